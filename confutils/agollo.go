@@ -14,6 +14,7 @@ import (
 	"github.com/apolloconfig/agollo/v4/storage"
 	"github.com/spf13/cast"
 	"github.com/spf13/viper"
+	"github.com/0DeOrg/gutils"
 	"github.com/0DeOrg/gutils/convert"
 	"github.com/0DeOrg/gutils/eventListener"
 	"log"
@@ -80,6 +81,8 @@ func (l *changeListener) OnChange(event *storage.ChangeEvent) {
 	err := l.vp.Unmarshal(l.confSt)
 	if nil != err {
 		log.Print("changeListener OnChange err", err.Error())
+	} else {
+		gutils.TriggerConfigChange()
 	}
 
 	eventListener.TriggerEvent(EventApolloChange)
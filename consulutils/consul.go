@@ -36,8 +36,29 @@ func RegisterConsul(consulHost string, consulPort int, token string, instance *S
 	return nil
 }
 
+// DeregisterSelf
+/* @Description: 注销RegisterConsul注册的本地实例，需要处理注销失败时使用 DeregisterLocal
+ */
 func DeregisterSelf() {
+	_ = DeregisterLocal()
+}
+
+// DeregisterLocal
+/* @Description: 注销RegisterConsul注册的本地实例，用于退出时主动下线
+ * @return error
+ */
+func DeregisterLocal() error {
+	if nil == gConsulRegistry || nil == gConsulRegistry.LocalServiceInstance {
+		return nil
+	}
 
+	err := gConsulRegistry.unregister(*gConsulRegistry.LocalServiceInstance)
+	if nil != err {
+		return fmt.Errorf("deregister instance fatal, err: %s", err.Error())
+	}
+
+	gConsulRegistry.LocalServiceInstance = nil
+	return nil
 }
 
 // NewLocalServiceInstance
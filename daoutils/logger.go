@@ -0,0 +1,124 @@
+package daoutils
+
+/**
+ * @Author: lee
+ * @Description: gorm logger.Interface 适配，sql日志写入 logutils
+ * @File: logger
+ * @Date: 2026-10-19 10:30 下午
+ */
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/0DeOrg/gutils/logutils"
+	"go.uber.org/zap"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+	"strings"
+	"time"
+)
+
+const defaultSlowThreshold = 200 * time.Millisecond
+
+type GormLogger struct {
+	LogLevel                  logger.LogLevel
+	SlowThreshold             time.Duration //慢查询阈值，0 不记录慢查询
+	IgnoreRecordNotFoundError bool
+}
+
+var _ logger.Interface = (*GormLogger)(nil)
+
+// NewGormLogger
+/* @Description: 创建写入 logutils 的 gorm 日志，在日志配置 loggers 中配置 gorm 即可输出到单独的文件
+ * 每次输出时获取日志，日志初始化前输出到标准错误
+ * @param level logger.LogLevel
+ * @param slowThreshold time.Duration 慢查询阈值
+ * @return *GormLogger
+ */
+func NewGormLogger(level logger.LogLevel, slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{
+		LogLevel:                  level,
+		SlowThreshold:             slowThreshold,
+		IgnoreRecordNotFoundError: true,
+	}
+}
+
+func (l *GormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	ret := *l
+	ret.LogLevel = level
+	return &ret
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= logger.Info {
+		l.module().Info(fmt.Sprintf(msg, data...), l.fields(ctx)...)
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= logger.Warn {
+		l.module().Warn(fmt.Sprintf(msg, data...), l.fields(ctx)...)
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= logger.Error {
+		l.module().Error(fmt.Sprintf(msg, data...), l.fields(ctx)...)
+	}
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.LogLevel <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case nil != err && l.LogLevel >= logger.Error && (!errors.Is(err, logger.ErrRecordNotFound) || !l.IgnoreRecordNotFoundError):
+		sql, rows := fc()
+		l.module().Error("gorm trace", append(traceFields(l.fields(ctx), sql, rows, elapsed), zap.Error(err))...)
+	case l.SlowThreshold > 0 && elapsed > l.SlowThreshold && l.LogLevel >= logger.Warn:
+		sql, rows := fc()
+		l.module().Warn("gorm slow sql", append(traceFields(l.fields(ctx), sql, rows, elapsed), zap.Duration("threshold", l.SlowThreshold))...)
+	case l.LogLevel == logger.Info:
+		sql, rows := fc()
+		l.module().Info("gorm trace", traceFields(l.fields(ctx), sql, rows, elapsed)...)
+	}
+}
+
+// module GormLogger 的方法多一层调用
+func (l *GormLogger) module() *logutils.ZapLogModule {
+	return logutils.NamedModule(logutils.ModuleGorm).WithCallerSkip(1)
+}
+
+// fields 调用sql的业务代码位置及上下文中的 request_id/trace_id
+func (l *GormLogger) fields(ctx context.Context) []zap.Field {
+	ret := []zap.Field{zap.String("source", utils.FileWithLineNum())}
+	for k, v := range logutils.ContextIDs(ctx) {
+		ret = append(ret, zap.String(k, v))
+	}
+	return ret
+}
+
+func traceFields(fields []zap.Field, sql string, rows int64, elapsed time.Duration) []zap.Field {
+	return append(fields,
+		zap.String("sql", sql),
+		zap.Int64("rows", rows),
+		zap.Float64("elapsed_ms", float64(elapsed.Nanoseconds())/1e6),
+	)
+}
+
+// parseGormLevel 配置中的 log-mode 转换为 gorm 日志级别，默认 warn
+func parseGormLevel(mode string) logger.LogLevel {
+	switch strings.ToLower(mode) {
+	case "silent":
+		return logger.Silent
+	case "info":
+		return logger.Info
+	case "error":
+		return logger.Error
+	default:
+		return logger.Warn
+	}
+}
@@ -9,8 +9,7 @@ package daoutils
 import (
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
-	"strings"
+	"time"
 )
 
 type MySQLCfg struct {
@@ -25,6 +24,7 @@ type MySQLCfg struct {
 	Password               string `mapstructure:"password"       json:"password"      yaml:"password"`
 	DefaultStringSize      uint   `mapstructure:"default-str-size"       json:"defaultStrSize"      yaml:"default-str-size"`
 	SkipDefaultTransaction bool   `mapstructure:"skip-default-transaction"       json:"skip-default-transaction"      yaml:"skip-default-transaction"`
+	SlowThreshold          int    `mapstructure:"slow-threshold"       json:"slow-threshold"      yaml:"slow-threshold"` //慢查询阈值 单位ms，0 默认200ms，小于0不记录
 }
 
 type MySQLClient struct {
@@ -80,27 +80,13 @@ func (c MySQLClient) DSN() string {
 func (c MySQLClient) generateGormConfig() *gorm.Config {
 	gormConfig := gorm.Config{}
 
-	if nil == gormConfig.Logger {
-		gormConfig.Logger = logger.Default
-	}
-
-	switch strings.ToLower(c.cfg.LogMode) {
-	case "silent":
-		gormConfig.Logger.LogMode(logger.Silent)
-		break
-	case "info":
-		gormConfig.Logger.LogMode(logger.Info)
-		break
-	case "warn":
-		gormConfig.Logger.LogMode(logger.Warn)
-		break
-	case "error":
-		gormConfig.Logger.LogMode(logger.Error)
-		break
-	default:
-
-		break
+	slowThreshold := defaultSlowThreshold
+	if c.cfg.SlowThreshold > 0 {
+		slowThreshold = time.Duration(c.cfg.SlowThreshold) * time.Millisecond
+	} else if c.cfg.SlowThreshold < 0 {
+		slowThreshold = 0
 	}
+	gormConfig.Logger = NewGormLogger(parseGormLevel(c.cfg.LogMode), slowThreshold)
 
 	return &gormConfig
 }
@@ -0,0 +1,434 @@
+package dingutils
+
+/**
+ * @Author: lee
+ * @Description: 日志告警 zap core，error 及以上或带告警字段的日志转为钉钉告警，按 message+caller 去重、限流并汇总
+ * @File: alert_core
+ * @Date: 2026-10-20 5:10 下午
+ */
+
+import (
+	"fmt"
+	"github.com/0DeOrg/gutils/logutils"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultAlertField 携带该字段的日志不论级别都告警，值为告警code
+const DefaultAlertField = "alert"
+
+const (
+	defaultAlertWindow    = time.Minute
+	defaultAlertRateLimit = 10
+	alertQueueSize        = 1024
+	alertSummaryTop       = 10
+	alertCoreName         = "dingutils.alert"
+)
+
+// alertFlushTimeout panic/fatal 告警同步发送及 Sync、Close 的最长等待时间
+var alertFlushTimeout = 3 * time.Second
+
+type AlertCoreConfig struct {
+	Level     string        `json:"level"     yaml:"level"             mapstructure:"level"`      //告警的最低级别，默认error
+	Field     string        `json:"field"     yaml:"field"             mapstructure:"field"`      //携带该字段的 info/warn 日志也告警，默认 alert
+	Window    time.Duration `json:"window"     yaml:"window"           mapstructure:"window"`     //去重及限流周期，默认1m
+	RateLimit int           `json:"rate-limit"     yaml:"rate-limit"   mapstructure:"rate-limit"` //每个周期最多单独发送的告警数，超出的在周期结束时合并发送，默认10
+}
+
+// AlertField
+/* @Description: 标记日志需要告警，如 logutils.Warn("price is old", dingutils.AlertField(WarnIndexPriceIsOld))
+ * @param code int64 告警code，标题按 AssignMsgMap/DING_WARNING_MSG 查找
+ * @return zap.Field
+ */
+func AlertField(code int64) zap.Field {
+	return zap.Int64(DefaultAlertField, code)
+}
+
+type alertEntry struct {
+	level   zapcore.Level
+	code    int64
+	message string
+	caller  string
+	logger  string
+	time    time.Time
+	fields  map[string]interface{}
+}
+
+type alertGroup struct {
+	first alertEntry
+	last  time.Time
+	count int //周期内未发送的次数
+}
+
+type AlertCore struct {
+	minLevel zapcore.Level
+	field    string
+	fields   []zap.Field
+	alerter  *alerter
+}
+
+var _ zapcore.Core = (*AlertCore)(nil)
+
+type alerter struct {
+	window    time.Duration
+	rateLimit int
+	queue     chan alertEntry
+	flushReq  chan chan error
+	post      func(kind string, title string, code int64, params map[string]interface{}) error
+	dropped   uint64
+	stop      chan struct{}
+	done      chan struct{}
+	closed    sync.Once
+}
+
+// NewAlertCore
+/* @Description: 创建告警 core，相同 message+caller 的日志周期内只告警一次，之后汇总重复次数
+ * @param cfg AlertCoreConfig
+ * @return *AlertCore
+ * @return error
+ */
+func NewAlertCore(cfg AlertCoreConfig) (*AlertCore, error) {
+	return newAlertCore(cfg, postDingMarkdown)
+}
+
+// EnableAlertCore
+/* @Description: 全局日志接入钉钉告警，需先调用 InitDingBot，之后 logutils.Error 无需再手动 PostDingError
+ * 重复调用时替换之前的告警 core 并关闭，不会重复告警
+ * @param cfg AlertCoreConfig
+ * @return error
+ */
+func EnableAlertCore(cfg AlertCoreConfig) error {
+	if nil == dingBot {
+		return fmt.Errorf("EnableAlertCore dingBot is nil")
+	}
+	core, err := NewAlertCore(cfg)
+	if nil != err {
+		return err
+	}
+	if err = logutils.ReplaceCore(alertCoreName, core, core); nil != err {
+		_ = core.Close()
+		return err
+	}
+	return nil
+}
+
+func newAlertCore(cfg AlertCoreConfig, post func(kind string, title string, code int64, params map[string]interface{}) error) (*AlertCore, error) {
+	lvl := zap.ErrorLevel
+	if "" != cfg.Level {
+		var err error
+		if lvl, err = logutils.ParseLevel(cfg.Level); nil != err {
+			return nil, err
+		}
+	}
+	if "" == cfg.Field {
+		cfg.Field = DefaultAlertField
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaultAlertWindow
+	}
+	if cfg.RateLimit <= 0 {
+		cfg.RateLimit = defaultAlertRateLimit
+	}
+
+	a := &alerter{
+		window:    cfg.Window,
+		rateLimit: cfg.RateLimit,
+		queue:     make(chan alertEntry, alertQueueSize),
+		flushReq:  make(chan chan error),
+		post:      post,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go a.goAlert()
+
+	return &AlertCore{
+		minLevel: lvl,
+		field:    cfg.Field,
+		alerter:  a,
+	}, nil
+}
+
+// Enabled 只对告警级别开启；低于该级别、带告警字段的 info/warn 日志在 Check 中单独放行，
+// 需要与其他输出组合(如 logutils.AddCore)且日志本身开启了该级别
+func (c *AlertCore) Enabled(level zapcore.Level) bool {
+	return level >= c.minLevel
+}
+
+func (c *AlertCore) With(fields []zap.Field) zapcore.Core {
+	ret := *c
+	ret.fields = make([]zap.Field, 0, len(c.fields)+len(fields))
+	ret.fields = append(ret.fields, c.fields...)
+	ret.fields = append(ret.fields, fields...)
+	return &ret
+}
+
+func (c *AlertCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	//字段在 Write 时才能拿到，info/warn 先放行，Write 中只查找告警字段
+	if ent.Level >= zap.InfoLevel {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *AlertCore) Write(ent zapcore.Entry, fields []zap.Field) error {
+	code, marked := c.alertCode(fields)
+	if ent.Level < c.minLevel && !marked {
+		return nil
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	delete(enc.Fields, c.field)
+
+	entry := alertEntry{
+		level:   ent.Level,
+		code:    code,
+		message: ent.Message,
+		logger:  ent.LoggerName,
+		time:    ent.Time,
+		fields:  enc.Fields,
+	}
+	if ent.Caller.Defined {
+		entry.caller = ent.Caller.TrimmedPath()
+	}
+
+	select {
+	case c.alerter.queue <- entry:
+	default:
+		atomic.AddUint64(&c.alerter.dropped, 1)
+	}
+
+	//panic/fatal 后进程可能退出，限时同步发送
+	if ent.Level > zap.ErrorLevel {
+		return c.alerter.flushTimeout(alertFlushTimeout)
+	}
+	return nil
+}
+
+// alertCode 查找告警字段，后出现的覆盖之前的
+func (c *AlertCore) alertCode(fields []zap.Field) (code int64, marked bool) {
+	for _, list := range [][]zap.Field{c.fields, fields} {
+		for _, f := range list {
+			if f.Key != c.field {
+				continue
+			}
+			marked = true
+			code = 0
+			switch f.Type {
+			case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type:
+				code = f.Integer
+			}
+		}
+	}
+	return code, marked
+}
+
+// Sync 立即发送周期内被合并的告警，最多等待 alertFlushTimeout，钉钉不可用时不会卡住 logutils.Sync
+func (c *AlertCore) Sync() error {
+	return c.alerter.flushTimeout(alertFlushTimeout)
+}
+
+// Close
+/* @Description: 限时发送剩余告警后停止后台协程，With 创建的副本共用，关闭后的日志不再告警
+ * @return error
+ */
+func (c *AlertCore) Close() error {
+	return c.alerter.close()
+}
+
+// Dropped
+/* @Description: 队列满丢弃的告警数量
+ * @return uint64
+ */
+func (c *AlertCore) Dropped() uint64 {
+	return atomic.LoadUint64(&c.alerter.dropped)
+}
+
+func (a *alerter) flush() error {
+	ch := make(chan error, 1)
+	select {
+	case a.flushReq <- ch:
+	case <-a.done:
+		return nil
+	}
+	return <-ch
+}
+
+// close 只执行一次
+func (a *alerter) close() error {
+	var ret error
+	a.closed.Do(func() {
+		ret = a.flushTimeout(alertFlushTimeout)
+		close(a.stop)
+		select {
+		case <-a.done:
+		case <-time.After(alertFlushTimeout):
+		}
+	})
+	return ret
+}
+
+// flushTimeout 同 flush，超时后不再等待，发送在后台继续
+func (a *alerter) flushTimeout(timeout time.Duration) error {
+	ret := make(chan error, 1)
+	go func() {
+		ret <- a.flush()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-ret:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("alert core flush timeout after %s", timeout)
+	}
+}
+
+func (a *alerter) goAlert() {
+	defer close(a.done)
+	tick := time.NewTicker(a.window)
+	defer tick.Stop()
+
+	groups := map[string]*alertGroup{}
+	sent := 0
+	add := func(entry alertEntry) {
+		//panic/fatal 不去重、不限流
+		if entry.level > zap.ErrorLevel {
+			a.send(entry)
+			return
+		}
+		key := entry.message + "|" + entry.caller
+		if g, ok := groups[key]; ok {
+			g.count++
+			g.last = entry.time
+			return
+		}
+		g := &alertGroup{first: entry, last: entry.time}
+		groups[key] = g
+		//超过限流的在周期结束时合并发送
+		if sent >= a.rateLimit {
+			g.count = 1
+			return
+		}
+		sent++
+		a.send(entry)
+	}
+
+	for {
+		select {
+		case <-a.stop:
+			atomic.AddUint64(&a.dropped, uint64(len(a.queue)))
+			return
+		case entry := <-a.queue:
+			add(entry)
+		case <-tick.C:
+			_ = a.summary(groups)
+			groups = map[string]*alertGroup{}
+			sent = 0
+		case ch := <-a.flushReq:
+			for n := len(a.queue); n > 0; n-- {
+				add(<-a.queue)
+			}
+			ch <- a.summary(groups)
+		}
+	}
+}
+
+func (a *alerter) send(entry alertEntry) {
+	title := entry.message
+	if 0 != entry.code {
+		title = alertTitle(entry.code, entry.message)
+		//注册了模板的按模板渲染
+		if _, ok := lookupCode(entry.code); ok {
+			title = ""
+		}
+	}
+
+	params := make(map[string]interface{}, len(entry.fields)+4)
+	for k, v := range entry.fields {
+		params[k] = v
+	}
+	params["message"] = entry.message
+	if "" != entry.caller {
+		params["caller"] = entry.caller
+	}
+	if "" != entry.logger {
+		params["logger"] = entry.logger
+	}
+	params["time"] = entry.time.Format("2006-01-02 15:04:05.000")
+
+	//发送失败不能再写 error 日志，否则会循环告警
+	if err := a.post(alertKind(entry.level), title, entry.code, params); nil != err {
+		fmt.Fprintf(os.Stderr, "alert core post err: %s\n", err.Error())
+	}
+}
+
+// summary 周期内重复及被限流的告警合并为一条，按次数从多到少
+func (a *alerter) summary(groups map[string]*alertGroup) error {
+	pending := make([]*alertGroup, 0)
+	for _, g := range groups {
+		if g.count > 0 {
+			pending = append(pending, g)
+		}
+	}
+	if 0 == len(pending) {
+		return nil
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].count > pending[j].count
+	})
+
+	level := zap.InfoLevel
+	total := 0
+	params := make(map[string]interface{}, alertSummaryTop+2)
+	for i, g := range pending {
+		total += g.count
+		if g.first.level > level {
+			level = g.first.level
+		}
+		if i < alertSummaryTop {
+			var line strings.Builder
+			line.WriteString(g.first.message)
+			if "" != g.first.caller {
+				line.WriteString(" (" + g.first.caller + ")")
+			}
+			line.WriteString(fmt.Sprintf(" x%d，最近 %s", g.count, g.last.Format("15:04:05")))
+			params[fmt.Sprintf("%02d", i+1)] = line.String()
+		}
+		g.count = 0
+	}
+	params["total"] = total
+	params["window"] = a.window.String()
+	if len(pending) > alertSummaryTop {
+		params["more"] = len(pending) - alertSummaryTop
+	}
+
+	err := a.post(alertKind(level), "告警汇总", 0, params)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "alert core post summary err: %s\n", err.Error())
+	}
+	return err
+}
+
+func alertKind(level zapcore.Level) string {
+	switch {
+	case level >= zap.ErrorLevel:
+		return KindError
+	case zap.WarnLevel == level:
+		return KindWarn
+	default:
+		return KindInfo
+	}
+}
@@ -0,0 +1,149 @@
+package dingutils
+
+import (
+	"github.com/0DeOrg/gutils/logutils"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: alert_core_test
+ * @Date: 2026-10-20 5:40 下午
+ */
+
+type postRecord struct {
+	kind   string
+	title  string
+	code   int64
+	params map[string]interface{}
+}
+
+func Test_AlertCore(t *testing.T) {
+	var (
+		records []postRecord
+		mtx     sync.Mutex
+	)
+	post := func(kind string, title string, code int64, params map[string]interface{}) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		records = append(records, postRecord{kind: kind, title: title, code: code, params: params})
+		return nil
+	}
+	core, err := newAlertCore(AlertCoreConfig{Window: time.Hour, RateLimit: 2}, post)
+	if nil != err {
+		t.Fatal(err)
+	}
+	//与全局日志组合时 info/warn 的告警字段才会生效
+	logger := zap.New(zapcore.NewTee(core, zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(io.Discard), zap.InfoLevel)))
+	if core.Enabled(zap.WarnLevel) || !core.Enabled(zap.ErrorLevel) {
+		t.Fatal("expect enabled from error level")
+	}
+
+	for i := 0; i < 3; i++ {
+		logger.Error("db down", zap.Int("i", i))
+	}
+	logger.Info("ignored")
+	logger.Warn("price is old", AlertField(WarnIndexPriceIsOld))
+	logger.Error("limited")
+	if err = logger.Sync(); nil != err {
+		t.Fatal(err)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if 3 != len(records) {
+		t.Fatalf("expect 2 alerts and 1 summary, got %+v", records)
+	}
+	if KindError != records[0].kind || "db down" != records[0].title || int64(0) != records[0].params["i"] {
+		t.Fatalf("unexpected alert: %+v", records[0])
+	}
+	if KindWarn != records[1].kind || DING_WARNING_MSG[WarnIndexPriceIsOld] != records[1].title {
+		t.Fatalf("unexpected field alert: %+v", records[1])
+	}
+	//重复2次的 db down 及被限流的 limited
+	if "告警汇总" != records[2].title || 3 != records[2].params["total"] {
+		t.Fatalf("unexpected summary: %+v", records[2])
+	}
+	mtx.Unlock()
+
+	//panic/fatal 在 Write 返回前发送
+	if err = core.Write(zapcore.Entry{Level: zap.FatalLevel, Time: time.Now(), Message: "fatal"}, nil); nil != err {
+		t.Fatal(err)
+	}
+	mtx.Lock()
+	if 4 != len(records) || "fatal" != records[3].title {
+		t.Fatalf("expect fatal alert sent synchronously, got %+v", records)
+	}
+}
+
+func Test_AlertCoreClose(t *testing.T) {
+	timeout := alertFlushTimeout
+	alertFlushTimeout = 50 * time.Millisecond
+	defer func() {
+		alertFlushTimeout = timeout
+	}()
+
+	block := make(chan struct{})
+	core, err := newAlertCore(AlertCoreConfig{Window: time.Hour}, func(string, string, int64, map[string]interface{}) error {
+		<-block
+		return nil
+	})
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	//钉钉不可用时 Sync 限时返回
+	_ = core.Write(zapcore.Entry{Level: zap.ErrorLevel, Time: time.Now(), Message: "db down"}, nil)
+	start := time.Now()
+	if err = core.Sync(); nil == err || time.Since(start) > time.Second {
+		t.Fatalf("expect sync timeout, err %v, elapsed %s", err, time.Since(start))
+	}
+	start = time.Now()
+	_ = core.Close()
+	if time.Since(start) > time.Second {
+		t.Fatal("close blocked")
+	}
+	//关闭后不再阻塞
+	close(block)
+	if err = core.Sync(); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func Test_EnableAlertCore(t *testing.T) {
+	cfg := logutils.DefaultZapConfig
+	cfg.Directory = t.TempDir()
+	cfg.LinkName = ""
+	cfg.LogInConsole = false
+	logutils.InitLogger(cfg)
+
+	ding := newFakeWebhook(`{"errcode":0,"errmsg":"ok"}`)
+	defer ding.Close()
+	bot, err := NewDingTalk(ding.URL, "")
+	if nil != err {
+		t.Fatal(err)
+	}
+	old := dingBot
+	dingBot = bot
+	defer func() {
+		dingBot = old
+	}()
+
+	//重复调用替换原来的告警，不会重复发送
+	for i := 0; i < 2; i++ {
+		if err = EnableAlertCore(AlertCoreConfig{Window: time.Hour}); nil != err {
+			t.Fatal(err)
+		}
+	}
+	logutils.Error("db down")
+	_ = logutils.Sync()
+	if 1 != ding.count() {
+		t.Fatalf("expect 1 alert, got %d", ding.count())
+	}
+}
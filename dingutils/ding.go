@@ -7,13 +7,15 @@ package dingutils
  * @Date: 2022/1/11 11:52 上午
  */
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"github.com/spf13/cast"
 	"github.com/0DeOrg/gutils/logutils"
 	"github.com/0DeOrg/gutils/network"
 	"go.uber.org/zap"
-	"strings"
+	"strconv"
 	"time"
 )
 
@@ -21,6 +23,7 @@ var dingBot *DingTalk
 
 type DingTalk struct {
 	Url    string
+	secret string
 	client *network.RestAgent
 	mapMsg map[int64]string
 }
@@ -28,21 +31,125 @@ type DingTalk struct {
 // 初始化告警结构
 
 func InitDingBot(url string) error {
+	return InitDingBotWithSecret(url, "")
+}
+
+// InitDingBotWithSecret
+/* @Description: 初始化加签模式的钉钉机器人
+ * @param url string webhook 地址
+ * @param secret string 机器人安全设置中的加签密钥 SEC...
+ * @return error
+ */
+func InitDingBotWithSecret(url string, secret string) error {
 	if url == "" {
 		logutils.Warn("InitDingBot url is empty")
 		return fmt.Errorf("InitDingBot url is empty")
 	}
-	client, err := network.NewRestClient(url, 0, false)
+	bot, err := NewDingTalk(url, secret)
 	if nil != err {
 		logutils.Fatal("InitDingBot url is unreachable", zap.String("url", url))
 	}
-	dingBot = &DingTalk{
+	dingBot = bot
+	return nil
+}
+
+// NewDingTalk
+/* @Description: 创建钉钉机器人，可作为 Notifier 加入 AlertManager
+ * @param url string webhook 地址
+ * @param secret string 加签密钥，关键词模式为空
+ * @return *DingTalk
+ * @return error
+ */
+func NewDingTalk(url string, secret string) (*DingTalk, error) {
+	if "" == url {
+		return nil, fmt.Errorf("NewDingTalk url is empty")
+	}
+	client, err := network.NewRestClient(url, 0, false)
+	if nil != err {
+		return nil, err
+	}
+	return &DingTalk{
 		Url:    url,
+		secret: secret,
 		client: client,
+	}, nil
+}
+
+// Notify 以 markdown 发送告警，可通过 errors.As 取得 *DingError
+func (d *DingTalk) Notify(alert *Alert) error {
+	err := d.Send(NewMarkdownMsg("告警信息", alert.markdown()).WithAt(alert.Mention))
+	if err != nil {
+		return fmt.Errorf("DingTalk|Notify err %w", err)
+	}
+	return nil
+}
+
+// Send
+/* @Description: 发送任意类型的消息，errcode 不为0时返回 *DingError，限流为 DingErrRateLimit
+ * @param msg *DingMsg
+ * @return error
+ */
+func (d *DingTalk) Send(msg *DingMsg) error {
+	reqBody, err := json.Marshal(msg)
+	if nil != err {
+		return err
+	}
+
+	var params map[string]string
+	if "" != d.secret {
+		timestamp := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+		params = map[string]string{
+			"timestamp": timestamp,
+			"sign":      dingSign(timestamp, d.secret),
+		}
+	}
+
+	body, err := d.client.SimplePost("", string(reqBody), params)
+	if err != nil {
+		return err
+	}
+
+	var rsp DingRsp
+	if err = json.Unmarshal([]byte(body), &rsp); nil != err {
+		return fmt.Errorf("DingTalk|Send Unmarshal err: %s, body: %s", err.Error(), body)
+	}
+	if 0 != rsp.ErrCode {
+		return &DingError{Code: rsp.ErrCode, Msg: rsp.ErrMsg}
 	}
 	return nil
 }
 
+// PostDingMsg
+/* @Description: 通过全局机器人发送任意类型的消息
+ * @param msg *DingMsg
+ * @return error
+ */
+func PostDingMsg(msg *DingMsg) error {
+	if nil == dingBot {
+		return fmt.Errorf("PostDingMsg dingBot is nil")
+	}
+	return dingBot.Send(msg)
+}
+
+// PostDing
+/* @Description: 通过全局机器人发送告警，可带@的人，如 PostDing(NewAlert(KindError, code, "func", params).WithMention(Mention{All: true}))
+ * @param alert *Alert
+ * @return error
+ */
+func PostDing(alert *Alert) error {
+	if nil == dingBot {
+		return fmt.Errorf("PostDing dingBot is nil")
+	}
+	return dingBot.Notify(alert)
+}
+
+// dingSign 以 secret 为密钥对 timestamp+"\n"+secret 做 HmacSHA256 后 base64
+func dingSign(timestamp string, secret string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp + "\n" + secret))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
 func AssignMsgMap(mapMsg map[int64]string) {
 	if nil != dingBot {
 		dingBot.mapMsg = mapMsg
@@ -50,7 +157,7 @@ func AssignMsgMap(mapMsg map[int64]string) {
 }
 
 func PostDingInfo(code int64, funcName string, params map[string]interface{}) error {
-	if nil == dingBot {
+	if nil == dingBot && nil == getAlertQueue() {
 		return fmt.Errorf("PostDingInfo dingBot is nil")
 	}
 	mapParam := params
@@ -64,7 +171,7 @@ func PostDingInfo(code int64, funcName string, params map[string]interface{}) er
 }
 
 func PostDingWarn(code int64, funcName string, params map[string]interface{}) error {
-	if nil == dingBot {
+	if nil == dingBot && nil == getAlertQueue() {
 		return fmt.Errorf("PostDingWarn dingBot is nil")
 	}
 	mapParam := params
@@ -78,7 +185,7 @@ func PostDingWarn(code int64, funcName string, params map[string]interface{}) er
 }
 
 func PostDingError(code int64, funcName string, params map[string]interface{}) error {
-	if nil == dingBot {
+	if nil == dingBot && nil == getAlertQueue() {
 		return fmt.Errorf("PostDingError dingBot is nil")
 	}
 	mapParam := params
@@ -91,67 +198,48 @@ func PostDingError(code int64, funcName string, params map[string]interface{}) e
 	return doPostDingMsg(KindError, code, mapParam)
 }
 
+// doPostDingMsg 开启 EnableAlertQueue 后异步入队
 func doPostDingMsg(kind string, code int64, params map[string]interface{}) error {
-	var title = "未定义告警"
-	var ok bool
+	if q := getAlertQueue(); nil != q {
+		q.Post(&Alert{
+			Kind:   kind,
+			Code:   code,
+			Params: params,
+			Time:   time.Now(),
+		})
+		return nil
+	}
+	return postDingMarkdown(kind, "", code, params)
+}
 
-	str, ok := DING_WARNING_MSG[code]
-	if ok {
+// alertTitle code 对应的告警标题，AssignMsgMap 中的优先
+func alertTitle(code int64, def string) string {
+	title := def
+	if str, ok := DING_WARNING_MSG[code]; ok {
 		title = str
 	}
-
-	if nil != dingBot.mapMsg {
-		str, ok = dingBot.mapMsg[code]
-		if ok {
+	if nil != dingBot && nil != dingBot.mapMsg {
+		if str, ok := dingBot.mapMsg[code]; ok {
 			title = str
 		}
 	}
+	return title
+}
 
-	var content strings.Builder
-	titleStr := fmt.Sprintf("#### 【%s】", kind)
-	content.WriteString(titleStr + title + "\n")
-	content.WriteString("code: " + cast.ToString(code) + "\r\r\n")
-	for key, value := range params {
-		msg, _ := json.Marshal(value)
-		content.WriteString(key + ": " + string(msg) + "\r\r\n")
-	}
-
-	content.WriteString("\n\n ###### ")
-	content.WriteString(time.Now().Format("2006-01-02 15:04:05") + "（UTC+8）")
-
-	_, err := postDing(content.String())
+func postDingMarkdown(kind string, title string, code int64, params map[string]interface{}) error {
+	if nil == dingBot {
+		return fmt.Errorf("postDingMarkdown dingBot is nil")
+	}
+	err := dingBot.Notify(&Alert{
+		Kind:   kind,
+		Code:   code,
+		Title:  title,
+		Params: params,
+		Time:   time.Now(),
+	})
 	if err != nil {
 		return fmt.Errorf("doPostDingMsg|Ding err %s", err.Error())
 	}
 
 	return nil
 }
-
-// 发送告警信息
-func postDing(text string) (string, error) {
-	// 构造告警请求
-	markDownInfo := &WarnDataReq{
-		Title: "告警信息",
-		Text:  text,
-	}
-	atInfo := &WarnAtReq{
-		IsAtAll: false,
-	}
-	warnReq := &WarnReq{
-		MsgType:  "markdown",
-		MarkDown: *markDownInfo,
-		At:       *atInfo,
-	}
-
-	reqBody, _ := json.Marshal(warnReq)
-
-	// 发送告警信息
-	body, err := dingBot.client.SimplePost("", string(reqBody), nil)
-
-	//body, err := network.HttpPostJson(dingBot.Url, string(reqBody))
-	if err != nil {
-		return "", err
-	}
-
-	return body, nil
-}
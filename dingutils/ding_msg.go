@@ -0,0 +1,129 @@
+package dingutils
+
+/**
+ * @Author: lee
+ * @Description: 钉钉 text/markdown/link/actionCard/feedCard 消息及错误码
+ * @File: ding_msg
+ * @Date: 2026-10-20 9:20 下午
+ */
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	DingMsgText       = "text"
+	DingMsgMarkdown   = "markdown"
+	DingMsgLink       = "link"
+	DingMsgActionCard = "actionCard"
+	DingMsgFeedCard   = "feedCard"
+)
+
+const (
+	DingErrRateLimit = 130101 //发送速度太快，每个机器人每分钟最多20条
+	DingErrKeyword   = 310000 //关键词/签名/ip 校验不通过
+)
+
+// DingError 钉钉返回的 errcode 不为0
+type DingError struct {
+	Code int
+	Msg  string
+}
+
+func (e *DingError) Error() string {
+	return fmt.Sprintf("dingtalk errcode: %d, errmsg: %s", e.Code, e.Msg)
+}
+
+// IsRateLimit 是否被钉钉限流
+func (e *DingError) IsRateLimit() bool {
+	return DingErrRateLimit == e.Code
+}
+
+func NewTextMsg(content string) *DingMsg {
+	return &DingMsg{
+		MsgType: DingMsgText,
+		Text:    &DingText{Content: content},
+	}
+}
+
+func NewMarkdownMsg(title string, text string) *DingMsg {
+	return &DingMsg{
+		MsgType:  DingMsgMarkdown,
+		MarkDown: &WarnDataReq{Title: title, Text: text},
+	}
+}
+
+func NewLinkMsg(title string, text string, messageUrl string, picUrl string) *DingMsg {
+	return &DingMsg{
+		MsgType: DingMsgLink,
+		Link: &DingLink{
+			Title:      title,
+			Text:       text,
+			MessageUrl: messageUrl,
+			PicUrl:     picUrl,
+		},
+	}
+}
+
+// NewActionCardMsg
+/* @Description: 卡片消息，只有一个按钮时整体跳转
+ * @param title string
+ * @param text string markdown
+ * @param btns ...DingButton
+ * @return *DingMsg
+ */
+func NewActionCardMsg(title string, text string, btns ...DingButton) *DingMsg {
+	card := &DingActionCard{
+		Title: title,
+		Text:  text,
+	}
+	if 1 == len(btns) {
+		card.SingleTitle = btns[0].Title
+		card.SingleURL = btns[0].ActionURL
+	} else {
+		card.Btns = btns
+	}
+	return &DingMsg{
+		MsgType:    DingMsgActionCard,
+		ActionCard: card,
+	}
+}
+
+func NewFeedCardMsg(links ...DingFeedLink) *DingMsg {
+	return &DingMsg{
+		MsgType:  DingMsgFeedCard,
+		FeedCard: &DingFeedCard{Links: links},
+	}
+}
+
+// WithAt
+/* @Description: 设置需要@的人，text/markdown 消息会在内容末尾追加@手机号，钉钉才会高亮
+ * @param mention Mention
+ * @return *DingMsg
+ */
+func (m *DingMsg) WithAt(mention Mention) *DingMsg {
+	if mention.empty() {
+		return m
+	}
+	m.At = &WarnAtReq{
+		AtMobiles: mention.Mobiles,
+		AtUserIds: mention.UserIds,
+		IsAtAll:   mention.All,
+	}
+
+	var at strings.Builder
+	for _, mobile := range mention.Mobiles {
+		at.WriteString(" @" + mobile)
+	}
+	for _, userId := range mention.UserIds {
+		at.WriteString(" @" + userId)
+	}
+	switch {
+	case nil != m.Text:
+		m.Text.Content += at.String()
+	case nil != m.MarkDown:
+		m.MarkDown.Text += "\n\n" + at.String()
+	}
+	return m
+}
@@ -0,0 +1,313 @@
+package dingutils
+
+/**
+ * @Author: lee
+ * @Description: 告警管理，按级别和 code 将告警路由到一个或多个通知渠道
+ * @File: manager
+ * @Date: 2026-10-20 8:10 下午
+ */
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+type ChannelConfig struct {
+	Name   string      `json:"name"     yaml:"name"       mapstructure:"name"`
+	Type   string      `json:"type"     yaml:"type"       mapstructure:"type"` //dingtalk/feishu/wecom/slack/email
+	Url    string      `json:"url"     yaml:"url"         mapstructure:"url"`
+	Secret string      `json:"secret"     yaml:"secret"   mapstructure:"secret"` //钉钉/飞书加签密钥
+	Email  EmailConfig `json:"email"     yaml:"email"     mapstructure:"email"`
+}
+
+// Route 级别和 code 都匹配时发送到 Channels，为空表示匹配所有
+type Route struct {
+	Kinds    []string `json:"kinds"     yaml:"kinds"         mapstructure:"kinds"` //KindXxx 或 info/warn/error
+	Codes    []int64  `json:"codes"     yaml:"codes"         mapstructure:"codes"`
+	Channels []string `json:"channels"     yaml:"channels"   mapstructure:"channels"`
+	Continue bool     `json:"continue"     yaml:"continue"   mapstructure:"continue"` //匹配后继续匹配后面的路由
+}
+
+type AlertConfig struct {
+	Channels []ChannelConfig `json:"channels"     yaml:"channels"   mapstructure:"channels"`
+	Routes   []Route         `json:"routes"     yaml:"routes"       mapstructure:"routes"`
+	Default  []string        `json:"default"     yaml:"default"     mapstructure:"default"` //没有路由匹配时发送的渠道，为空则发送到所有渠道
+}
+
+type AlertManager struct {
+	notifiers map[string]Notifier
+	names     []string
+	routes    []Route
+	defaults  []string
+	mtx       sync.RWMutex
+}
+
+var alertManager *AlertManager
+
+// NewAlertManager
+/* @Description: 创建告警管理，通过 AddNotifier/AddRoute 配置渠道和路由
+ * @return *AlertManager
+ */
+func NewAlertManager() *AlertManager {
+	return &AlertManager{
+		notifiers: map[string]Notifier{},
+	}
+}
+
+// NewAlertManagerFromConfig
+/* @Description: 按配置创建告警管理
+ * @param cfg AlertConfig
+ * @return *AlertManager
+ * @return error
+ */
+func NewAlertManagerFromConfig(cfg AlertConfig) (*AlertManager, error) {
+	ret := NewAlertManager()
+	for _, ch := range cfg.Channels {
+		notifier, err := NewNotifier(ch)
+		if nil != err {
+			return nil, err
+		}
+		name := ch.Name
+		if "" == name {
+			name = ch.Type
+		}
+		ret.AddNotifier(name, notifier)
+	}
+	for _, route := range cfg.Routes {
+		if err := ret.AddRoute(route); nil != err {
+			return nil, err
+		}
+	}
+	if err := ret.SetDefault(cfg.Default...); nil != err {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// NewNotifier
+/* @Description: 按渠道类型创建 Notifier
+ * @param cfg ChannelConfig
+ * @return Notifier
+ * @return error
+ */
+func NewNotifier(cfg ChannelConfig) (Notifier, error) {
+	switch strings.ToLower(cfg.Type) {
+	case ChannelDingTalk:
+		return NewDingTalk(cfg.Url, cfg.Secret)
+	case ChannelFeishu:
+		return NewFeishu(cfg.Url, cfg.Secret)
+	case ChannelWeCom:
+		return NewWeCom(cfg.Url)
+	case ChannelSlack:
+		return NewSlack(cfg.Url)
+	case ChannelEmail:
+		return NewEmail(cfg.Email)
+	default:
+		return nil, fmt.Errorf("NewNotifier unknown type: %s", cfg.Type)
+	}
+}
+
+// InitAlertManager
+/* @Description: 初始化全局告警管理，之后通过 PostAlert 发送
+ * @param cfg AlertConfig
+ * @return error
+ */
+func InitAlertManager(cfg AlertConfig) error {
+	manager, err := NewAlertManagerFromConfig(cfg)
+	if nil != err {
+		return err
+	}
+	alertManager = manager
+	return nil
+}
+
+// PostAlert
+/* @Description: 通过全局告警管理发送，参数与 PostDingXxx 一致
+ * @param kind string KindInfo/KindWarn/KindError
+ * @param code int64
+ * @param funcName string
+ * @param params map[string]interface{}
+ * @return error
+ */
+func PostAlert(kind string, code int64, funcName string, params map[string]interface{}) error {
+	if nil == alertManager {
+		return fmt.Errorf("PostAlert alertManager is nil")
+	}
+	return alertManager.Notify(NewAlert(kind, code, funcName, params))
+}
+
+func (m *AlertManager) AddNotifier(name string, notifier Notifier) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if _, ok := m.notifiers[name]; !ok {
+		m.names = append(m.names, name)
+	}
+	m.notifiers[name] = notifier
+}
+
+// AddRoute 按添加顺序匹配
+func (m *AlertManager) AddRoute(route Route) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if err := m.checkChannels(route.Channels); nil != err {
+		return fmt.Errorf("AddRoute err: %s", err.Error())
+	}
+	kinds := make([]string, 0, len(route.Kinds))
+	for _, kind := range route.Kinds {
+		kinds = append(kinds, normalizeKind(kind))
+	}
+	route.Kinds = kinds
+	m.routes = append(m.routes, route)
+	return nil
+}
+
+// SetDefault 没有路由匹配时发送的渠道，为空则发送到所有渠道
+func (m *AlertManager) SetDefault(channels ...string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if err := m.checkChannels(channels); nil != err {
+		return fmt.Errorf("SetDefault err: %s", err.Error())
+	}
+	m.defaults = channels
+	return nil
+}
+
+// Notify
+/* @Description: 发送到匹配的所有渠道，部分渠道失败时返回汇总的错误
+ * @param alert *Alert
+ * @return error
+ */
+func (m *AlertManager) Notify(alert *Alert) error {
+	m.mtx.RLock()
+	channels := m.match(alert)
+	notifiers := make([]Notifier, 0, len(channels))
+	for _, name := range channels {
+		notifiers = append(notifiers, m.notifiers[name])
+	}
+	m.mtx.RUnlock()
+
+	if 0 == len(notifiers) {
+		return fmt.Errorf("AlertManager|Notify no channel for kind: %s, code: %d", alert.Kind, alert.Code)
+	}
+
+	errs := make([]string, len(notifiers))
+	var wg sync.WaitGroup
+	for i, notifier := range notifiers {
+		wg.Add(1)
+		go func(i int, notifier Notifier) {
+			defer wg.Done()
+			if err := notifier.Notify(alert); nil != err {
+				errs[i] = channels[i] + ": " + err.Error()
+			}
+		}(i, notifier)
+	}
+	wg.Wait()
+
+	failed := make([]string, 0)
+	for _, e := range errs {
+		if "" != e {
+			failed = append(failed, e)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("AlertManager|Notify err: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// AlertCore
+/* @Description: 创建通过本告警管理发送的日志告警 core，配合 logutils.AddCore 使用
+ * @param cfg AlertCoreConfig
+ * @return *AlertCore
+ * @return error
+ */
+func (m *AlertManager) AlertCore(cfg AlertCoreConfig) (*AlertCore, error) {
+	return newAlertCore(cfg, func(kind string, title string, code int64, params map[string]interface{}) error {
+		return m.Notify(&Alert{Kind: kind, Code: code, Title: title, Params: params})
+	})
+}
+
+// match 去重后的渠道名称
+func (m *AlertManager) match(alert *Alert) []string {
+	ret := make([]string, 0)
+	seen := map[string]bool{}
+	add := func(channels []string) {
+		for _, name := range channels {
+			if !seen[name] {
+				seen[name] = true
+				ret = append(ret, name)
+			}
+		}
+	}
+
+	for _, route := range m.routes {
+		if !route.match(alert) {
+			continue
+		}
+		add(route.Channels)
+		if !route.Continue {
+			return ret
+		}
+	}
+	if len(ret) > 0 {
+		return ret
+	}
+
+	if len(m.defaults) > 0 {
+		add(m.defaults)
+	} else {
+		add(m.names)
+	}
+	return ret
+}
+
+func (m *AlertManager) checkChannels(channels []string) error {
+	for _, name := range channels {
+		if _, ok := m.notifiers[name]; !ok {
+			return fmt.Errorf("unknown channel: %s", name)
+		}
+	}
+	return nil
+}
+
+func (r *Route) match(alert *Alert) bool {
+	if len(r.Kinds) > 0 {
+		found := false
+		for _, kind := range r.Kinds {
+			if kind == alert.Kind {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(r.Codes) > 0 {
+		for _, code := range r.Codes {
+			if code == alert.Code {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// normalizeKind 配置中可使用英文级别
+func normalizeKind(kind string) string {
+	switch strings.ToLower(kind) {
+	case "error":
+		return KindError
+	case "warn", "warning":
+		return KindWarn
+	case "info":
+		return KindInfo
+	default:
+		return kind
+	}
+}
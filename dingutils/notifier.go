@@ -0,0 +1,134 @@
+package dingutils
+
+/**
+ * @Author: lee
+ * @Description: 告警通知渠道接口及告警内容
+ * @File: notifier
+ * @Date: 2026-10-20 7:10 下午
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ChannelDingTalk = "dingtalk"
+	ChannelFeishu   = "feishu"
+	ChannelWeCom    = "wecom"
+	ChannelSlack    = "slack"
+	ChannelEmail    = "email"
+)
+
+// Alert 一条告警，各渠道按自己的格式发送
+type Alert struct {
+	Kind    string //KindInfo/KindWarn/KindError
+	Code    int64
+	Title   string //为空时按 code 查找，找不到为"未定义告警"
+	Params  map[string]interface{}
+	Time    time.Time
+	Mention Mention //需要@的人，目前只有钉钉支持
+}
+
+// Mention 按手机号、用户id或全部@
+type Mention struct {
+	Mobiles []string
+	UserIds []string
+	All     bool
+}
+
+type Notifier interface {
+	Notify(alert *Alert) error
+}
+
+// NewAlert
+/* @Description: 与 PostDingXxx 参数一致的告警，funcName 写入 params 的 func
+ * @param kind string
+ * @param code int64
+ * @param funcName string
+ * @param params map[string]interface{}
+ * @return *Alert
+ */
+func NewAlert(kind string, code int64, funcName string, params map[string]interface{}) *Alert {
+	mapParam := make(map[string]interface{}, len(params)+1)
+	for k, v := range params {
+		mapParam[k] = v
+	}
+	if "" != funcName {
+		mapParam["func"] = funcName
+	}
+	return &Alert{
+		Kind:   kind,
+		Code:   code,
+		Params: mapParam,
+		Time:   time.Now(),
+	}
+}
+
+// WithMention 设置需要@的人
+func (a *Alert) WithMention(mention Mention) *Alert {
+	a.Mention = mention
+	return a
+}
+
+func (m Mention) empty() bool {
+	return !m.All && 0 == len(m.Mobiles) && 0 == len(m.UserIds)
+}
+
+// title 注册了标题模板时按模板渲染
+func (a *Alert) title() string {
+	title, _ := a.render(a.alertData(), "\n")
+	return title
+}
+
+// header 如 【错误】标题
+func (a *Alert) header() string {
+	data := a.alertData()
+	title, _ := a.render(data, "\n")
+	return fmt.Sprintf("【%s】%s", data.KindName, title)
+}
+
+func (a *Alert) time() time.Time {
+	if a.Time.IsZero() {
+		return time.Now()
+	}
+	return a.Time
+}
+
+// keys 参数按名称排序，保证每次顺序一致
+func (a *Alert) keys() []string {
+	ret := make([]string, 0, len(a.Params))
+	for k := range a.Params {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (a *Alert) value(key string) string {
+	msg, _ := json.Marshal(a.Params[key])
+	return string(msg)
+}
+
+// markdown 钉钉/企业微信/飞书通用的 markdown 内容
+func (a *Alert) markdown() string {
+	data := a.alertData()
+	title, body := a.render(data, "\r\r\n")
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("#### 【%s】%s\n", data.KindName, title))
+	content.WriteString(body)
+	content.WriteString("\n\n ###### ")
+	content.WriteString(data.Time + "（" + data.Zone + "）")
+	return content.String()
+}
+
+// text 纯文本内容，用于 slack/邮件
+func (a *Alert) text() string {
+	data := a.alertData()
+	_, body := a.render(data, "\n")
+	return body + data.Time + "（" + data.Zone + "）"
+}
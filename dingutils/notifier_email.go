@@ -0,0 +1,81 @@
+package dingutils
+
+/**
+ * @Author: lee
+ * @Description: 邮件告警，smtp 发送纯文本邮件
+ * @File: notifier_email
+ * @Date: 2026-10-20 7:50 下午
+ */
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type EmailConfig struct {
+	Host     string   `json:"host"     yaml:"host"           mapstructure:"host"`
+	Port     int      `json:"port"     yaml:"port"           mapstructure:"port"` //默认25
+	User     string   `json:"user"     yaml:"user"           mapstructure:"user"` //为空不认证
+	Password string   `json:"password"     yaml:"password"   mapstructure:"password"`
+	From     string   `json:"from"     yaml:"from"           mapstructure:"from"` //为空同 user
+	To       []string `json:"to"     yaml:"to"               mapstructure:"to"`
+}
+
+type Email struct {
+	cfg  EmailConfig
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+var _ Notifier = (*Email)(nil)
+
+// NewEmail
+/* @Description: 创建邮件告警
+ * @param cfg EmailConfig
+ * @return *Email
+ * @return error
+ */
+func NewEmail(cfg EmailConfig) (*Email, error) {
+	if "" == cfg.Host {
+		return nil, fmt.Errorf("NewEmail host is empty")
+	}
+	if 0 == len(cfg.To) {
+		return nil, fmt.Errorf("NewEmail to is empty")
+	}
+	if 0 == cfg.Port {
+		cfg.Port = 25
+	}
+	if "" == cfg.From {
+		cfg.From = cfg.User
+	}
+	return &Email{
+		cfg:  cfg,
+		send: smtp.SendMail,
+	}, nil
+}
+
+func (e *Email) Notify(alert *Alert) error {
+	var auth smtp.Auth
+	if "" != e.cfg.User {
+		auth = smtp.PlainAuth("", e.cfg.User, e.cfg.Password, e.cfg.Host)
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + e.cfg.From + "\r\n")
+	msg.WriteString("To: " + strings.Join(e.cfg.To, ",") + "\r\n")
+	msg.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", alert.header()) + "\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(alert.text(), "\n", "\r\n"))
+
+	addr := net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port))
+	if err := e.send(addr, auth, e.cfg.From, e.cfg.To, []byte(msg.String())); nil != err {
+		return fmt.Errorf("Email|Notify err %s", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package dingutils
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: notifier_test
+ * @Date: 2026-10-20 8:40 下午
+ */
+
+type fakeWebhook struct {
+	*httptest.Server
+	rsp     string
+	bodies  []map[string]interface{}
+	queries []url.Values
+	mtx     sync.Mutex
+}
+
+func newFakeWebhook(rsp string) *fakeWebhook {
+	ret := &fakeWebhook{rsp: rsp}
+	ret.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		body := map[string]interface{}{}
+		_ = json.Unmarshal(data, &body)
+		ret.mtx.Lock()
+		ret.bodies = append(ret.bodies, body)
+		ret.queries = append(ret.queries, r.URL.Query())
+		ret.mtx.Unlock()
+		_, _ = w.Write([]byte(ret.rsp))
+	}))
+	return ret
+}
+
+func (f *fakeWebhook) count() int {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return len(f.bodies)
+}
+
+func Test_AlertManager(t *testing.T) {
+	ding := newFakeWebhook(`{"errcode":0,"errmsg":"ok"}`)
+	defer ding.Close()
+	feishu := newFakeWebhook(`{"code":0,"msg":"success"}`)
+	defer feishu.Close()
+	wecom := newFakeWebhook(`{"errcode":93000,"errmsg":"invalid webhook url"}`)
+	defer wecom.Close()
+	slack := newFakeWebhook(`ok`)
+	defer slack.Close()
+
+	manager, err := NewAlertManagerFromConfig(AlertConfig{
+		Channels: []ChannelConfig{
+			{Name: "ops", Type: ChannelDingTalk, Url: ding.URL},
+			{Name: "dev", Type: ChannelFeishu, Url: feishu.URL, Secret: "secret"},
+			{Name: "wx", Type: ChannelWeCom, Url: wecom.URL},
+			{Name: "slack", Type: ChannelSlack, Url: slack.URL},
+		},
+		Routes: []Route{
+			{Codes: []int64{WarnIndexPriceIsOld}, Channels: []string{"wx"}},
+			{Kinds: []string{"error"}, Channels: []string{"ops", "dev"}, Continue: true},
+			{Kinds: []string{KindError, KindWarn}, Channels: []string{"dev", "slack"}},
+		},
+		Default: []string{"slack"},
+	})
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	if err = manager.Notify(NewAlert(KindError, 1, "test", map[string]interface{}{"b": 2, "a": 1})); nil != err {
+		t.Fatal(err)
+	}
+	if 1 != ding.count() || 1 != feishu.count() || 1 != slack.count() || 0 != wecom.count() {
+		t.Fatalf("unexpected error route: %d %d %d %d", ding.count(), feishu.count(), slack.count(), wecom.count())
+	}
+	if "" == feishu.bodies[0]["sign"] || "interactive" != feishu.bodies[0]["msg_type"] {
+		t.Fatalf("unexpected feishu body: %v", feishu.bodies[0])
+	}
+	text := ding.bodies[0]["markdown"].(map[string]interface{})["text"].(string)
+	if !strings.Contains(text, "【错误】未定义告警") || strings.Index(text, "a: 1") > strings.Index(text, "b: 2") {
+		t.Fatalf("unexpected dingtalk text: %s", text)
+	}
+
+	//企业微信返回 errcode 时报错
+	err = manager.Notify(NewAlert(KindWarn, WarnIndexPriceIsOld, "test", nil))
+	if nil == err || !strings.Contains(err.Error(), "93000") || 1 != wecom.count() {
+		t.Fatalf("expect wecom errcode, got %v", err)
+	}
+
+	if err = manager.Notify(NewAlert(KindInfo, 2, "test", nil)); nil != err {
+		t.Fatal(err)
+	}
+	if 2 != slack.count() {
+		t.Fatalf("expect default route to slack, got %d", slack.count())
+	}
+
+	if _, err = NewAlertManagerFromConfig(AlertConfig{Routes: []Route{{Channels: []string{"none"}}}}); nil == err {
+		t.Fatal("expect unknown channel error")
+	}
+}
+
+func Test_EmailNotifier(t *testing.T) {
+	email, err := NewEmail(EmailConfig{Host: "smtp.example.com", User: "alert@example.com", To: []string{"ops@example.com"}})
+	if nil != err {
+		t.Fatal(err)
+	}
+	var msg string
+	email.send = func(addr string, a smtp.Auth, from string, to []string, data []byte) error {
+		if "smtp.example.com:25" != addr || "alert@example.com" != from || nil == a {
+			t.Fatalf("unexpected smtp args: %s %s", addr, from)
+		}
+		msg = string(data)
+		return nil
+	}
+	if err = email.Notify(NewAlert(KindWarn, WarnIndexPriceIsOld, "test", nil)); nil != err {
+		t.Fatal(err)
+	}
+	if !strings.Contains(msg, "Subject: =?UTF-8?b?") || !strings.Contains(msg, "func: \"test\"") {
+		t.Fatalf("unexpected email: %s", msg)
+	}
+}
+
+func Test_DingTalkSend(t *testing.T) {
+	ding := newFakeWebhook(`{"errcode":0,"errmsg":"ok"}`)
+	defer ding.Close()
+
+	bot, err := NewDingTalk(ding.URL+"/robot/send?access_token=token", "SECtest")
+	if nil != err {
+		t.Fatal(err)
+	}
+	alert := NewAlert(KindError, 1, "test", nil).WithMention(Mention{Mobiles: []string{"13800000000"}})
+	if err = bot.Notify(alert); nil != err {
+		t.Fatal(err)
+	}
+
+	query := ding.queries[0]
+	timestamp := query.Get("timestamp")
+	if "token" != query.Get("access_token") || "" == timestamp || dingSign(timestamp, "SECtest") != query.Get("sign") {
+		t.Fatalf("unexpected query: %v", query)
+	}
+	if ts, _ := strconv.ParseInt(timestamp, 10, 64); time.Since(time.Unix(0, ts*int64(time.Millisecond))) > time.Minute {
+		t.Fatalf("timestamp should be milliseconds: %s", timestamp)
+	}
+	body := ding.bodies[0]
+	at := body["at"].(map[string]interface{})
+	text := body["markdown"].(map[string]interface{})["text"].(string)
+	if "13800000000" != at["atMobiles"].([]interface{})[0] || !strings.HasSuffix(text, "@13800000000") {
+		t.Fatalf("unexpected at: %v", body)
+	}
+
+	if err = bot.Send(NewActionCardMsg("title", "text", DingButton{Title: "查看", ActionURL: "https://example.com"})); nil != err {
+		t.Fatal(err)
+	}
+	card := ding.bodies[1]["actionCard"].(map[string]interface{})
+	if "actionCard" != ding.bodies[1]["msgtype"] || "https://example.com" != card["singleURL"] {
+		t.Fatalf("unexpected action card: %v", ding.bodies[1])
+	}
+
+	ding.rsp = `{"errcode":130101,"errmsg":"send too fast"}`
+	err = bot.Send(NewTextMsg("too fast"))
+	if dingErr, ok := err.(*DingError); !ok || !dingErr.IsRateLimit() {
+		t.Fatalf("expect rate limit error, got %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package dingutils
+
+/**
+ * @Author: lee
+ * @Description: 飞书/企业微信/slack 机器人 webhook
+ * @File: notifier_webhook
+ * @Date: 2026-10-20 7:30 下午
+ */
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/0DeOrg/gutils/network"
+	"strconv"
+	"time"
+)
+
+type Feishu struct {
+	Url    string
+	secret string
+	client *network.RestAgent
+}
+
+var _ Notifier = (*Feishu)(nil)
+
+// NewFeishu
+/* @Description: 创建飞书/Lark 机器人
+ * @param url string webhook 地址
+ * @param secret string 签名校验密钥，未开启签名时为空
+ * @return *Feishu
+ * @return error
+ */
+func NewFeishu(url string, secret string) (*Feishu, error) {
+	client, err := newWebhookClient("NewFeishu", url)
+	if nil != err {
+		return nil, err
+	}
+	return &Feishu{
+		Url:    url,
+		secret: secret,
+		client: client,
+	}, nil
+}
+
+// Notify 以消息卡片发送，错误告警标题为红色
+func (f *Feishu) Notify(alert *Alert) error {
+	template := "blue"
+	switch alert.Kind {
+	case KindError:
+		template = "red"
+	case KindWarn:
+		template = "orange"
+	}
+
+	req := map[string]interface{}{
+		"msg_type": "interactive",
+		"card": map[string]interface{}{
+			"header": map[string]interface{}{
+				"title":    map[string]string{"tag": "plain_text", "content": alert.header()},
+				"template": template,
+			},
+			"elements": []interface{}{
+				map[string]string{"tag": "markdown", "content": alert.text()},
+			},
+		},
+	}
+	if "" != f.secret {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req["timestamp"] = timestamp
+		req["sign"] = feishuSign(timestamp, f.secret)
+	}
+
+	body, err := postWebhook(f.client, req)
+	if nil != err {
+		return fmt.Errorf("Feishu|Notify err %s", err.Error())
+	}
+
+	var rsp struct {
+		Code       int    `json:"code"`
+		Msg        string `json:"msg"`
+		StatusCode int    `json:"StatusCode"`
+	}
+	if err = json.Unmarshal([]byte(body), &rsp); nil != err {
+		return fmt.Errorf("Feishu|Notify Unmarshal err %s", err.Error())
+	}
+	if 0 != rsp.Code || 0 != rsp.StatusCode {
+		return fmt.Errorf("Feishu|Notify code: %d, msg: %s", rsp.Code+rsp.StatusCode, rsp.Msg)
+	}
+	return nil
+}
+
+// feishuSign 以 timestamp+"\n"+secret 为密钥对空串做 HmacSHA256
+func feishuSign(timestamp string, secret string) string {
+	h := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+type WeCom struct {
+	Url    string
+	client *network.RestAgent
+}
+
+var _ Notifier = (*WeCom)(nil)
+
+// NewWeCom
+/* @Description: 创建企业微信群机器人
+ * @param url string webhook 地址
+ * @return *WeCom
+ * @return error
+ */
+func NewWeCom(url string) (*WeCom, error) {
+	client, err := newWebhookClient("NewWeCom", url)
+	if nil != err {
+		return nil, err
+	}
+	return &WeCom{
+		Url:    url,
+		client: client,
+	}, nil
+}
+
+func (w *WeCom) Notify(alert *Alert) error {
+	req := map[string]interface{}{
+		"msgtype":  "markdown",
+		"markdown": map[string]string{"content": alert.markdown()},
+	}
+
+	body, err := postWebhook(w.client, req)
+	if nil != err {
+		return fmt.Errorf("WeCom|Notify err %s", err.Error())
+	}
+
+	var rsp struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err = json.Unmarshal([]byte(body), &rsp); nil != err {
+		return fmt.Errorf("WeCom|Notify Unmarshal err %s", err.Error())
+	}
+	if 0 != rsp.ErrCode {
+		return fmt.Errorf("WeCom|Notify errcode: %d, errmsg: %s", rsp.ErrCode, rsp.ErrMsg)
+	}
+	return nil
+}
+
+type Slack struct {
+	Url    string
+	client *network.RestAgent
+}
+
+var _ Notifier = (*Slack)(nil)
+
+// NewSlack
+/* @Description: 创建 slack incoming webhook
+ * @param url string webhook 地址
+ * @return *Slack
+ * @return error
+ */
+func NewSlack(url string) (*Slack, error) {
+	client, err := newWebhookClient("NewSlack", url)
+	if nil != err {
+		return nil, err
+	}
+	return &Slack{
+		Url:    url,
+		client: client,
+	}, nil
+}
+
+// Notify slack 成功时返回 ok，失败时返回非200状态码
+func (s *Slack) Notify(alert *Alert) error {
+	req := map[string]interface{}{
+		"text": fmt.Sprintf("*%s*\n```%s```", alert.header(), alert.text()),
+	}
+
+	if _, err := postWebhook(s.client, req); nil != err {
+		return fmt.Errorf("Slack|Notify err %s", err.Error())
+	}
+	return nil
+}
+
+func newWebhookClient(name string, url string) (*network.RestAgent, error) {
+	if "" == url {
+		return nil, fmt.Errorf("%s url is empty", name)
+	}
+	return network.NewRestClient(url, 0, false)
+}
+
+func postWebhook(client *network.RestAgent, req interface{}) (string, error) {
+	reqBody, err := json.Marshal(req)
+	if nil != err {
+		return "", err
+	}
+	return client.SimplePost("", string(reqBody), nil)
+}
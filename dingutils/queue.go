@@ -0,0 +1,386 @@
+package dingutils
+
+/**
+ * @Author: lee
+ * @Description: 异步告警队列，按 code 去重、全局限流、汇总重复次数，条件恢复时发送恢复通知
+ * @File: queue
+ * @Date: 2026-10-20 10:10 下午
+ */
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultDedupWindow    = 5 * time.Minute
+	defaultQueueRateLimit = 20 //钉钉机器人每分钟最多20条
+	defaultAlertQueueSize = 1024
+	defaultAlertIdle      = time.Hour
+	alertQueueTick        = time.Second
+)
+
+type AlertQueueConfig struct {
+	DedupWindow time.Duration           `json:"dedup-window"     yaml:"dedup-window"   mapstructure:"dedup-window"` //相同 code 的去重周期，默认5m
+	CodeWindows map[int64]time.Duration `json:"code-windows"     yaml:"code-windows"   mapstructure:"code-windows"` //按 code 单独设置去重周期
+	RateLimit   int                     `json:"rate-limit"     yaml:"rate-limit"       mapstructure:"rate-limit"`   //每分钟最多发送条数，默认20
+	QueueSize   int                     `json:"queue-size"     yaml:"queue-size"       mapstructure:"queue-size"`   //队列容量，满了丢弃，默认1024
+	IdleTimeout time.Duration           `json:"idle-timeout"     yaml:"idle-timeout"   mapstructure:"idle-timeout"` //按 code 去重的告警超过该时间未再触发时清除，之后不再发送恢复通知，默认1h，不小于去重周期
+}
+
+type alertState struct {
+	alert      *Alert
+	firstFired time.Time
+	lastFired  time.Time
+	lastSent   time.Time
+	total      int //从第一次触发到恢复的总次数
+	suppressed int //周期内被去重的次数
+}
+
+type queueOp struct {
+	alert   *Alert
+	resolve bool
+	flush   chan struct{}
+}
+
+type AlertQueue struct {
+	notifier  Notifier
+	window    time.Duration
+	windows   map[int64]time.Duration
+	rateLimit int
+	idle      time.Duration
+	tick      time.Duration
+	ops       chan queueOp
+	states    map[string]*alertState
+	pending   []*Alert    //限流等待发送
+	sent      []time.Time //最近一分钟的发送时间
+	dropped   uint64
+	stop      chan struct{}
+	done      chan struct{}
+	closed    sync.Once
+}
+
+var (
+	alertQueue    *AlertQueue
+	alertQueueMtx sync.RWMutex
+)
+
+// NewAlertQueue
+/* @Description: 创建异步告警队列，Post 不阻塞，相同 code 在去重周期内只发送一次，周期结束时汇总触发次数
+ * @param notifier Notifier 如 *DingTalk、*AlertManager
+ * @param cfg AlertQueueConfig
+ * @return *AlertQueue
+ */
+func NewAlertQueue(notifier Notifier, cfg AlertQueueConfig) *AlertQueue {
+	return newAlertQueue(notifier, cfg, alertQueueTick)
+}
+
+func newAlertQueue(notifier Notifier, cfg AlertQueueConfig, tick time.Duration) *AlertQueue {
+	if cfg.DedupWindow <= 0 {
+		cfg.DedupWindow = defaultDedupWindow
+	}
+	if cfg.RateLimit <= 0 {
+		cfg.RateLimit = defaultQueueRateLimit
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultAlertQueueSize
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = defaultAlertIdle
+	}
+
+	ret := &AlertQueue{
+		notifier:  notifier,
+		window:    cfg.DedupWindow,
+		windows:   cfg.CodeWindows,
+		rateLimit: cfg.RateLimit,
+		idle:      cfg.IdleTimeout,
+		tick:      tick,
+		ops:       make(chan queueOp, cfg.QueueSize),
+		states:    map[string]*alertState{},
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go ret.goProcess()
+	return ret
+}
+
+// EnableAlertQueue
+/* @Description: PostDingInfo/PostDingWarn/PostDingError 改为异步入队，已初始化 AlertManager 时通过其发送，否则通过钉钉机器人
+ * 重复调用时替换原来的队列，原队列发送完剩余告警后关闭
+ * @param cfg AlertQueueConfig
+ * @return error
+ */
+func EnableAlertQueue(cfg AlertQueueConfig) error {
+	var notifier Notifier
+	switch {
+	case nil != alertManager:
+		notifier = alertManager
+	case nil != dingBot:
+		notifier = dingBot
+	default:
+		return fmt.Errorf("EnableAlertQueue dingBot and alertManager are nil")
+	}
+	alertQueueMtx.Lock()
+	old := alertQueue
+	alertQueue = NewAlertQueue(notifier, cfg)
+	alertQueueMtx.Unlock()
+
+	if nil != old {
+		old.Close()
+	}
+	return nil
+}
+
+func getAlertQueue() *AlertQueue {
+	alertQueueMtx.RLock()
+	defer alertQueueMtx.RUnlock()
+	return alertQueue
+}
+
+// ResolveDing
+/* @Description: 告警条件已恢复，该 code 之前告警过时发送恢复通知
+ * @param code int64
+ * @param funcName string
+ * @param params map[string]interface{}
+ * @return error
+ */
+func ResolveDing(code int64, funcName string, params map[string]interface{}) error {
+	q := getAlertQueue()
+	if nil == q {
+		return fmt.Errorf("ResolveDing alertQueue is nil")
+	}
+	q.Resolve(NewAlert(KindInfo, code, funcName, params))
+	return nil
+}
+
+// Post 入队，队列满时丢弃
+func (q *AlertQueue) Post(alert *Alert) {
+	q.push(queueOp{alert: alert})
+}
+
+// Resolve 条件恢复，code 相同的告警之前触发过时发送恢复通知
+func (q *AlertQueue) Resolve(alert *Alert) {
+	q.push(queueOp{alert: alert, resolve: true})
+}
+
+// Flush
+/* @Description: 立即发送周期内的汇总及限流等待的告警，忽略限流，退出前调用，关闭后直接返回
+ */
+func (q *AlertQueue) Flush() {
+	ch := make(chan struct{})
+	select {
+	case q.ops <- queueOp{flush: ch}:
+	case <-q.done:
+		return
+	}
+	select {
+	case <-ch:
+	case <-q.done:
+	}
+}
+
+// Close
+/* @Description: 发送剩余告警后停止后台协程，之后入队的告警丢弃
+ */
+func (q *AlertQueue) Close() {
+	q.closed.Do(func() {
+		q.Flush()
+		close(q.stop)
+		<-q.done
+	})
+}
+
+// Dropped
+/* @Description: 队列满丢弃的告警数量
+ * @return uint64
+ */
+func (q *AlertQueue) Dropped() uint64 {
+	return atomic.LoadUint64(&q.dropped)
+}
+
+func (q *AlertQueue) push(op queueOp) {
+	select {
+	case <-q.stop:
+		atomic.AddUint64(&q.dropped, 1)
+		return
+	default:
+	}
+
+	select {
+	case q.ops <- op:
+	default:
+		atomic.AddUint64(&q.dropped, 1)
+	}
+}
+
+func (q *AlertQueue) goProcess() {
+	defer close(q.done)
+	tick := time.NewTicker(q.tick)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-q.stop:
+			atomic.AddUint64(&q.dropped, uint64(len(q.ops)))
+			return
+		case op := <-q.ops:
+			switch {
+			case nil != op.flush:
+				q.summarize(time.Now(), true)
+				q.sendPending(true)
+				close(op.flush)
+			case op.resolve:
+				q.resolve(op.alert)
+			default:
+				q.fire(op.alert)
+			}
+		case <-tick.C:
+			now := time.Now()
+			q.summarize(now, false)
+			q.evict(now)
+			q.sendPending(false)
+		}
+	}
+}
+
+func (q *AlertQueue) fire(alert *Alert) {
+	now := time.Now()
+	key := alertKey(alert)
+	st, ok := q.states[key]
+	if !ok {
+		q.states[key] = &alertState{alert: alert, firstFired: now, lastFired: now, lastSent: now, total: 1}
+		q.enqueue(alert)
+		return
+	}
+
+	st.total++
+	st.alert = alert
+	st.lastFired = now
+	if now.Sub(st.lastSent) < q.windowOf(alert.Code) {
+		st.suppressed++
+		return
+	}
+	st.lastSent = now
+	q.enqueue(alert)
+}
+
+func (q *AlertQueue) resolve(alert *Alert) {
+	key := alertKey(alert)
+	st, ok := q.states[key]
+	if !ok {
+		return
+	}
+	delete(q.states, key)
+
+	resolved := &Alert{
+		Kind:    KindInfo,
+		Code:    alert.Code,
+		Title:   "【已恢复】" + st.alert.title(),
+		Params:  map[string]interface{}{},
+		Time:    time.Now(),
+		Mention: st.alert.Mention,
+	}
+	for k, v := range alert.Params {
+		resolved.Params[k] = v
+	}
+	resolved.Params["fired"] = st.total
+	resolved.Params["duration"] = time.Since(st.firstFired).Truncate(time.Second).String()
+	q.enqueue(resolved)
+}
+
+// summarize 去重周期结束时，将周期内被抑制的次数汇总为一条
+func (q *AlertQueue) summarize(now time.Time, force bool) {
+	for _, st := range q.states {
+		window := q.windowOf(st.alert.Code)
+		if 0 == st.suppressed || (!force && now.Sub(st.lastSent) < window) {
+			continue
+		}
+
+		summary := *st.alert
+		summary.Params = make(map[string]interface{}, len(st.alert.Params)+1)
+		for k, v := range st.alert.Params {
+			summary.Params[k] = v
+		}
+		summary.Params["repeat"] = fmt.Sprintf("code %d fired %d times in %s", st.alert.Code, st.suppressed+1, window)
+		summary.Time = now
+
+		st.suppressed = 0
+		st.lastSent = now
+		q.enqueue(&summary)
+	}
+}
+
+// evict 清除长时间未再触发的告警状态，按标题去重的在去重周期结束后清除，按 code 的超过 idle 后清除
+func (q *AlertQueue) evict(now time.Time) {
+	for key, st := range q.states {
+		if st.suppressed > 0 {
+			continue
+		}
+		window := q.windowOf(st.alert.Code)
+		if 0 != st.alert.Code && q.idle > window {
+			window = q.idle
+		}
+		if now.Sub(st.lastFired) >= window {
+			delete(q.states, key)
+		}
+	}
+}
+
+func (q *AlertQueue) enqueue(alert *Alert) {
+	q.pending = append(q.pending, alert)
+	q.sendPending(false)
+}
+
+// sendPending 按限流发送，钉钉返回限流错误时留到下一轮
+func (q *AlertQueue) sendPending(force bool) {
+	now := time.Now()
+	recent := q.sent[:0]
+	for _, t := range q.sent {
+		if now.Sub(t) < time.Minute {
+			recent = append(recent, t)
+		}
+	}
+	q.sent = recent
+
+	for len(q.pending) > 0 {
+		if !force && len(q.sent) >= q.rateLimit {
+			return
+		}
+		alert := q.pending[0]
+		err := q.notifier.Notify(alert)
+		var dingErr *DingError
+		if nil != err && errors.As(err, &dingErr) && dingErr.IsRateLimit() && !force {
+			//占满本分钟的额度，下一分钟再发
+			for len(q.sent) < q.rateLimit {
+				q.sent = append(q.sent, now)
+			}
+			return
+		}
+		if nil != err {
+			fmt.Fprintf(os.Stderr, "alert queue notify code %d err: %s\n", alert.Code, err.Error())
+		}
+		q.pending = q.pending[1:]
+		q.sent = append(q.sent, now)
+	}
+}
+
+func (q *AlertQueue) windowOf(code int64) time.Duration {
+	if w, ok := q.windows[code]; ok && w > 0 {
+		return w
+	}
+	return q.window
+}
+
+// alertKey 按 code 去重，未定义 code 的按标题
+func alertKey(alert *Alert) string {
+	if 0 != alert.Code {
+		return strconv.FormatInt(alert.Code, 10)
+	}
+	return "title:" + alert.title()
+}
@@ -0,0 +1,134 @@
+package dingutils
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: queue_test
+ * @Date: 2026-10-20 10:40 下午
+ */
+
+type fakeNotifier struct {
+	alerts []*Alert
+	mtx    sync.Mutex
+}
+
+func (f *fakeNotifier) Notify(alert *Alert) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.alerts = append(f.alerts, alert)
+	return nil
+}
+
+func (f *fakeNotifier) wait(t *testing.T, n int) []*Alert {
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		f.mtx.Lock()
+		if len(f.alerts) >= n {
+			ret := append([]*Alert{}, f.alerts...)
+			f.mtx.Unlock()
+			return ret
+		}
+		f.mtx.Unlock()
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("expect %d alerts, got %d", n, len(f.alerts))
+	return nil
+}
+
+func Test_AlertQueue(t *testing.T) {
+	notifier := &fakeNotifier{}
+	q := newAlertQueue(notifier, AlertQueueConfig{DedupWindow: 100 * time.Millisecond}, 10*time.Millisecond)
+
+	for i := 0; i < 57; i++ {
+		q.Post(NewAlert(KindWarn, WarnIndexPriceIsOld, "test", nil))
+	}
+	alerts := notifier.wait(t, 2)
+	if DING_WARNING_MSG[WarnIndexPriceIsOld] != alerts[0].title() {
+		t.Fatalf("unexpected first alert: %+v", alerts[0])
+	}
+	if "code 1002 fired 57 times in 100ms" != alerts[1].Params["repeat"] {
+		t.Fatalf("unexpected summary: %+v", alerts[1].Params)
+	}
+
+	q.Resolve(NewAlert(KindInfo, WarnIndexPriceIsOld, "test", nil))
+	alerts = notifier.wait(t, 3)
+	if !strings.HasPrefix(alerts[2].Title, "【已恢复】") || 57 != alerts[2].Params["fired"] {
+		t.Fatalf("unexpected resolved alert: %+v", alerts[2])
+	}
+
+	//未触发过的不发送恢复通知
+	q.Resolve(NewAlert(KindInfo, WarnIndexPriceIsOld, "test", nil))
+	q.Flush()
+	if 3 != len(notifier.wait(t, 3)) {
+		t.Fatal("unexpected resolved alert for inactive code")
+	}
+}
+
+func Test_AlertQueueRateLimit(t *testing.T) {
+	notifier := &fakeNotifier{}
+	q := newAlertQueue(notifier, AlertQueueConfig{RateLimit: 2}, 10*time.Millisecond)
+
+	for code := int64(1); code <= 3; code++ {
+		q.Post(NewAlert(KindError, code, "test", nil))
+	}
+	notifier.wait(t, 2)
+	time.Sleep(50 * time.Millisecond)
+	notifier.mtx.Lock()
+	if 2 != len(notifier.alerts) {
+		t.Fatalf("expect rate limited to 2, got %d", len(notifier.alerts))
+	}
+	notifier.mtx.Unlock()
+
+	q.Flush()
+	alerts := notifier.wait(t, 3)
+	if 3 != alerts[2].Code {
+		t.Fatalf("unexpected flushed alert: %+v", alerts[2])
+	}
+}
+
+func Test_AlertQueueClose(t *testing.T) {
+	notifier := &fakeNotifier{}
+	q := newAlertQueue(notifier, AlertQueueConfig{RateLimit: 1}, time.Hour)
+
+	for code := int64(1); code <= 3; code++ {
+		q.Post(NewAlert(KindError, code, "test", nil))
+	}
+	q.Close()
+	if 3 != len(notifier.wait(t, 3)) {
+		t.Fatal("expect pending alerts flushed on close")
+	}
+
+	//关闭后丢弃，Flush/Close 不阻塞
+	q.Post(NewAlert(KindError, 4, "test", nil))
+	q.Flush()
+	q.Close()
+	if 3 != len(notifier.wait(t, 3)) || 1 != q.Dropped() {
+		t.Fatalf("expect alert dropped after close, dropped %d", q.Dropped())
+	}
+}
+
+func Test_AlertQueueEvict(t *testing.T) {
+	notifier := &fakeNotifier{}
+	q := newAlertQueue(notifier, AlertQueueConfig{DedupWindow: 20 * time.Millisecond}, 5*time.Millisecond)
+
+	q.Post(&Alert{Kind: KindWarn, Title: "no code"})
+	q.Post(NewAlert(KindWarn, WarnIndexPriceIsOld, "test", nil))
+	notifier.wait(t, 2)
+	time.Sleep(100 * time.Millisecond)
+	q.Close()
+
+	//按标题去重的过期清除，按 code 的保留到 IdleTimeout 以便发送恢复通知
+	if 1 != len(q.states) {
+		t.Fatalf("expect title state evicted, got %d states", len(q.states))
+	}
+	if _, ok := q.states[alertKey(NewAlert(KindWarn, WarnIndexPriceIsOld, "test", nil))]; !ok {
+		t.Fatal("expect code state kept")
+	}
+}
@@ -0,0 +1,310 @@
+package dingutils
+
+/**
+ * @Author: lee
+ * @Description: 告警 code 注册，每个 code 通过 text/template 定义标题和内容，支持时区、语言及从配置文件加载
+ * @File: registry
+ * @Date: 2026-10-20 11:10 下午
+ */
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/spf13/viper"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const (
+	LocaleZhCN = "zh-CN"
+	LocaleEnUS = "en-US"
+)
+
+// CodeDef 告警 code 定义，模板中可使用 AlertData 的字段，如 {{.Params.symbol}}、{{range .Fields}}
+type CodeDef struct {
+	Code  int64  `json:"code"     yaml:"code"     mapstructure:"code"`
+	Title string `json:"title"     yaml:"title"   mapstructure:"title"` //标题模板
+	Body  string `json:"body"     yaml:"body"     mapstructure:"body"`  //内容模板，为空时按参数名称排序逐行输出
+}
+
+// TemplateConfig 告警模板配置文件，如
+// timezone: Asia/Shanghai
+// locale: zh-CN
+// codes:
+//   - code: 1002
+//     title: "{{.Params.symbol}} 指数价格超过5s未更新"
+type TemplateConfig struct {
+	Timezone string    `json:"timezone"     yaml:"timezone"   mapstructure:"timezone"` //为空使用本地时区
+	Locale   string    `json:"locale"     yaml:"locale"       mapstructure:"locale"`   //zh-CN/en-US，默认zh-CN
+	Codes    []CodeDef `json:"codes"     yaml:"codes"         mapstructure:"codes"`
+}
+
+// Locale 告警中固定文字的语言
+type Locale struct {
+	Kinds     map[string]string //KindXxx 的显示名称
+	Undefined string            //未定义 code 的标题
+}
+
+// AlertParam 排序后的参数，Value 为 json
+type AlertParam struct {
+	Key   string
+	Value string
+}
+
+// AlertData 模板数据
+type AlertData struct {
+	Kind     string
+	KindName string //按语言显示的级别
+	Code     int64
+	Title    string //未注册模板时的标题，在内容模板中为渲染后的标题
+	Params   map[string]interface{}
+	Fields   []AlertParam
+	Time     string //按时区格式化的时间
+	Zone     string //如 UTC+8
+}
+
+type codeTemplate struct {
+	title *template.Template
+	body  *template.Template
+}
+
+var locales = map[string]Locale{
+	LocaleZhCN: {
+		Kinds:     map[string]string{KindError: "错误", KindWarn: "警告", KindInfo: "通知"},
+		Undefined: "未定义告警",
+	},
+	LocaleEnUS: {
+		Kinds:     map[string]string{KindError: "Error", KindWarn: "Warning", KindInfo: "Info"},
+		Undefined: "Undefined alert",
+	},
+}
+
+var (
+	codeTemplates = map[int64]*codeTemplate{}
+	alertLocale   = locales[LocaleZhCN]
+	alertLocation = time.Local
+	registryMtx   sync.RWMutex
+)
+
+// RegisterCode
+/* @Description: 注册告警 code 的标题及内容模板，重复注册时覆盖
+ * @param def CodeDef
+ * @return error
+ */
+func RegisterCode(def CodeDef) error {
+	tpl := &codeTemplate{}
+	var err error
+	if "" != def.Title {
+		if tpl.title, err = template.New(fmt.Sprintf("title-%d", def.Code)).Parse(def.Title); nil != err {
+			return fmt.Errorf("RegisterCode|Parse title %d err: %s", def.Code, err.Error())
+		}
+	}
+	if "" != def.Body {
+		if tpl.body, err = template.New(fmt.Sprintf("body-%d", def.Code)).Parse(def.Body); nil != err {
+			return fmt.Errorf("RegisterCode|Parse body %d err: %s", def.Code, err.Error())
+		}
+	}
+
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+	codeTemplates[def.Code] = tpl
+	return nil
+}
+
+// RegisterCodes
+/* @Description: 批量注册，有一个失败时都不注册
+ * @param defs []CodeDef
+ * @return error
+ */
+func RegisterCodes(defs []CodeDef) error {
+	for _, def := range defs {
+		if err := validateCode(def); nil != err {
+			return err
+		}
+	}
+	for _, def := range defs {
+		if err := RegisterCode(def); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadCodes
+/* @Description: 从配置文件(yaml/json/toml)加载时区、语言及 code 定义
+ * @param path string
+ * @return error
+ */
+func LoadCodes(path string) error {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); nil != err {
+		return fmt.Errorf("LoadCodes|ReadInConfig err: %s", err.Error())
+	}
+	var cfg TemplateConfig
+	if err := v.Unmarshal(&cfg); nil != err {
+		return fmt.Errorf("LoadCodes|Unmarshal err: %s", err.Error())
+	}
+	return ApplyTemplateConfig(cfg)
+}
+
+// ApplyTemplateConfig
+/* @Description: 应用模板配置，配置放在业务配置文件中时使用
+ * @param cfg TemplateConfig
+ * @return error
+ */
+func ApplyTemplateConfig(cfg TemplateConfig) error {
+	if "" != cfg.Timezone {
+		if err := SetTimezone(cfg.Timezone); nil != err {
+			return err
+		}
+	}
+	if "" != cfg.Locale {
+		if err := SetLocale(cfg.Locale); nil != err {
+			return err
+		}
+	}
+	return RegisterCodes(cfg.Codes)
+}
+
+// SetTimezone
+/* @Description: 告警时间的时区，如 Asia/Shanghai、UTC
+ * @param name string
+ * @return error
+ */
+func SetTimezone(name string) error {
+	loc, err := time.LoadLocation(name)
+	if nil != err {
+		return fmt.Errorf("SetTimezone|LoadLocation err: %s", err.Error())
+	}
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+	alertLocation = loc
+	return nil
+}
+
+// SetLocale
+/* @Description: 告警中级别及默认标题的语言
+ * @param name string zh-CN/en-US 或 RegisterLocale 注册的名称
+ * @return error
+ */
+func SetLocale(name string) error {
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+	locale, ok := locales[name]
+	if !ok {
+		return fmt.Errorf("SetLocale unknown locale: %s", name)
+	}
+	alertLocale = locale
+	return nil
+}
+
+// RegisterLocale 注册其他语言
+func RegisterLocale(name string, locale Locale) {
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+	locales[name] = locale
+}
+
+func validateCode(def CodeDef) error {
+	if _, err := template.New("").Parse(def.Title); nil != err {
+		return fmt.Errorf("RegisterCodes|Parse title %d err: %s", def.Code, err.Error())
+	}
+	if _, err := template.New("").Parse(def.Body); nil != err {
+		return fmt.Errorf("RegisterCodes|Parse body %d err: %s", def.Code, err.Error())
+	}
+	return nil
+}
+
+func lookupCode(code int64) (*codeTemplate, bool) {
+	registryMtx.RLock()
+	defer registryMtx.RUnlock()
+	tpl, ok := codeTemplates[code]
+	return tpl, ok
+}
+
+// alertData 模板数据，Title 为传入或旧的 code 映射中的标题
+func (a *Alert) alertData() *AlertData {
+	registryMtx.RLock()
+	locale := alertLocale
+	loc := alertLocation
+	registryMtx.RUnlock()
+
+	t := a.time().In(loc)
+	kindName, ok := locale.Kinds[a.Kind]
+	if !ok {
+		kindName = a.Kind
+	}
+	title := a.Title
+	if "" == title {
+		title = alertTitle(a.Code, locale.Undefined)
+	}
+
+	data := &AlertData{
+		Kind:     a.Kind,
+		KindName: kindName,
+		Code:     a.Code,
+		Title:    title,
+		Params:   a.Params,
+		Fields:   make([]AlertParam, 0, len(a.Params)),
+		Time:     t.Format("2006-01-02 15:04:05"),
+		Zone:     zoneName(t),
+	}
+	for _, key := range a.keys() {
+		data.Fields = append(data.Fields, AlertParam{Key: key, Value: a.value(key)})
+	}
+	return data
+}
+
+// render 渲染标题和内容，未注册模板或渲染失败时使用默认格式
+func (a *Alert) render(data *AlertData, sep string) (title string, body string) {
+	title = data.Title
+	tpl, ok := lookupCode(a.Code)
+	if ok && "" == a.Title && nil != tpl.title {
+		if s, err := execute(tpl.title, data); nil == err {
+			title = s
+		}
+	}
+
+	if ok && nil != tpl.body {
+		titled := *data
+		titled.Title = title
+		if s, err := execute(tpl.body, &titled); nil == err {
+			return title, strings.TrimRight(s, "\n") + sep
+		}
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("code: %d%s", a.Code, sep))
+	for _, f := range data.Fields {
+		content.WriteString(f.Key + ": " + f.Value + sep)
+	}
+	return title, content.String()
+}
+
+func execute(tpl *template.Template, data *AlertData) (string, error) {
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); nil != err {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// zoneName 如 UTC+8、UTC+5:30
+func zoneName(t time.Time) string {
+	_, offset := t.Zone()
+	if 0 == offset {
+		return "UTC"
+	}
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	if 0 == offset%3600 {
+		return fmt.Sprintf("UTC%s%d", sign, offset/3600)
+	}
+	return fmt.Sprintf("UTC%s%d:%02d", sign, offset/3600, offset%3600/60)
+}
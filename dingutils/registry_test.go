@@ -0,0 +1,73 @@
+package dingutils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: registry_test
+ * @Date: 2026-10-20 11:40 下午
+ */
+
+func Test_CodeRegistry(t *testing.T) {
+	defer func() {
+		_ = SetLocale(LocaleZhCN)
+		alertLocation = time.Local
+		codeTemplates = map[int64]*codeTemplate{}
+	}()
+
+	path := filepath.Join(t.TempDir(), "alert.yaml")
+	conf := `
+timezone: Asia/Shanghai
+locale: en-US
+codes:
+  - code: 2001
+    title: "{{.Params.symbol}} price is stale"
+    body: |
+      symbol: {{.Params.symbol}}
+      {{range .Fields}}{{if ne .Key "symbol"}}- {{.Key}}={{.Value}}
+      {{end}}{{end}}
+`
+	if err := os.WriteFile(path, []byte(conf), 0644); nil != err {
+		t.Fatal(err)
+	}
+	if err := LoadCodes(path); nil != err {
+		t.Fatal(err)
+	}
+
+	alert := &Alert{
+		Kind:   KindWarn,
+		Code:   2001,
+		Params: map[string]interface{}{"symbol": "BTCUSDT", "b": 2, "a": 1},
+		Time:   time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC),
+	}
+	md := alert.markdown()
+	if !strings.HasPrefix(md, "#### 【Warning】BTCUSDT price is stale\n") {
+		t.Fatalf("unexpected title: %s", md)
+	}
+	if !strings.Contains(md, "- a=1\n- b=2") || !strings.HasSuffix(md, "2026-10-20 08:00:00（UTC+8）") {
+		t.Fatalf("unexpected body: %s", md)
+	}
+
+	//未注册的 code 按参数名称排序，标题按语言
+	other := &Alert{Kind: KindError, Code: 9999, Params: map[string]interface{}{"z": 1, "a": 2}}
+	if text := other.text(); strings.Index(text, "a: 2") > strings.Index(text, "z: 1") {
+		t.Fatalf("unexpected order: %s", text)
+	}
+	if "【Error】Undefined alert" != other.header() {
+		t.Fatalf("unexpected header: %s", other.header())
+	}
+
+	if err := RegisterCodes([]CodeDef{{Code: 1, Title: "ok"}, {Code: 2, Title: "{{.Bad"}}); nil == err {
+		t.Fatal("expect template parse error")
+	}
+	if _, ok := lookupCode(1); ok {
+		t.Fatal("expect no code registered when one definition is invalid")
+	}
+}
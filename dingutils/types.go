@@ -22,5 +22,60 @@ type WarnDataReq struct {
 
 // 告警数据内容
 type WarnAtReq struct {
-	IsAtAll bool `json:"isAtAll"     binding:"required"` // 是否全部命中
+	AtMobiles []string `json:"atMobiles,omitempty"`            // 按手机号@
+	AtUserIds []string `json:"atUserIds,omitempty"`            // 按用户id@
+	IsAtAll   bool     `json:"isAtAll"     binding:"required"` // 是否全部命中
+}
+
+// 钉钉消息，按 MsgType 填写对应内容
+type DingMsg struct {
+	MsgType    string          `json:"msgtype"`
+	Text       *DingText       `json:"text,omitempty"`
+	MarkDown   *WarnDataReq    `json:"markdown,omitempty"`
+	Link       *DingLink       `json:"link,omitempty"`
+	ActionCard *DingActionCard `json:"actionCard,omitempty"`
+	FeedCard   *DingFeedCard   `json:"feedCard,omitempty"`
+	At         *WarnAtReq      `json:"at,omitempty"`
+}
+
+type DingText struct {
+	Content string `json:"content"`
+}
+
+type DingLink struct {
+	Title      string `json:"title"`
+	Text       string `json:"text"`
+	MessageUrl string `json:"messageUrl"`
+	PicUrl     string `json:"picUrl,omitempty"`
+}
+
+// 整体跳转时填写 SingleTitle/SingleURL，否则填写 Btns
+type DingActionCard struct {
+	Title          string       `json:"title"`
+	Text           string       `json:"text"`
+	SingleTitle    string       `json:"singleTitle,omitempty"`
+	SingleURL      string       `json:"singleURL,omitempty"`
+	BtnOrientation string       `json:"btnOrientation,omitempty"` // 0 按钮竖直排列，1 横向排列
+	Btns           []DingButton `json:"btns,omitempty"`
+}
+
+type DingButton struct {
+	Title     string `json:"title"`
+	ActionURL string `json:"actionURL"`
+}
+
+type DingFeedCard struct {
+	Links []DingFeedLink `json:"links"`
+}
+
+type DingFeedLink struct {
+	Title      string `json:"title"`
+	MessageURL string `json:"messageURL"`
+	PicURL     string `json:"picURL"`
+}
+
+// 钉钉返回结果
+type DingRsp struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
 }
@@ -0,0 +1,209 @@
+package dumputils
+
+/**
+ * @Author: lee
+ * @Description: 崩溃报告及协程堆栈导出，panic 时写入报告文件，SIGUSR1 时导出所有协程堆栈且不退出
+ * @File: crash
+ * @Date: 2026-10-21 11:30 上午
+ */
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/0DeOrg/gutils/fileutils"
+	"github.com/0DeOrg/gutils/logutils"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCrashDirectory = "crash"
+	defaultCrashLogTail   = 200
+	maxStackBytes         = 64 << 20
+)
+
+type CrashConfig struct {
+	Directory string `json:"directory"     yaml:"directory"   mapstructure:"directory"` //报告目录，默认 crash
+	LogTail   int    `json:"log-tail"     yaml:"log-tail"     mapstructure:"log-tail"`  //附带最近的日志行数，默认200，小于0不附带
+}
+
+var (
+	crashCfg    *CrashConfig
+	configHash  string
+	startTime   = time.Now()
+	crashMtx    sync.Mutex
+	dumpSigOnce sync.Once
+)
+
+// InitCrashReport
+/* @Description: 开启崩溃报告，HandlePanic 及守护协程放弃重启时写入报告，同时监听 SIGUSR1 导出协程堆栈
+ * @param cfg CrashConfig
+ * @return error
+ */
+func InitCrashReport(cfg CrashConfig) error {
+	if "" == cfg.Directory {
+		cfg.Directory = defaultCrashDirectory
+	}
+	if 0 == cfg.LogTail {
+		cfg.LogTail = defaultCrashLogTail
+	}
+	if err := fileutils.CreateDirectoryIfNotExist(cfg.Directory, os.ModePerm); nil != err {
+		return fmt.Errorf("InitCrashReport|CreateDirectory err: %s", err.Error())
+	}
+
+	crashMtx.Lock()
+	crashCfg = &cfg
+	crashMtx.Unlock()
+
+	dumpSigOnce.Do(watchDumpSignal)
+	return nil
+}
+
+// SetConfigHash
+/* @Description: 记录当前配置的 sha256，写入崩溃报告，配置热更新后再次调用
+ * @param config interface{} 配置结构体
+ */
+func SetConfigHash(config interface{}) {
+	data, err := json.Marshal(config)
+	if nil != err {
+		return
+	}
+	sum := sha256.Sum256(data)
+
+	crashMtx.Lock()
+	defer crashMtx.Unlock()
+	configHash = hex.EncodeToString(sum[:])
+}
+
+// WriteCrashReport
+/* @Description: 写入崩溃报告: panic 值、所有协程堆栈、编译信息、配置hash、最近的日志
+ * @param reason interface{} panic 的值
+ * @param stack string panic 协程的堆栈，已 recover 的协程堆栈不在 AllStacks 中，为空不写
+ * @return string 报告文件
+ * @return error
+ */
+func WriteCrashReport(reason interface{}, stack string) (string, error) {
+	crashMtx.Lock()
+	cfg := crashCfg
+	hash := configHash
+	crashMtx.Unlock()
+	if nil == cfg {
+		return "", fmt.Errorf("WriteCrashReport crash report not inited")
+	}
+
+	var report strings.Builder
+	now := time.Now()
+	report.WriteString("=== crash report ===\n")
+	report.WriteString(fmt.Sprintf("time: %s\n", now.Format("2006-01-02 15:04:05.000 -0700")))
+	report.WriteString(fmt.Sprintf("panic: %v\n", reason))
+	report.WriteString(fmt.Sprintf("config sha256: %s\n", hash))
+	if "" != stack {
+		report.WriteString("\n=== panic stack ===\n")
+		report.WriteString(stack + "\n")
+	}
+	report.WriteString("\n=== build ===\n")
+	report.WriteString(buildInfo())
+	report.WriteString("\n=== goroutines ===\n")
+	report.Write(AllStacks())
+
+	if cfg.LogTail > 0 {
+		report.WriteString("\n=== log tail ===\n")
+		lines, err := logutils.TailLog(cfg.LogTail)
+		if nil != err {
+			report.WriteString("read log err: " + err.Error() + "\n")
+		}
+		for _, line := range lines {
+			report.WriteString(line + "\n")
+		}
+	}
+
+	return writeDumpFile(cfg.Directory, "crash", now, report.String())
+}
+
+// DumpGoroutines
+/* @Description: 导出所有协程堆栈到文件，进程继续运行
+ * @return string 文件名
+ * @return error
+ */
+func DumpGoroutines() (string, error) {
+	crashMtx.Lock()
+	dir := defaultCrashDirectory
+	if nil != crashCfg {
+		dir = crashCfg.Directory
+	}
+	crashMtx.Unlock()
+
+	now := time.Now()
+	content := fmt.Sprintf("time: %s\ngoroutines: %d\n\n%s", now.Format("2006-01-02 15:04:05.000 -0700"),
+		runtime.NumGoroutine(), AllStacks())
+	return writeDumpFile(dir, "goroutines", now, content)
+}
+
+// AllStacks
+/* @Description: 所有协程的完整堆栈，缓冲不足时自动扩大
+ * @return []byte
+ */
+func AllStacks() []byte {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxStackBytes {
+			return buf[:n]
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+}
+
+// reportCrash 开启崩溃报告时写入，失败只记录日志
+func reportCrash(reason interface{}, stack string) {
+	crashMtx.Lock()
+	enabled := nil != crashCfg
+	crashMtx.Unlock()
+	if !enabled {
+		return
+	}
+
+	file, err := WriteCrashReport(reason, stack)
+	if nil != err {
+		dumpLog(zap.ErrorLevel, "WriteCrashReport failed", zap.Error(err))
+		return
+	}
+	dumpLog(zap.ErrorLevel, "crash report written", zap.String("file", file))
+}
+
+func writeDumpFile(dir string, prefix string, now time.Time, content string) (string, error) {
+	if err := fileutils.CreateDirectoryIfNotExist(dir, os.ModePerm); nil != err {
+		return "", err
+	}
+	name := filepath.Join(dir, fmt.Sprintf("%s-%s-%d.log", prefix, now.Format("20060102-150405.000"), os.Getpid()))
+	if err := os.WriteFile(name, []byte(content), 0644); nil != err {
+		return "", err
+	}
+	return name, nil
+}
+
+func buildInfo() string {
+	var ret strings.Builder
+	hostname, _ := os.Hostname()
+	ret.WriteString(fmt.Sprintf("go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH))
+	ret.WriteString(fmt.Sprintf("pid: %d, host: %s, uptime: %s\n", os.Getpid(), hostname, time.Since(startTime).Truncate(time.Second)))
+	ret.WriteString(fmt.Sprintf("args: %s\n", strings.Join(os.Args, " ")))
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ret.String()
+	}
+	ret.WriteString(fmt.Sprintf("main: %s %s\n", info.Main.Path, info.Main.Version))
+	for _, dep := range info.Deps {
+		ret.WriteString(fmt.Sprintf("dep: %s %s\n", dep.Path, dep.Version))
+	}
+	return ret.String()
+}
@@ -0,0 +1,50 @@
+package dumputils
+
+import (
+	"github.com/0DeOrg/gutils/logutils"
+	"go.uber.org/zap"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: crash_test
+ * @Date: 2026-10-21 12:10 下午
+ */
+
+func Test_CrashReport(t *testing.T) {
+	initTestLogger(t)
+	logutils.Info("before crash", zap.String("marker", "crash-tail-marker"))
+
+	if err := InitCrashReport(CrashConfig{Directory: t.TempDir()}); nil != err {
+		t.Fatal(err)
+	}
+	SetConfigHash(map[string]string{"env": "test"})
+
+	file, err := WriteCrashReport("bad message", "")
+	if nil != err {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file)
+	if nil != err {
+		t.Fatal(err)
+	}
+	report := string(data)
+	for _, expect := range []string{"panic: bad message", "config sha256: ", "Test_CrashReport", runtime.Version(), "crash-tail-marker"} {
+		if !strings.Contains(report, expect) {
+			t.Fatalf("report missing %q:\n%s", expect, report)
+		}
+	}
+
+	file, err = DumpGoroutines()
+	if nil != err {
+		t.Fatal(err)
+	}
+	if data, err = os.ReadFile(file); nil != err || !strings.Contains(string(data), "goroutine ") {
+		t.Fatalf("unexpected dump %s, err: %v", data, err)
+	}
+}
@@ -0,0 +1,33 @@
+//go:build !windows
+// +build !windows
+
+package dumputils
+
+/**
+ * @Author: lee
+ * @Description: SIGUSR1 导出协程堆栈，windows 没有该信号
+ * @File: dump_signal
+ * @Date: 2026-10-21 11:50 上午
+ */
+
+import (
+	"go.uber.org/zap"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func watchDumpSignal() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGUSR1)
+	go func() {
+		for range c {
+			file, err := DumpGoroutines()
+			if nil != err {
+				dumpLog(zap.ErrorLevel, "DumpGoroutines failed", zap.Error(err))
+				continue
+			}
+			dumpLog(zap.InfoLevel, "goroutines dumped", zap.String("file", file))
+		}
+	}()
+}
@@ -0,0 +1,14 @@
+//go:build windows
+// +build windows
+
+package dumputils
+
+/**
+ * @Author: lee
+ * @Description: windows 没有 SIGUSR1，通过 DumpGoroutines 手动导出
+ * @File: dump_signal_windows
+ * @Date: 2026-10-21 11:50 上午
+ */
+
+func watchDumpSignal() {
+}
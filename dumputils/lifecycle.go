@@ -0,0 +1,252 @@
+package dumputils
+
+/**
+ * @Author: lee
+ * @Description: 进程生命周期，各组件注册带优先级和超时的退出钩子，退出时按顺序只执行一次，SIGHUP 时重新加载
+ * @File: lifecycle
+ * @Date: 2026-10-21 2:10 下午
+ */
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 退出钩子优先级，数值小的先执行，相同优先级按注册顺序执行
+const (
+	PriorityRegistry = 10  //服务注销，如 consul，先摘流量
+	PriorityServer   = 20  //停止接收请求，如 http/websocket 服务
+	PriorityDefault  = 50  //业务组件
+	PriorityMQ       = 60  //mq 生产者、消费者
+	PriorityStorage  = 70  //raft 节点、数据库
+	PriorityLogger   = 100 //日志 sink，最后的日志刷盘在所有钩子之后自动执行
+)
+
+const DefaultShutdownTimeout = 30 * time.Second
+
+type hook struct {
+	name     string
+	priority int
+	timeout  time.Duration
+	seq      int
+	fn       func(ctx context.Context) error
+}
+
+type reloadHook struct {
+	name string
+	fn   func() error
+}
+
+type lifecycle struct {
+	mtx          sync.Mutex
+	timeout      time.Duration
+	hooks        []*hook
+	reloads      []*reloadHook
+	reloadMtx    sync.Mutex //重新加载串行执行
+	shuttingDown int32
+	signals      int32 //收到的退出信号次数
+	once         sync.Once
+	done         chan struct{}
+	err          error
+}
+
+var std = newLifecycle()
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{
+		timeout: DefaultShutdownTimeout,
+		done:    make(chan struct{}),
+	}
+}
+
+// OnShutdown
+/* @Description: 注册退出钩子，如 dumputils.OnShutdown("consul", dumputils.PriorityRegistry, 5*time.Second, deregister)
+ * @param name string 名称，用于日志
+ * @param priority int 数值小的先执行
+ * @param timeout time.Duration 单个钩子的超时，<=0 时只受整体截止时间限制，超时后不再等待继续执行下一个
+ * @param fn func(ctx context.Context) error
+ */
+func OnShutdown(name string, priority int, timeout time.Duration, fn func(ctx context.Context) error) {
+	std.onShutdown(name, priority, timeout, fn)
+}
+
+// OnReload
+/* @Description: 注册重新加载回调，收到 SIGHUP 时按注册顺序执行，如重新读取配置
+ * @param name string
+ * @param fn func() error
+ */
+func OnReload(name string, fn func() error) {
+	std.onReload(name, fn)
+}
+
+// SetShutdownTimeout 所有退出钩子的整体截止时间，默认30s
+func SetShutdownTimeout(timeout time.Duration) {
+	std.mtx.Lock()
+	defer std.mtx.Unlock()
+	if timeout > 0 {
+		std.timeout = timeout
+	}
+}
+
+// Shutdown
+/* @Description: 按优先级执行退出钩子，只执行一次，并发调用时等待第一次执行完成，最后写出缓冲中的日志
+ * @return error 钩子返回的错误及超时
+ */
+func Shutdown() error {
+	return std.shutdown()
+}
+
+// Reload
+/* @Description: 执行重新加载回调，退出过程中不执行
+ * @return error
+ */
+func Reload() error {
+	return std.reload()
+}
+
+// ShuttingDown 是否已开始退出
+func ShuttingDown() bool {
+	return 1 == atomic.LoadInt32(&std.shuttingDown)
+}
+
+// Done 退出钩子执行完成后关闭
+func Done() <-chan struct{} {
+	return std.done
+}
+
+// Wait
+/* @Description: 阻塞到退出钩子执行完成，放在 main 的最后，配合 ListenSignal 使用
+ * @return error Shutdown 的结果
+ */
+func Wait() error {
+	<-std.done
+	return std.err
+}
+
+func (l *lifecycle) onShutdown(name string, priority int, timeout time.Duration, fn func(ctx context.Context) error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.hooks = append(l.hooks, &hook{name: name, priority: priority, timeout: timeout, seq: len(l.hooks), fn: fn})
+}
+
+func (l *lifecycle) onReload(name string, fn func() error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.reloads = append(l.reloads, &reloadHook{name: name, fn: fn})
+}
+
+func (l *lifecycle) shutdown() error {
+	l.once.Do(func() {
+		atomic.StoreInt32(&l.shuttingDown, 1)
+		defer close(l.done)
+
+		l.mtx.Lock()
+		hooks := make([]*hook, len(l.hooks))
+		copy(hooks, l.hooks)
+		timeout := l.timeout
+		l.mtx.Unlock()
+
+		sort.Slice(hooks, func(i, j int) bool {
+			if hooks[i].priority != hooks[j].priority {
+				return hooks[i].priority < hooks[j].priority
+			}
+			return hooks[i].seq < hooks[j].seq
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		var errs []string
+		for i, h := range hooks {
+			if nil != ctx.Err() {
+				skipped := make([]string, 0, len(hooks)-i)
+				for _, rest := range hooks[i:] {
+					skipped = append(skipped, rest.name)
+				}
+				dumpLog(zap.ErrorLevel, "shutdown deadline exceeded, skip hooks", zap.Strings("hooks", skipped))
+				errs = append(errs, fmt.Sprintf("deadline %s exceeded, skip: %s", timeout, strings.Join(skipped, ",")))
+				break
+			}
+
+			start := time.Now()
+			if err := runHook(ctx, h); nil != err {
+				dumpLog(zap.ErrorLevel, "shutdown hook fatal", zap.String("hook", h.name), zap.Int("priority", h.priority),
+					zap.Duration("elapse", time.Since(start)), zap.Error(err))
+				errs = append(errs, h.name+": "+err.Error())
+				continue
+			}
+			dumpLog(zap.InfoLevel, "shutdown hook done", zap.String("hook", h.name), zap.Int("priority", h.priority),
+				zap.Duration("elapse", time.Since(start)))
+		}
+
+		if len(errs) > 0 {
+			l.err = fmt.Errorf("Shutdown err: %s", strings.Join(errs, "; "))
+		}
+		//最后的日志刷盘也受整体截止时间限制
+		deadline, _ := ctx.Deadline()
+		syncLog(time.Until(deadline))
+	})
+
+	<-l.done
+	return l.err
+}
+
+// runHook 超时后不再等待，钩子所在的协程继续运行直到进程退出
+func runHook(parent context.Context, h *hook) error {
+	ctx := parent
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(parent, h.timeout)
+		defer cancel()
+	}
+
+	ch := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); nil != p {
+				ch <- fmt.Errorf("panic: %v", p)
+			}
+		}()
+		ch <- h.fn(ctx)
+	}()
+
+	select {
+	case err := <-ch:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timeout: %s", ctx.Err().Error())
+	}
+}
+
+func (l *lifecycle) reload() error {
+	if 1 == atomic.LoadInt32(&l.shuttingDown) {
+		return fmt.Errorf("Reload shutting down")
+	}
+	l.reloadMtx.Lock()
+	defer l.reloadMtx.Unlock()
+
+	l.mtx.Lock()
+	reloads := make([]*reloadHook, len(l.reloads))
+	copy(reloads, l.reloads)
+	l.mtx.Unlock()
+
+	var errs []string
+	for _, r := range reloads {
+		if err := r.fn(); nil != err {
+			dumpLog(zap.ErrorLevel, "reload fatal", zap.String("hook", r.name), zap.Error(err))
+			errs = append(errs, r.name+": "+err.Error())
+			continue
+		}
+		dumpLog(zap.InfoLevel, "reload done", zap.String("hook", r.name))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("Reload err: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
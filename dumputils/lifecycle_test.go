@@ -0,0 +1,166 @@
+package dumputils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: lifecycle_test
+ * @Date: 2026-10-21 2:50 下午
+ */
+
+func Test_Lifecycle(t *testing.T) {
+	initTestLogger(t)
+
+	l := newLifecycle()
+	l.timeout = time.Second
+
+	var order []string
+	var mtx sync.Mutex
+	record := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			mtx.Lock()
+			defer mtx.Unlock()
+			order = append(order, name)
+			return nil
+		}
+	}
+	l.onShutdown("logger", PriorityLogger, 0, record("logger"))
+	l.onShutdown("server", PriorityServer, 0, record("server"))
+	l.onShutdown("consul", PriorityRegistry, 0, record("consul"))
+	l.onShutdown("mq", PriorityMQ, 0, record("mq"))
+	l.onShutdown("hang", PriorityMQ, 50*time.Millisecond, func(ctx context.Context) error {
+		time.Sleep(time.Hour)
+		return nil
+	})
+	l.onShutdown("raft", PriorityStorage, 0, record("raft"))
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = l.shutdown()
+		}(i)
+	}
+	wg.Wait()
+
+	if "consul,server,mq,raft,logger" != strings.Join(order, ",") {
+		t.Fatalf("unexpected order: %v", order)
+	}
+	for _, err := range errs {
+		if nil == err || !strings.Contains(err.Error(), "hang: timeout") {
+			t.Fatalf("expect hang timeout, got %v", err)
+		}
+	}
+	if err := l.reload(); nil == err {
+		t.Fatal("expect reload rejected after shutdown")
+	}
+}
+
+func Test_LifecycleDeadline(t *testing.T) {
+	initTestLogger(t)
+
+	l := newLifecycle()
+	l.timeout = 50 * time.Millisecond
+	l.onShutdown("slow", PriorityDefault, 0, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	skipped := true
+	l.onShutdown("after", PriorityLogger, 0, func(ctx context.Context) error {
+		skipped = false
+		return nil
+	})
+
+	err := l.shutdown()
+	if nil == err || !strings.Contains(err.Error(), "skip: after") || !skipped {
+		t.Fatalf("expect after skipped, got %v", err)
+	}
+}
+
+// Test_WatchSignal 直接调用 Shutdown 后第一次信号不强制退出，第二次才退出
+func Test_WatchSignal(t *testing.T) {
+	initTestLogger(t)
+
+	exited := make(chan int, 2)
+	exitFunc = func(code int) {
+		exited <- code
+	}
+	defer func() {
+		exitFunc = os.Exit
+	}()
+
+	l := newLifecycle()
+	_ = l.shutdown()
+
+	c := make(chan os.Signal, 2)
+	defer close(c)
+	go watchSignal(l, c)
+
+	c <- syscall.SIGTERM
+	select {
+	case <-exited:
+		t.Fatal("unexpected force exit on first signal")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	c <- syscall.SIGTERM
+	select {
+	case code := <-exited:
+		if 1 != code {
+			t.Fatalf("unexpected exit code %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expect force exit on second signal")
+	}
+}
+
+// Test_WatchSignalNotInit 日志初始化前收到信号不 panic，在子进程中运行以保证日志未初始化
+func Test_WatchSignalNotInit(t *testing.T) {
+	if "1" != os.Getenv("DUMPUTILS_NOT_INIT") {
+		cmd := exec.Command(os.Args[0], "-test.run=^Test_WatchSignalNotInit$")
+		cmd.Env = append(os.Environ(), "DUMPUTILS_NOT_INIT=1")
+		out, err := cmd.CombinedOutput()
+		if nil != err || !strings.Contains(string(out), "receive signal again, force exit") {
+			t.Fatalf("unexpected child result: %v\n%s", err, out)
+		}
+		return
+	}
+
+	exited := make(chan int, 1)
+	exitFunc = func(code int) {
+		exited <- code
+	}
+
+	l := newLifecycle()
+	l.onShutdown("fatal", PriorityDefault, 0, func(ctx context.Context) error {
+		return fmt.Errorf("fatal")
+	})
+	l.onReload("fatal", func() error {
+		return fmt.Errorf("fatal")
+	})
+
+	c := make(chan os.Signal, 3)
+	go watchSignal(l, c)
+	c <- syscall.SIGHUP
+	c <- syscall.SIGTERM
+	<-l.done
+	c <- syscall.SIGTERM
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatal("expect force exit on second signal")
+	}
+}
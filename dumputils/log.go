@@ -0,0 +1,47 @@
+package dumputils
+
+/**
+ * @Author: lee
+ * @Description: 信号、退出钩子及守护协程可能早于日志初始化触发，未初始化时写到标准错误
+ * @File: log
+ * @Date: 2026-10-22 10:20 上午
+ */
+
+import (
+	"fmt"
+	"github.com/0DeOrg/gutils/logutils"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"time"
+)
+
+// dumpLog 日志未初始化时不 panic，写到标准错误
+func dumpLog(level zapcore.Level, msg string, fields ...zap.Field) {
+	if logutils.IsInit() {
+		switch {
+		case level >= zap.ErrorLevel:
+			logutils.Error(msg, fields...)
+		case zap.WarnLevel == level:
+			logutils.Warn(msg, fields...)
+		default:
+			logutils.Info(msg, fields...)
+		}
+		return
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	fmt.Fprintf(os.Stderr, "%s %s %v\n", level.CapitalString(), msg, enc.Fields)
+}
+
+// syncLog 限时写出日志，timeout<=0 时只写出本地异步队列，不等待远程输出及告警
+func syncLog(timeout time.Duration) {
+	if !logutils.IsInit() {
+		return
+	}
+	logutils.FlushLimited()
+	_ = logutils.SyncTimeout(timeout)
+}
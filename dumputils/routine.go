@@ -50,6 +50,7 @@ func HandlePanic(params ...interface{}) {
 
 		logutils.Error("frame function, file, line", zap.String("func", frame.Function), zap.String("file", frame.File),
 			zap.Int("line", frame.Line))
+		reportCrash(r, stack)
 		logutils.Panic("panic stack:", zap.Error(err), zap.String("stack", stack))
 
 	}
@@ -88,14 +89,32 @@ func SkipPanic(params ...interface{}) {
 	}
 }
 
+// PanicTrace
+/* @Description: panic 协程从 panic 处开始的堆栈，缓冲不足时自动扩大
+ * @param kb int 初始缓冲大小
+ * @return []byte
+ */
 func PanicTrace(kb int) []byte {
 	s := []byte("/src/runtime/panic.go")
 	e := []byte("\ngoroutine ")
 	line := []byte("\n")
-	stack := make([]byte, kb<<10) //4KB
-	length := runtime.Stack(stack, true)
+	if kb <= 0 {
+		kb = 4
+	}
+	stack := make([]byte, kb<<10)
+	for {
+		length := runtime.Stack(stack, true)
+		if length < len(stack) || len(stack) >= maxStackBytes {
+			stack = stack[:length]
+			break
+		}
+		stack = make([]byte, len(stack)*2)
+	}
 	start := bytes.Index(stack, s)
-	stack = stack[start:length]
+	if -1 == start {
+		return stack
+	}
+	stack = stack[start:]
 	start = bytes.Index(stack, line) + 1
 	stack = stack[start:]
 	end := bytes.LastIndex(stack, line)
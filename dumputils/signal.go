@@ -8,26 +8,76 @@ package dumputils
  */
 
 import (
-	"fmt"
+	"context"
+	"go.uber.org/zap"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
 )
 
+var (
+	listenOnce sync.Once
+	exitFunc   = os.Exit
+)
+
+// ListenSignal
+/* @Description: 监听退出信号，SIGINT/SIGTERM/SIGQUIT 时执行 Shutdown，执行中再次收到时强制退出，SIGHUP 时执行 Reload
+ * main 中调用 Wait 阻塞到退出钩子执行完成，重复调用只监听一次
+ */
+func ListenSignal() {
+	listenOnce.Do(func() {
+		c := make(chan os.Signal, 2)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
+		go watchSignal(std, c)
+	})
+}
+
+// RegisterSignal
+/* @Description: 退出信号时执行 cbExit，只执行一次
+ * Deprecated: 使用 OnShutdown 注册退出钩子，ListenSignal 监听信号，Wait 等待退出
+ * @param cbExit func()
+ */
 func RegisterSignal(cbExit func()) {
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
-	go func() {
-		for s := range c {
-			switch s {
-			case syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT:
-				if nil != cbExit {
-					cbExit()
-				}
+	if nil != cbExit {
+		OnShutdown("RegisterSignal", PriorityDefault, 0, func(ctx context.Context) error {
+			cbExit()
+			return nil
+		})
+	}
+	ListenSignal()
+}
 
-			default:
-				fmt.Println("other signal", s)
+// watchSignal 第二次收到退出信号时强制退出，直接调用 Shutdown 后的第一次信号只等待退出完成
+func watchSignal(l *lifecycle, c chan os.Signal) {
+	for s := range c {
+		switch s {
+		case syscall.SIGHUP:
+			if 1 == atomic.LoadInt32(&l.shuttingDown) {
+				continue
+			}
+			dumpLog(zap.WarnLevel, "receive signal, reload", zap.String("signal", s.String()))
+			go func() {
+				_ = l.reload()
+			}()
+
+		default:
+			if atomic.AddInt32(&l.signals, 1) > 1 {
+				dumpLog(zap.ErrorLevel, "receive signal again, force exit", zap.String("signal", s.String()))
+				//只写出本地异步队列，远程输出可能卡住
+				syncLog(0)
+				exitFunc(1)
+				continue
+			}
+			if 1 == atomic.LoadInt32(&l.shuttingDown) {
+				dumpLog(zap.WarnLevel, "receive signal while shutting down, send again to force exit", zap.String("signal", s.String()))
+				continue
 			}
+			dumpLog(zap.WarnLevel, "receive signal, shutdown", zap.String("signal", s.String()))
+			go func() {
+				_ = l.shutdown()
+			}()
 		}
-	}()
+	}
 }
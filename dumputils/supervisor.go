@@ -0,0 +1,269 @@
+package dumputils
+
+/**
+ * @Author: lee
+ * @Description: 协程守护，panic 后按策略退避重启，超过次数时上报，可列出存活的协程及重启次数
+ * @File: supervisor
+ * @Date: 2026-10-21 10:10 上午
+ */
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type RestartMode int
+
+const (
+	RestartNever   RestartMode = iota //panic 后不重启
+	RestartAlways                     //panic 后总是重启
+	RestartLimited                    //Window 内最多重启 MaxRestarts 次
+)
+
+const (
+	RoutineRunning    = "running"
+	RoutineRestarting = "restarting"
+	RoutineFailed     = "failed" //panic 后不再重启
+)
+
+const (
+	defaultRestartWindow = time.Minute
+	defaultBackoff       = 100 * time.Millisecond
+	defaultMaxBackoff    = 30 * time.Second
+	maxFailedRoutines    = 32 //保留最近失败的协程用于排查
+)
+
+type Policy struct {
+	Mode        RestartMode
+	MaxRestarts int                               //RestartLimited 时 Window 内的最大重启次数
+	Window      time.Duration                     //默认1m
+	Backoff     time.Duration                     //第一次重启的等待时间，之后翻倍，默认100ms
+	MaxBackoff  time.Duration                     //默认30s
+	OnEscalate  func(name string, panicErr error) //不再重启时调用，如发送告警或退出进程，默认写 error 日志
+}
+
+// PolicyNever panic 后只记录日志，不重启也不影响其他协程
+func PolicyNever() Policy {
+	return Policy{Mode: RestartNever}
+}
+
+// PolicyAlways panic 后总是退避重启
+func PolicyAlways() Policy {
+	return Policy{Mode: RestartAlways}
+}
+
+// PolicyMax window 内重启超过 n 次后上报
+func PolicyMax(n int, window time.Duration) Policy {
+	return Policy{Mode: RestartLimited, MaxRestarts: n, Window: window}
+}
+
+// RoutineInfo 守护协程的状态
+type RoutineInfo struct {
+	Id          int64
+	Name        string
+	State       string
+	Started     time.Time //第一次启动时间
+	Restarts    int
+	LastPanic   string
+	LastRestart time.Time
+}
+
+type routine struct {
+	info     RoutineInfo
+	policy   Policy
+	fn       func()
+	restarts []time.Time //Window 内的重启时间
+	mtx      sync.Mutex
+}
+
+var (
+	routines    = map[int64]*routine{}
+	failed      []*routine //最近不再重启的协程，最多 maxFailedRoutines 个
+	routinesMtx sync.RWMutex
+	routineSeq  int64
+)
+
+// Go
+/* @Description: 启动守护协程，fn 正常返回后结束，panic 时记录日志并按策略重启，不再使用 defer HandlePanic
+ * 如 dumputils.Go("ws-receive", ws.doReceive, dumputils.PolicyMax(5, time.Minute))
+ * @param name string 名称，用于日志及 Routines
+ * @param fn func()
+ * @param policy Policy
+ * @return int64 id
+ */
+func Go(name string, fn func(), policy Policy) int64 {
+	if policy.Window <= 0 {
+		policy.Window = defaultRestartWindow
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = defaultBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaultMaxBackoff
+	}
+
+	r := &routine{
+		info: RoutineInfo{
+			Id:      atomic.AddInt64(&routineSeq, 1),
+			Name:    name,
+			State:   RoutineRunning,
+			Started: time.Now(),
+		},
+		policy: policy,
+		fn:     fn,
+	}
+
+	routinesMtx.Lock()
+	routines[r.info.Id] = r
+	routinesMtx.Unlock()
+
+	go r.supervise()
+	return r.info.Id
+}
+
+// Routines
+/* @Description: 存活及最近失败的守护协程，按 id 排序，用于排查
+ * @return []RoutineInfo
+ */
+func Routines() []RoutineInfo {
+	routinesMtx.RLock()
+	ret := make([]RoutineInfo, 0, len(routines)+len(failed))
+	for _, r := range routines {
+		r.mtx.Lock()
+		ret = append(ret, r.info)
+		r.mtx.Unlock()
+	}
+	for _, r := range failed {
+		r.mtx.Lock()
+		ret = append(ret, r.info)
+		r.mtx.Unlock()
+	}
+	routinesMtx.RUnlock()
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Id < ret[j].Id
+	})
+	return ret
+}
+
+func (r *routine) supervise() {
+	backoff := r.policy.Backoff
+	for {
+		start := time.Now()
+		err, stack := r.run()
+		if nil == err {
+			routinesMtx.Lock()
+			delete(routines, r.info.Id)
+			routinesMtx.Unlock()
+			return
+		}
+		//稳定运行超过 Window 后重新从 Backoff 开始退避
+		if time.Since(start) > r.policy.Window {
+			backoff = r.policy.Backoff
+		}
+
+		dumpLog(zap.ErrorLevel, "supervised goroutine panic", zap.String("routine", r.info.Name), zap.Error(err),
+			zap.String("stack", stack))
+
+		if !r.allowRestart(err) {
+			r.retire()
+			r.escalate(err, stack)
+			return
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > r.policy.MaxBackoff {
+			backoff = r.policy.MaxBackoff
+		}
+
+		r.mtx.Lock()
+		r.info.State = RoutineRunning
+		r.info.Restarts++
+		r.info.LastRestart = time.Now()
+		restarts := r.info.Restarts
+		r.mtx.Unlock()
+		dumpLog(zap.WarnLevel, "supervised goroutine restarted", zap.String("routine", r.info.Name), zap.Int("restarts", restarts))
+	}
+}
+
+// run 返回 panic 的值，正常返回时为 nil
+func (r *routine) run() (err error, stack string) {
+	defer func() {
+		if p := recover(); nil != p {
+			err = panicError(p)
+			stack = string(debug.Stack())
+		}
+	}()
+	r.fn()
+	return nil, ""
+}
+
+func (r *routine) allowRestart(err error) bool {
+	now := time.Now()
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.info.LastPanic = err.Error()
+	switch r.policy.Mode {
+	case RestartAlways:
+	case RestartLimited:
+		recent := r.restarts[:0]
+		for _, t := range r.restarts {
+			if now.Sub(t) < r.policy.Window {
+				recent = append(recent, t)
+			}
+		}
+		r.restarts = recent
+		if len(r.restarts) >= r.policy.MaxRestarts {
+			r.info.State = RoutineFailed
+			return false
+		}
+		r.restarts = append(r.restarts, now)
+	default:
+		r.info.State = RoutineFailed
+		return false
+	}
+	r.info.State = RoutineRestarting
+	return true
+}
+
+// retire 不再重启的协程移出 routines，只保留最近 maxFailedRoutines 个
+func (r *routine) retire() {
+	routinesMtx.Lock()
+	defer routinesMtx.Unlock()
+
+	delete(routines, r.info.Id)
+	failed = append(failed, r)
+	if len(failed) > maxFailedRoutines {
+		failed = append(failed[:0], failed[len(failed)-maxFailedRoutines:]...)
+	}
+}
+
+func (r *routine) escalate(err error, stack string) {
+	reportCrash(err, stack)
+	if RestartNever != r.policy.Mode {
+		err = fmt.Errorf("restart limit %d in %s exceeded, last panic: %s", r.policy.MaxRestarts, r.policy.Window, err.Error())
+	}
+	if nil != r.policy.OnEscalate {
+		r.policy.OnEscalate(r.info.Name, err)
+		return
+	}
+	dumpLog(zap.ErrorLevel, "supervised goroutine stopped", zap.String("routine", r.info.Name), zap.Error(err))
+}
+
+func panicError(p interface{}) error {
+	switch v := p.(type) {
+	case error:
+		return v
+	case string:
+		return fmt.Errorf("%s", v)
+	default:
+		return fmt.Errorf("%v", v)
+	}
+}
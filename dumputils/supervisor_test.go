@@ -0,0 +1,162 @@
+package dumputils
+
+import (
+	"github.com/0DeOrg/gutils/logutils"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: supervisor_test
+ * @Date: 2026-10-21 10:40 上午
+ */
+
+func initTestLogger(t *testing.T) {
+	if logutils.IsInit() {
+		return
+	}
+	cfg := logutils.DefaultZapConfig
+	cfg.Directory = t.TempDir()
+	cfg.LinkName = ""
+	cfg.LogInConsole = false
+	logutils.InitLogger(cfg)
+}
+
+func Test_Supervisor(t *testing.T) {
+	initTestLogger(t)
+
+	var runs int32
+	escalated := make(chan error, 1)
+	policy := PolicyMax(2, time.Minute)
+	policy.Backoff = time.Millisecond
+	policy.OnEscalate = func(name string, err error) {
+		escalated <- err
+	}
+	id := Go("always-panic", func() {
+		atomic.AddInt32(&runs, 1)
+		panic("bad message")
+	}, policy)
+
+	select {
+	case err := <-escalated:
+		if !strings.Contains(err.Error(), "bad message") {
+			t.Fatalf("unexpected escalate err: %s", err.Error())
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("expect escalate")
+	}
+	if 3 != atomic.LoadInt32(&runs) {
+		t.Fatalf("expect 1 run and 2 restarts, got %d", runs)
+	}
+
+	var info *RoutineInfo
+	for _, r := range Routines() {
+		if id == r.Id {
+			v := r
+			info = &v
+		}
+	}
+	if nil == info || RoutineFailed != info.State || 2 != info.Restarts || "bad message" != info.LastPanic {
+		t.Fatalf("unexpected routine info: %+v", info)
+	}
+
+	//正常返回后不再列出
+	done := make(chan struct{})
+	id = Go("once", func() {}, PolicyAlways())
+	go func() {
+		for {
+			found := false
+			for _, r := range Routines() {
+				found = found || id == r.Id
+			}
+			if !found {
+				close(done)
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("expect exited routine removed")
+	}
+}
+
+// Test_SupervisorBackoffReset 稳定运行超过 Window 后退避时间重置
+func Test_SupervisorBackoffReset(t *testing.T) {
+	initTestLogger(t)
+
+	policy := PolicyAlways()
+	policy.Window = 30 * time.Millisecond
+	policy.Backoff = 20 * time.Millisecond
+	policy.MaxBackoff = time.Second
+
+	var (
+		runs     int32
+		panicked time.Time
+	)
+	gap := make(chan time.Duration, 1)
+	Go("backoff-reset", func() {
+		switch atomic.AddInt32(&runs, 1) {
+		case 1, 2:
+			panic("fast")
+		case 3:
+			//第三次稳定运行超过 Window，未重置时需要等待 80ms
+			time.Sleep(60 * time.Millisecond)
+			panicked = time.Now()
+			panic("stable")
+		default:
+			gap <- time.Since(panicked)
+		}
+	}, policy)
+
+	select {
+	case d := <-gap:
+		if d >= 60*time.Millisecond {
+			t.Fatalf("expect backoff reset, restarted after %s", d)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("expect restart")
+	}
+}
+
+// Test_SupervisorRetire 不再重启的协程移出 routines，只保留最近的失败记录
+func Test_SupervisorRetire(t *testing.T) {
+	initTestLogger(t)
+
+	var stopped int32
+	policy := PolicyNever()
+	policy.OnEscalate = func(name string, err error) {
+		atomic.AddInt32(&stopped, 1)
+	}
+	n := maxFailedRoutines + 8
+	for i := 0; i < n; i++ {
+		Go("never", func() {
+			panic("once")
+		}, policy)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for int32(n) != atomic.LoadInt32(&stopped) {
+		if time.Now().After(deadline) {
+			t.Fatal("expect all routines stopped")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	routinesMtx.RLock()
+	defer routinesMtx.RUnlock()
+	for _, r := range routines {
+		if "never" == r.info.Name {
+			t.Fatalf("expect failed routine removed: %+v", r.info)
+		}
+	}
+	if maxFailedRoutines != len(failed) {
+		t.Fatalf("expect %d failed routines kept, got %d", maxFailedRoutines, len(failed))
+	}
+}
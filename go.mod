@@ -1,6 +1,6 @@
 module github.com/0DeOrg/gutils
 
-go 1.17
+go 1.18
 
 require (
 	github.com/apache/rocketmq-client-go/v2 v2.1.1
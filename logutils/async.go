@@ -0,0 +1,249 @@
+package logutils
+
+/**
+ * @Author: lee
+ * @Description: 异步缓冲写入，日志先进入有界队列，由后台协程批量写出并定时刷新
+ * @File: async
+ * @Date: 2026-10-20 11:10 上午
+ */
+
+import (
+	"bufio"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	OverflowBlock = "block" //队列满时阻塞等待
+	OverflowDrop  = "drop"  //队列满时丢弃并计数
+)
+
+const (
+	defaultAsyncBufferSize    = 8192
+	defaultAsyncFlushInterval = time.Second
+	defaultAsyncWriteBuffer   = 256 << 10
+)
+
+type AsyncConfig struct {
+	BufferSize    int           `json:"buffer-size"     yaml:"buffer-size"         mapstructure:"buffer-size"`    //队列容量，单位条，默认8192
+	Overflow      string        `json:"overflow"     yaml:"overflow"               mapstructure:"overflow"`       //队列满时的策略 block/drop，默认block
+	FlushInterval time.Duration `json:"flush-interval"     yaml:"flush-interval"   mapstructure:"flush-interval"` //定时刷新间隔，默认1s
+	WriteBuffer   int           `json:"write-buffer"     yaml:"write-buffer"       mapstructure:"write-buffer"`   //写出缓冲大小，单位字节，默认256KB
+}
+
+type AsyncWriter struct {
+	out      zapcore.WriteSyncer
+	buf      *bufio.Writer
+	queue    chan []byte
+	syncReq  chan chan error
+	drop     bool
+	interval time.Duration
+	dropped  uint64
+	closed   int32
+	pending  int32 //已通过关闭检查、尚未入队的写入
+	closeMtx sync.RWMutex
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+var (
+	asyncWriters   = map[*AsyncWriter]struct{}{}
+	asyncWritersMu sync.Mutex
+
+	errorAsyncClosed = fmt.Errorf("async writer closed")
+)
+
+// NewAsyncWriter
+/* @Description: 创建异步写入器，Sync 时将队列中的日志全部写出
+ * @param out zapcore.WriteSyncer 实际输出
+ * @param cfg AsyncConfig
+ * @return *AsyncWriter
+ */
+func NewAsyncWriter(out zapcore.WriteSyncer, cfg AsyncConfig) *AsyncWriter {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultAsyncBufferSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultAsyncFlushInterval
+	}
+	if cfg.WriteBuffer <= 0 {
+		cfg.WriteBuffer = defaultAsyncWriteBuffer
+	}
+
+	ret := &AsyncWriter{
+		out:      out,
+		buf:      bufio.NewWriterSize(out, cfg.WriteBuffer),
+		queue:    make(chan []byte, cfg.BufferSize),
+		syncReq:  make(chan chan error),
+		drop:     OverflowDrop == cfg.Overflow,
+		interval: cfg.FlushInterval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go ret.goWrite()
+
+	asyncWritersMu.Lock()
+	asyncWriters[ret] = struct{}{}
+	asyncWritersMu.Unlock()
+
+	return ret
+}
+
+// Write zap 会复用传入的内存，需要拷贝后入队
+// 队列满时阻塞不持有锁，Close 不会被阻塞的写入卡住，关闭后后台协程写完所有 pending 的写入才退出
+// 关闭后实际输出通常也已关闭，之后的写入丢弃并计数
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	w.closeMtx.RLock()
+	if 1 == atomic.LoadInt32(&w.closed) {
+		w.closeMtx.RUnlock()
+		atomic.AddUint64(&w.dropped, 1)
+		return 0, errorAsyncClosed
+	}
+	atomic.AddInt32(&w.pending, 1)
+	w.closeMtx.RUnlock()
+	defer atomic.AddInt32(&w.pending, -1)
+
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	if w.drop {
+		select {
+		case w.queue <- data:
+		default:
+			atomic.AddUint64(&w.dropped, 1)
+		}
+		return len(p), nil
+	}
+
+	w.queue <- data
+	return len(p), nil
+}
+
+// Sync
+/* @Description: 等待队列中已有的日志写出并刷新到实际输出
+ * @return error
+ */
+func (w *AsyncWriter) Sync() error {
+	if 1 == atomic.LoadInt32(&w.closed) {
+		return w.out.Sync()
+	}
+
+	ch := make(chan error, 1)
+	select {
+	case w.syncReq <- ch:
+		return <-ch
+	case <-w.done:
+		return w.out.Sync()
+	}
+}
+
+// Dropped
+/* @Description: drop 策略下因队列满丢弃及关闭后丢弃的日志数量
+ * @return uint64
+ */
+func (w *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// Close
+/* @Description: 写出剩余日志并停止后台协程，之后的写入返回错误并丢弃
+ * @return error
+ */
+func (w *AsyncWriter) Close() error {
+	err := w.Sync()
+
+	w.closeMtx.Lock()
+	if atomic.CompareAndSwapInt32(&w.closed, 0, 1) {
+		close(w.stop)
+	}
+	w.closeMtx.Unlock()
+	<-w.done
+
+	asyncWritersMu.Lock()
+	delete(asyncWriters, w)
+	asyncWritersMu.Unlock()
+
+	return err
+}
+
+func (w *AsyncWriter) goWrite() {
+	defer close(w.done)
+	tick := time.NewTicker(w.interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			w.drain()
+			return
+		case data := <-w.queue:
+			_, _ = w.buf.Write(data)
+		case <-tick.C:
+			_ = w.flush()
+		case ch := <-w.syncReq:
+			//先写完 Sync 之前入队的日志
+			for n := len(w.queue); n > 0; n-- {
+				_, _ = w.buf.Write(<-w.queue)
+			}
+			ch <- w.flush()
+		}
+	}
+}
+
+// drain 关闭后写出队列及仍在等待入队的日志
+func (w *AsyncWriter) drain() {
+	for {
+		select {
+		case data := <-w.queue:
+			_, _ = w.buf.Write(data)
+		case <-time.After(time.Millisecond):
+			if 0 == atomic.LoadInt32(&w.pending) && 0 == len(w.queue) {
+				_ = w.flush()
+				return
+			}
+		}
+	}
+}
+
+func (w *AsyncWriter) flush() error {
+	if err := w.buf.Flush(); nil != err {
+		return err
+	}
+	return w.out.Sync()
+}
+
+// syncAsyncWriters 写出所有异步写入器队列中的日志
+func syncAsyncWriters() error {
+	asyncWritersMu.Lock()
+	writers := make([]*AsyncWriter, 0, len(asyncWriters))
+	for w := range asyncWriters {
+		writers = append(writers, w)
+	}
+	asyncWritersMu.Unlock()
+
+	var ret error
+	for _, w := range writers {
+		if err := w.Sync(); nil != err && nil == ret {
+			ret = err
+		}
+	}
+	return ret
+}
+
+// AsyncDropped
+/* @Description: 所有异步写入器丢弃的日志数量
+ * @return uint64
+ */
+func AsyncDropped() uint64 {
+	asyncWritersMu.Lock()
+	defer asyncWritersMu.Unlock()
+
+	var ret uint64
+	for w := range asyncWriters {
+		ret += w.Dropped()
+	}
+	return ret
+}
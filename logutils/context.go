@@ -0,0 +1,164 @@
+package logutils
+
+/**
+ * @Author: lee
+ * @Description: 上下文日志，携带 request_id/trace_id/user_id/symbol 等字段
+ * @File: context
+ * @Date: 2026-10-19 7:20 下午
+ */
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"go.uber.org/zap"
+)
+
+const (
+	FieldRequestID = "request_id"
+	FieldTraceID   = "trace_id"
+	FieldUserID    = "user_id"
+	FieldSymbol    = "symbol"
+)
+
+const (
+	HeaderRequestID = "X-Request-ID"
+	HeaderTraceID   = "X-Trace-ID"
+	HeaderUserID    = "X-User-ID"
+	HeaderSymbol    = "X-Symbol"
+)
+
+// ContextHeaders 上下文字段与http头的对应关系，用于跨服务传递
+var ContextHeaders = map[string]string{
+	FieldRequestID: HeaderRequestID,
+	FieldTraceID:   HeaderTraceID,
+	FieldUserID:    HeaderUserID,
+	FieldSymbol:    HeaderSymbol,
+}
+
+type ctxKey struct{}
+
+type ctxValue struct {
+	logger ILogger
+	ids    map[string]string
+}
+
+// With
+/* @Description: 创建带固定字段的子日志
+ * @param fields ...zap.Field
+ * @return ILogger
+ */
+func With(fields ...zap.Field) ILogger {
+	return Logger().(*ZapLogModule).With(fields...)
+}
+
+// ToContext
+/* @Description: 将日志存入上下文
+ * @param ctx context.Context
+ * @param logger ILogger
+ * @return context.Context
+ */
+func ToContext(ctx context.Context, logger ILogger) context.Context {
+	value := &ctxValue{logger: logger}
+	if old := contextValue(ctx); nil != old {
+		value.ids = old.ids
+	}
+	return context.WithValue(unwrapContext(ctx), ctxKey{}, value)
+}
+
+// FromContext
+/* @Description: 从上下文取日志，没有时返回全局日志
+ * @param ctx context.Context
+ * @return ILogger
+ */
+func FromContext(ctx context.Context) ILogger {
+	if nil != ctx {
+		if value := contextValue(ctx); nil != value && nil != value.logger {
+			return value.logger
+		}
+	}
+	return Logger()
+}
+
+// WithContextID
+/* @Description: 在上下文中记录一个跟踪字段，上下文日志同时带上该字段
+ * @param ctx context.Context
+ * @param key string 如 FieldRequestID
+ * @param id string
+ * @return context.Context
+ */
+func WithContextID(ctx context.Context, key string, id string) context.Context {
+	ids := map[string]string{}
+	if old := contextValue(ctx); nil != old {
+		for k, v := range old.ids {
+			ids[k] = v
+		}
+	}
+	ids[key] = id
+
+	value := &ctxValue{ids: ids}
+	if IsInit() {
+		value.logger = FromContext(ctx)
+		if m, ok := value.logger.(*ZapLogModule); ok {
+			value.logger = m.With(zap.String(key, id))
+		}
+	}
+
+	return context.WithValue(unwrapContext(ctx), ctxKey{}, value)
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return WithContextID(ctx, FieldRequestID, id)
+}
+
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return WithContextID(ctx, FieldTraceID, id)
+}
+
+func WithUserID(ctx context.Context, id string) context.Context {
+	return WithContextID(ctx, FieldUserID, id)
+}
+
+func WithSymbol(ctx context.Context, symbol string) context.Context {
+	return WithContextID(ctx, FieldSymbol, symbol)
+}
+
+// ContextIDs
+/* @Description: 上下文中所有跟踪字段，用于传递到下游http请求和mq消息
+ * @param ctx context.Context
+ * @return map[string]string
+ */
+func ContextIDs(ctx context.Context) map[string]string {
+	ret := map[string]string{}
+	if nil == ctx {
+		return ret
+	}
+	if value := contextValue(ctx); nil != value {
+		for k, v := range value.ids {
+			ret[k] = v
+		}
+	}
+	return ret
+}
+
+func ContextID(ctx context.Context, key string) string {
+	if nil == ctx {
+		return ""
+	}
+	if value := contextValue(ctx); nil != value {
+		return value.ids[key]
+	}
+	return ""
+}
+
+// NewRequestID
+/* @Description: 生成随机请求id
+ * @return string
+ */
+func NewRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); nil != err {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
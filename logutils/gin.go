@@ -0,0 +1,69 @@
+package logutils
+
+/**
+ * @Author: lee
+ * @Description: gin中间件，生成或透传请求id并在请求上下文中存放带id的日志
+ * @File: gin
+ * @Date: 2026-10-19 7:20 下午
+ */
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// maxContextIDLen 请求头中跟踪字段的最大长度，超过时请求id重新生成，其他字段忽略
+const maxContextIDLen = 128
+
+// GinMiddleware
+/* @Description: 读取请求头中的跟踪字段，没有 X-Request-ID 或超长时生成，响应头回写 X-Request-ID
+ * 处理函数中通过 logutils.FromContext(c) 或 logutils.FromContext(c.Request.Context()) 获取日志
+ * @return gin.HandlerFunc
+ */
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		requestID := c.GetHeader(HeaderRequestID)
+		if "" == requestID || len(requestID) > maxContextIDLen {
+			requestID = NewRequestID()
+		}
+		ctx = WithRequestID(ctx, requestID)
+
+		for field, header := range ContextHeaders {
+			if FieldRequestID == field {
+				continue
+			}
+			if value := c.GetHeader(header); "" != value && len(value) <= maxContextIDLen {
+				ctx = WithContextID(ctx, field, value)
+			}
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(HeaderRequestID, requestID)
+		c.Next()
+	}
+}
+
+// unwrapContext gin.Context 的 Value 不会查找请求上下文，这里转为请求上下文
+func unwrapContext(ctx context.Context) context.Context {
+	if c, ok := ctx.(*gin.Context); ok && nil != c.Request {
+		return c.Request.Context()
+	}
+	return ctx
+}
+
+// contextValue 取上下文中的日志及跟踪字段，ctx 为 gin.Context 或由其派生时从请求上下文中查找
+func contextValue(ctx context.Context) *ctxValue {
+	ctx = unwrapContext(ctx)
+	if value, ok := ctx.Value(ctxKey{}).(*ctxValue); ok {
+		return value
+	}
+	//gin.Context 以 0 为键返回请求
+	if req, ok := ctx.Value(0).(*http.Request); ok && nil != req {
+		value, _ := req.Context().Value(ctxKey{}).(*ctxValue)
+		return value
+	}
+	return nil
+}
@@ -3,6 +3,11 @@ package logutils
 import (
 	"fmt"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 /**
@@ -13,8 +18,9 @@ import (
  */
 
 var (
-	loggerModule ILogger
-	logInit      = false
+	//全局日志 *ZapLogModule，每次输出都会读取，InitLogger、AddCore 持有 globalMtx 整体替换
+	loggerModule atomic.Value
+	globalMtx    sync.Mutex
 
 	errorNotInit = fmt.Errorf("log module not inited")
 )
@@ -27,69 +33,324 @@ type ILogger interface {
 	Fatal(msg string, fields ...zap.Field)
 	DPanic(msg string, fields ...zap.Field)
 	Panic(msg string, fields ...zap.Field)
+
+	Infof(format string, vals ...interface{})
+	Errorf(format string, vals ...interface{})
+	Warnf(format string, vals ...interface{})
+	Debugf(format string, vals ...interface{})
+	Fatalf(format string, vals ...interface{})
+	DPanicf(format string, vals ...interface{})
+	Panicf(format string, vals ...interface{})
+
+	Infow(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+	Debugw(msg string, keysAndValues ...interface{})
+	Fatalw(msg string, keysAndValues ...interface{})
+	DPanicw(msg string, keysAndValues ...interface{})
+	Panicw(msg string, keysAndValues ...interface{})
 }
 
+// InitLogger
+/* @Description: 初始化日志，同时创建 Loggers 中的命名日志，传入 *ZapConfig 时绑定该配置，
+ * 配合 BindLevel 注册的热更新钩子或 ReloadLevel 使日志级别生效
+ * @param config interface{} ZapConfig 或 *ZapConfig
+ */
 func InitLogger(config interface{}) {
-	var err error
+	if v, ok := config.(*ZapConfig); ok && nil != v {
+		InitLogger(*v)
+		setBoundLevel(v)
+		return
+	}
+
 	if v, ok := config.(ZapConfig); ok {
-		loggerModule, err = newZapLogModule(v)
+		module, err := newZapLogModule(v)
 		if nil != err {
 			panic(fmt.Errorf("zap log init fault, err: %s", err.Error()))
 		}
+		globalMtx.Lock()
+		old := globalModule()
+		//包级函数多一层调用
+		loggerModule.Store(module.WithCallerSkip(1))
+		zapConfig = v
+		globalMtx.Unlock()
+
+		//重新初始化时关闭原来的文件及远程输出
+		if nil != old {
+			_ = old.Close()
+		}
+
+		if err = initNamedLoggers(v); nil != err {
+			panic(fmt.Errorf("zap log init fault, err: %s", err.Error()))
+		}
 
-		logInit = true
+		RedirectStdLog()
 	}
 }
 
-func Logger() ILogger {
-	return loggerModule.(*ZapLogModule).logger
+// IsInit
+/* @Description: 日志是否已初始化，配置加载阶段的库可据此决定是否接入日志
+ * @return bool
+ */
+func IsInit() bool {
+	return nil != globalModule()
 }
 
-func Info(msg string, fields ...zap.Field) {
-	if !logInit {
+// globalModule 全局日志，未初始化时返回 nil
+func globalModule() *ZapLogModule {
+	module, _ := loggerModule.Load().(*ZapLogModule)
+	return module
+}
+
+// mustModule 全局日志，未初始化时 panic
+func mustModule() *ZapLogModule {
+	module := globalModule()
+	if nil == module {
 		panic(errorNotInit)
 	}
-	loggerModule.Info(msg, fields...)
+	return module
 }
 
-func Warn(msg string, fields ...zap.Field) {
-	if !logInit {
-		panic(errorNotInit)
+// Sync
+/* @Description: 写出全局日志、命名日志、所有异步写入器及远程输出缓冲中的日志，等待远程输出发送完成，
+ * 远程输出不可用时可能长时间阻塞，进程退出路径使用 SyncTimeout
+ * @return error
+ */
+func Sync() error {
+	var ret error
+	if module := globalModule(); nil != module {
+		if err := module.Sync(); nil != err {
+			ret = err
+		}
+	}
+
+	namedMtx.RLock()
+	modules := make([]*ZapLogModule, 0, len(namedLoggers))
+	for _, module := range namedLoggers {
+		modules = append(modules, module)
+	}
+	namedMtx.RUnlock()
+	for _, module := range modules {
+		if err := module.Sync(); nil != err && nil == ret {
+			ret = err
+		}
+	}
+
+	if err := syncAsyncWriters(); nil != err && nil == ret {
+		ret = err
+	}
+
+	if err := syncSinks(); nil != err && nil == ret {
+		ret = err
 	}
-	loggerModule.Warn(msg, fields...)
+
+	return ret
 }
 
-func Error(msg string, fields ...zap.Field) {
-	if !logInit {
-		panic(errorNotInit)
+// SyncTimeout
+/* @Description: 先写出本地异步队列，再执行 Sync，最多等待 timeout，超时后远程输出在后台继续发送，
+ * Fatal、退出钩子执行完成及强制退出时使用
+ * @param timeout time.Duration <=0 时只写出本地异步队列
+ * @return error
+ */
+func SyncTimeout(timeout time.Duration) error {
+	ret := syncAsyncWriters()
+	if timeout <= 0 {
+		return ret
 	}
-	loggerModule.Error(msg, fields...)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Sync()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if nil == ret {
+			ret = err
+		}
+	case <-timer.C:
+		if nil == ret {
+			ret = fmt.Errorf("log sync timeout after %s", timeout)
+		}
+	}
+	return ret
 }
 
-func Debug(msg string, fields ...zap.Field) {
-	if !logInit {
-		panic(errorNotInit)
+// AddCore
+/* @Description: 全局日志增加一个 zap core，与原有输出同时写入，之后通过包级函数、Logger()、未配置的 Named 获取的日志
+ * 及标准库 log 生效；之前获取的 Logger() 及 Loggers 中配置的命名日志不受影响
+ * @param core zapcore.Core
+ * @return error
+ */
+func AddCore(core zapcore.Core) error {
+	return addCore("", core, nil)
+}
+
+// ReplaceCore
+/* @Description: 同 AddCore，替换之前以同一名称添加的 core 并关闭其 closer，可重复调用的组件使用，如 dingutils.EnableAlertCore
+ * @param name string
+ * @param core zapcore.Core
+ * @param closer io.Closer 可为 nil，随全局日志一起关闭
+ * @return error
+ */
+func ReplaceCore(name string, core zapcore.Core, closer io.Closer) error {
+	if "" == name {
+		return fmt.Errorf("ReplaceCore name is empty")
 	}
-	loggerModule.Debug(msg, fields...)
+	return addCore(name, core, closer)
 }
 
-func Fatal(msg string, fields ...zap.Field) {
-	if !logInit {
-		panic(errorNotInit)
+// extraCore AddCore 添加的 core，name 非空时可被同名替换
+type extraCore struct {
+	name   string
+	core   zapcore.Core
+	closer io.Closer
+}
+
+// addCore 在初始化时的日志上重新组合所有添加的 core，closer 非空时随全局日志一起关闭
+func addCore(name string, core zapcore.Core, closer io.Closer) error {
+	globalMtx.Lock()
+	module := globalModule()
+	if nil == module {
+		globalMtx.Unlock()
+		return errorNotInit
+	}
+
+	base := module.base
+	if nil == base {
+		base = module
+	}
+	var replaced io.Closer
+	extras := make([]extraCore, 0, len(module.extras)+1)
+	for _, extra := range module.extras {
+		if "" != name && name == extra.name {
+			replaced = extra.closer
+			continue
+		}
+		extras = append(extras, extra)
+	}
+	extras = append(extras, extraCore{name: name, core: core, closer: closer})
+
+	cores := make([]zapcore.Core, 0, len(extras)+1)
+	closers := append(make([]io.Closer, 0, len(base.closers)+len(extras)), base.closers...)
+	for _, extra := range extras {
+		cores = append(cores, extra.core)
+		if nil != extra.closer {
+			closers = append(closers, extra.closer)
+		}
 	}
-	loggerModule.Fatal(msg, fields...)
+	logger := base.logger.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(append([]zapcore.Core{c}, cores...)...)
+	}))
+	loggerModule.Store(&ZapLogModule{
+		logger:  logger,
+		sugar:   logger.Sugar(),
+		config:  module.config,
+		level:   module.level,
+		closers: closers,
+		base:    base,
+		extras:  extras,
+	})
+	globalMtx.Unlock()
+
+	refreshStdLog()
+	if nil != replaced {
+		_ = replaced.Close()
+	}
+	return nil
+}
+
+// Logger
+/* @Description: 返回可直接调用的日志模块，行号指向调用处
+ * @return ILogger
+ */
+func Logger() ILogger {
+	return mustModule().WithCallerSkip(-1)
+}
+
+func Info(msg string, fields ...zap.Field) {
+	mustModule().Info(msg, fields...)
+}
+
+func Warn(msg string, fields ...zap.Field) {
+	mustModule().Warn(msg, fields...)
+}
+
+func Error(msg string, fields ...zap.Field) {
+	mustModule().Error(msg, fields...)
+}
+
+func Debug(msg string, fields ...zap.Field) {
+	mustModule().Debug(msg, fields...)
+}
+
+func Fatal(msg string, fields ...zap.Field) {
+	mustModule().Fatal(msg, fields...)
 }
 
 func DPanic(msg string, fields ...zap.Field) {
-	if !logInit {
-		panic(errorNotInit)
-	}
-	loggerModule.DPanic(msg, fields...)
+	mustModule().DPanic(msg, fields...)
 }
 
 func Panic(msg string, fields ...zap.Field) {
-	if !logInit {
-		panic(errorNotInit)
-	}
-	loggerModule.Panic(msg, fields...)
+	mustModule().Panic(msg, fields...)
+}
+
+func Infof(format string, vals ...interface{}) {
+	mustModule().Infof(format, vals...)
+}
+
+func Warnf(format string, vals ...interface{}) {
+	mustModule().Warnf(format, vals...)
+}
+
+func Errorf(format string, vals ...interface{}) {
+	mustModule().Errorf(format, vals...)
+}
+
+func Debugf(format string, vals ...interface{}) {
+	mustModule().Debugf(format, vals...)
+}
+
+func Fatalf(format string, vals ...interface{}) {
+	mustModule().Fatalf(format, vals...)
+}
+
+func DPanicf(format string, vals ...interface{}) {
+	mustModule().DPanicf(format, vals...)
+}
+
+func Panicf(format string, vals ...interface{}) {
+	mustModule().Panicf(format, vals...)
+}
+
+func Infow(msg string, keysAndValues ...interface{}) {
+	mustModule().Infow(msg, keysAndValues...)
+}
+
+func Warnw(msg string, keysAndValues ...interface{}) {
+	mustModule().Warnw(msg, keysAndValues...)
+}
+
+func Errorw(msg string, keysAndValues ...interface{}) {
+	mustModule().Errorw(msg, keysAndValues...)
+}
+
+func Debugw(msg string, keysAndValues ...interface{}) {
+	mustModule().Debugw(msg, keysAndValues...)
+}
+
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	mustModule().Fatalw(msg, keysAndValues...)
+}
+
+func DPanicw(msg string, keysAndValues ...interface{}) {
+	mustModule().DPanicw(msg, keysAndValues...)
+}
+
+func Panicw(msg string, keysAndValues ...interface{}) {
+	mustModule().Panicw(msg, keysAndValues...)
 }
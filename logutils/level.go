@@ -0,0 +1,107 @@
+package logutils
+
+/**
+ * @Author: lee
+ * @Description: 运行时调整日志级别
+ * @File: level
+ * @Date: 2026-10-19 6:50 下午
+ */
+
+import (
+	"go.uber.org/zap"
+	"net/http"
+	"sync"
+)
+
+var (
+	boundLevelMtx  sync.RWMutex
+	boundLevelCfg  *ZapConfig
+	boundLevelOnce sync.Once
+)
+
+// SetLevel
+/* @Description: 运行时修改日志级别
+ * @param lvl string debug/info/warn/error/dpanic/panic/fatal
+ * @return error
+ */
+func SetLevel(lvl string) error {
+	module := globalModule()
+	if nil == module {
+		return errorNotInit
+	}
+	return module.SetLevel(lvl)
+}
+
+// GetLevel
+/* @Description: 当前日志级别
+ * @return string
+ */
+func GetLevel() string {
+	module := globalModule()
+	if nil == module {
+		return ""
+	}
+	return module.Level().String()
+}
+
+// LevelHandler
+/* @Description: 查看/修改日志级别的http接口，可挂载到gin(gin.WrapH)或pprof的mux上
+ * GET 返回 {"level":"info"}，PUT 传入 {"level":"debug"}
+ * @return http.Handler
+ */
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		module := globalModule()
+		if nil == module {
+			http.Error(w, errorNotInit.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		module.LevelHandler().ServeHTTP(w, r)
+	})
+}
+
+// BindLevel
+/* @Description: 绑定配置，配置热更新(配置文件、Apollo、Nacos)后 zap-level 变化时自动修改日志级别
+ * 重复调用只替换绑定的配置，钩子只注册一次
+ * @param cfg *ZapConfig 热更新反序列化的目标结构体中的日志配置
+ * @param register func(fn func()) 配置更新钩子的注册函数，如 gutils.RegisterConfigChangeHook
+ */
+func BindLevel(cfg *ZapConfig, register func(fn func())) {
+	setBoundLevel(cfg)
+	boundLevelOnce.Do(func() {
+		register(ReloadLevel)
+	})
+}
+
+// ReloadLevel
+/* @Description: 按绑定的配置更新全局及命名日志的级别，BindLevel 注册的钩子调用，也可在配置更新后手动调用
+ */
+func ReloadLevel() {
+	boundLevelMtx.RLock()
+	cfg := boundLevelCfg
+	boundLevelMtx.RUnlock()
+	module := globalModule()
+	if nil == module || nil == cfg || "" == cfg.ZapLevel {
+		return
+	}
+	defer bindNamedLevel(cfg)
+
+	current := GetLevel()
+	lvl, err := ParseLevel(cfg.ZapLevel)
+	if nil != err {
+		Warn("BindLevel invalid zap-level", zap.String("zap-level", cfg.ZapLevel))
+		return
+	}
+	if lvl.String() == current {
+		return
+	}
+
+	module.level.SetLevel(lvl)
+	Warn("log level changed", zap.String("from", current), zap.String("to", lvl.String()))
+}
+
+func setBoundLevel(cfg *ZapConfig) {
+	boundLevelMtx.Lock()
+	defer boundLevelMtx.Unlock()
+	boundLevelCfg = cfg
+}
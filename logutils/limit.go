@@ -0,0 +1,325 @@
+package logutils
+
+/**
+ * @Author: lee
+ * @Description: 按key限流的日志，重复的错误在周期内只输出一次，之后汇总输出被抑制的数量
+ * @File: limit
+ * @Date: 2026-10-20 10:20 上午
+ */
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sync"
+	"time"
+)
+
+// DefaultLimitInterval Limited 使用的默认周期
+var DefaultLimitInterval = 10 * time.Second
+
+const limitFlushTick = time.Second
+
+type limitEntry struct {
+	module     *ZapLogModule
+	level      zapcore.Level
+	msg        string
+	interval   time.Duration
+	last       time.Time //上次输出时间
+	suppressed int64
+}
+
+var (
+	limitEntries = map[string]*limitEntry{}
+	//包级函数创建的限流日志，每个key一个，全局日志替换后重新创建
+	limitLoggers = map[string]*limitedLogger{}
+	limitMtx     sync.Mutex
+	limitOnce    sync.Once
+)
+
+type limitedLogger struct {
+	module   *ZapLogModule
+	base     *ZapLogModule //创建时的全局日志
+	key      string
+	interval time.Duration
+}
+
+var _ ILogger = (*limitedLogger)(nil)
+
+// Limited
+/* @Description: 按key限流的全局日志，同一个key在 DefaultLimitInterval 内只输出一次
+ * 如 logutils.Limited("ws:recv:" + url).Warn("doReceiveThread fatal", zap.Error(err))
+ * @param key string
+ * @return ILogger
+ */
+func Limited(key string) ILogger {
+	return LimitedEvery(key, DefaultLimitInterval)
+}
+
+// LimitedEvery
+/* @Description: 同 Limited，指定周期
+ * @param key string
+ * @param interval time.Duration
+ * @return ILogger
+ */
+func LimitedEvery(key string, interval time.Duration) ILogger {
+	base := mustModule()
+	if interval <= 0 {
+		interval = DefaultLimitInterval
+	}
+
+	limitMtx.Lock()
+	defer limitMtx.Unlock()
+	if l, ok := limitLoggers[key]; ok && l.base == base && l.interval == interval {
+		return l
+	}
+
+	l := base.WithCallerSkip(-1).Limited(key, interval).(*limitedLogger)
+	l.base = base
+	limitLoggers[key] = l
+	return l
+}
+
+// Limited
+/* @Description: 按key限流的子日志，周期内重复的日志被抑制，周期结束后输出一条带 suppressed 数量的汇总
+ * @param key string
+ * @param interval time.Duration
+ * @return ILogger
+ */
+func (m *ZapLogModule) Limited(key string, interval time.Duration) ILogger {
+	if interval <= 0 {
+		interval = DefaultLimitInterval
+	}
+	limitOnce.Do(func() {
+		go goFlushLimited()
+	})
+
+	return &limitedLogger{
+		//limitedLogger 的方法多一层调用
+		module:   m.WithCallerSkip(1),
+		key:      key,
+		interval: interval,
+	}
+}
+
+// FlushLimited
+/* @Description: 立即输出所有被抑制日志的汇总，退出前调用
+ */
+func FlushLimited() {
+	flushLimited(true)
+}
+
+func goFlushLimited() {
+	tick := time.NewTicker(limitFlushTick)
+	defer tick.Stop()
+	for range tick.C {
+		flushLimited(false)
+	}
+}
+
+func flushLimited(force bool) {
+	now := time.Now()
+	type summary struct {
+		key   string
+		entry limitEntry
+	}
+	summaries := make([]summary, 0)
+
+	limitMtx.Lock()
+	for key, e := range limitEntries {
+		elapsed := now.Sub(e.last)
+		if e.suppressed > 0 && (force || elapsed >= e.interval) {
+			summaries = append(summaries, summary{key: key, entry: *e})
+			e.suppressed = 0
+			e.last = now
+			continue
+		}
+		//长时间没有再出现的key清理掉
+		if 0 == e.suppressed && elapsed > 10*e.interval {
+			delete(limitEntries, key)
+			delete(limitLoggers, key)
+		}
+	}
+	limitMtx.Unlock()
+
+	for _, s := range summaries {
+		logAt(s.entry.module, s.entry.level, s.entry.msg,
+			zap.String("limit_key", s.key),
+			zap.Int64("suppressed", s.entry.suppressed),
+			zap.Duration("interval", s.entry.interval))
+	}
+}
+
+// allow 周期内第一次出现时输出，并带上上个周期被抑制的数量
+func (l *limitedLogger) allow(level zapcore.Level, msg string) (bool, []zap.Field) {
+	now := time.Now()
+	limitMtx.Lock()
+	defer limitMtx.Unlock()
+
+	e, ok := limitEntries[l.key]
+	if !ok {
+		limitEntries[l.key] = &limitEntry{
+			module:   l.module,
+			level:    level,
+			msg:      msg,
+			interval: l.interval,
+			last:     now,
+		}
+		return true, nil
+	}
+
+	e.module = l.module
+	e.level = level
+	e.msg = msg
+	e.interval = l.interval
+	if now.Sub(e.last) < e.interval {
+		e.suppressed++
+		return false, nil
+	}
+
+	e.last = now
+	if 0 == e.suppressed {
+		return true, nil
+	}
+	suppressed := e.suppressed
+	e.suppressed = 0
+	return true, []zap.Field{zap.Int64("suppressed", suppressed)}
+}
+
+func (l *limitedLogger) log(level zapcore.Level, msg string, fields []zap.Field) {
+	ok, extra := l.allow(level, msg)
+	if !ok {
+		return
+	}
+	fields = append(fields, extra...)
+	//直接调用zap，保证与 logw 的调用层级一致
+	switch level {
+	case zap.DebugLevel:
+		l.module.logger.Debug(msg, fields...)
+	case zap.WarnLevel:
+		l.module.logger.Warn(msg, fields...)
+	case zap.ErrorLevel:
+		l.module.logger.Error(msg, fields...)
+	default:
+		l.module.logger.Info(msg, fields...)
+	}
+}
+
+func (l *limitedLogger) logw(level zapcore.Level, msg string, keysAndValues []interface{}) {
+	ok, extra := l.allow(level, msg)
+	if !ok {
+		return
+	}
+	for _, f := range extra {
+		keysAndValues = append(keysAndValues, f)
+	}
+	switch level {
+	case zap.DebugLevel:
+		l.module.sugar.Debugw(msg, keysAndValues...)
+	case zap.WarnLevel:
+		l.module.sugar.Warnw(msg, keysAndValues...)
+	case zap.ErrorLevel:
+		l.module.sugar.Errorw(msg, keysAndValues...)
+	default:
+		l.module.sugar.Infow(msg, keysAndValues...)
+	}
+}
+
+func logAt(m *ZapLogModule, level zapcore.Level, msg string, fields ...zap.Field) {
+	switch level {
+	case zap.DebugLevel:
+		m.logger.Debug(msg, fields...)
+	case zap.WarnLevel:
+		m.logger.Warn(msg, fields...)
+	case zap.ErrorLevel:
+		m.logger.Error(msg, fields...)
+	default:
+		m.logger.Info(msg, fields...)
+	}
+}
+
+func (l *limitedLogger) Info(msg string, fields ...zap.Field) {
+	l.log(zap.InfoLevel, msg, fields)
+}
+
+func (l *limitedLogger) Error(msg string, fields ...zap.Field) {
+	l.log(zap.ErrorLevel, msg, fields)
+}
+
+func (l *limitedLogger) Warn(msg string, fields ...zap.Field) {
+	l.log(zap.WarnLevel, msg, fields)
+}
+
+func (l *limitedLogger) Debug(msg string, fields ...zap.Field) {
+	l.log(zap.DebugLevel, msg, fields)
+}
+
+// Fatal/DPanic/Panic 不限流
+
+func (l *limitedLogger) Fatal(msg string, fields ...zap.Field) {
+	l.module.Fatal(msg, fields...)
+}
+
+func (l *limitedLogger) DPanic(msg string, fields ...zap.Field) {
+	l.module.DPanic(msg, fields...)
+}
+
+func (l *limitedLogger) Panic(msg string, fields ...zap.Field) {
+	l.module.Panic(msg, fields...)
+}
+
+func (l *limitedLogger) Infof(format string, vals ...interface{}) {
+	l.log(zap.InfoLevel, fmt.Sprintf(format, vals...), nil)
+}
+
+func (l *limitedLogger) Errorf(format string, vals ...interface{}) {
+	l.log(zap.ErrorLevel, fmt.Sprintf(format, vals...), nil)
+}
+
+func (l *limitedLogger) Warnf(format string, vals ...interface{}) {
+	l.log(zap.WarnLevel, fmt.Sprintf(format, vals...), nil)
+}
+
+func (l *limitedLogger) Debugf(format string, vals ...interface{}) {
+	l.log(zap.DebugLevel, fmt.Sprintf(format, vals...), nil)
+}
+
+func (l *limitedLogger) Fatalf(format string, vals ...interface{}) {
+	l.module.Fatalf(format, vals...)
+}
+
+func (l *limitedLogger) DPanicf(format string, vals ...interface{}) {
+	l.module.DPanicf(format, vals...)
+}
+
+func (l *limitedLogger) Panicf(format string, vals ...interface{}) {
+	l.module.Panicf(format, vals...)
+}
+
+func (l *limitedLogger) Infow(msg string, keysAndValues ...interface{}) {
+	l.logw(zap.InfoLevel, msg, keysAndValues)
+}
+
+func (l *limitedLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.logw(zap.ErrorLevel, msg, keysAndValues)
+}
+
+func (l *limitedLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	l.logw(zap.WarnLevel, msg, keysAndValues)
+}
+
+func (l *limitedLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	l.logw(zap.DebugLevel, msg, keysAndValues)
+}
+
+func (l *limitedLogger) Fatalw(msg string, keysAndValues ...interface{}) {
+	l.module.Fatalw(msg, keysAndValues...)
+}
+
+func (l *limitedLogger) DPanicw(msg string, keysAndValues ...interface{}) {
+	l.module.DPanicw(msg, keysAndValues...)
+}
+
+func (l *limitedLogger) Panicw(msg string, keysAndValues ...interface{}) {
+	l.module.Panicw(msg, keysAndValues...)
+}
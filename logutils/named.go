@@ -0,0 +1,231 @@
+package logutils
+
+/**
+ * @Author: lee
+ * @Description: 命名日志，不同业务/模块写入各自的文件
+ * @File: named
+ * @Date: 2026-10-19 8:10 下午
+ */
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"sort"
+	"sync"
+)
+
+// 第三方库日志使用的名称，在 ZapConfig.Loggers 中配置同名日志即可输出到单独的文件
+const (
+	ModuleRaft     = "raft"
+	ModuleGorm     = "gorm"
+	ModuleRocketMQ = "rocketmq"
+	ModuleNacos    = "nacos"
+	ModuleSignalR  = "signalr"
+	ModuleViper    = "viper"
+)
+
+var (
+	namedLoggers = map[string]*ZapLogModule{}
+	namedMtx     sync.RWMutex
+
+	//日志初始化前第三方库的日志输出到标准错误
+	stderrModule = newStderrModule()
+)
+
+// RegisterLogger
+/* @Description: 按配置创建命名日志，同名的会被替换，被替换的日志写出后关闭
+ * @param name string
+ * @param config ZapConfig
+ * @return *ZapLogModule
+ * @return error
+ */
+func RegisterLogger(name string, config ZapConfig) (*ZapLogModule, error) {
+	if "" == name {
+		return nil, fmt.Errorf("RegisterLogger name is empty")
+	}
+
+	module, err := newZapLogModule(config)
+	if nil != err {
+		return nil, fmt.Errorf("RegisterLogger|%s err: %s", name, err.Error())
+	}
+	module = module.Named(name)
+
+	namedMtx.Lock()
+	old, ok := namedLoggers[name]
+	namedLoggers[name] = module
+	namedMtx.Unlock()
+
+	if ok {
+		_ = old.Close()
+	}
+
+	return module, nil
+}
+
+// Named
+/* @Description: 获取命名日志，未配置时返回写入全局日志、logger字段为 name 的子日志
+ * @param name string
+ * @return ILogger
+ */
+func Named(name string) ILogger {
+	return namedModule(name)
+}
+
+// NamedModule
+/* @Description: 获取命名日志，日志未初始化时返回输出到标准错误的日志，不会 panic
+ * 第三方库日志适配在每次输出时调用，日志初始化或命名日志替换后自动生效
+ * @param name string
+ * @return *ZapLogModule
+ */
+func NamedModule(name string) *ZapLogModule {
+	if !IsInit() {
+		return stderrModule.Named(name)
+	}
+	return namedModule(name)
+}
+
+// LoggerNames
+/* @Description: 已注册的命名日志
+ * @return []string
+ */
+func LoggerNames() []string {
+	namedMtx.RLock()
+	defer namedMtx.RUnlock()
+
+	ret := make([]string, 0, len(namedLoggers))
+	for name := range namedLoggers {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// SetNamedLevel
+/* @Description: 运行时修改命名日志的级别
+ * @param name string
+ * @param lvl string
+ * @return error
+ */
+func SetNamedLevel(name string, lvl string) error {
+	namedMtx.RLock()
+	module, ok := namedLoggers[name]
+	namedMtx.RUnlock()
+	if !ok {
+		return fmt.Errorf("logger %s not registered", name)
+	}
+	return module.SetLevel(lvl)
+}
+
+func namedModule(name string) *ZapLogModule {
+	namedMtx.RLock()
+	module, ok := namedLoggers[name]
+	namedMtx.RUnlock()
+	if ok {
+		return module
+	}
+
+	return mustModule().WithCallerSkip(-1).Named(name)
+}
+
+func newStderrModule() *ZapLogModule {
+	lvl := zap.NewAtomicLevelAt(zap.InfoLevel)
+	core := zapcore.NewCore(zapcore.NewConsoleEncoder(getEncoderConfig(ZapConfig{})), zapcore.Lock(os.Stderr), lvl)
+	//与 newZapLogger 一致，ZapLogModule 的方法多一层调用
+	logger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
+	return &ZapLogModule{
+		logger: logger,
+		sugar:  logger.Sugar(),
+		level:  lvl,
+	}
+}
+
+// initNamedLoggers 根据全局配置中的 Loggers 创建命名日志
+func initNamedLoggers(parent ZapConfig) error {
+	for name, config := range parent.Loggers {
+		if _, err := RegisterLogger(name, inheritZapConfig(parent, config, name)); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// inheritZapConfig 命名日志未配置的字段继承全局配置
+// 远程输出不继承，否则每个命名日志各自创建写入器，共用落盘目录且同一条日志重复发送
+func inheritZapConfig(parent ZapConfig, named NamedZapConfig, name string) ZapConfig {
+	config := named.ZapConfig
+	if "" == config.Directory {
+		config.Directory = parent.Directory
+	}
+	if "" == config.ZapLevel {
+		config.ZapLevel = parent.ZapLevel
+	}
+	if "" == config.Archive {
+		config.Archive = name
+	}
+	if "" == config.Format {
+		config.Format = parent.Format
+	}
+	if "" == config.LinkName {
+		config.LinkName = parent.LinkName
+	}
+	if "" == config.EncodeLevel {
+		config.EncodeLevel = parent.EncodeLevel
+	}
+	if 0 == config.RotationTime {
+		config.RotationTime = parent.RotationTime
+	}
+	if 0 == config.MaxSize {
+		config.MaxSize = parent.MaxSize
+	}
+	if 0 == config.MaxBackups {
+		config.MaxBackups = parent.MaxBackups
+	}
+	if 0 == config.MaxAge {
+		config.MaxAge = parent.MaxAge
+	}
+	if nil == config.Async {
+		config.Async = parent.Async
+	}
+	if nil == config.Sampling {
+		config.Sampling = parent.Sampling
+	}
+	if "" == config.ErrorArchive && "" != parent.ErrorArchive {
+		config.ErrorArchive = config.Archive + "-" + parent.ErrorArchive
+	}
+	config.ShowLine = inheritBool(named.ShowLine, parent.ShowLine)
+	config.LogInConsole = inheritBool(named.LogInConsole, parent.LogInConsole)
+	config.Compress = inheritBool(named.Compress, parent.Compress)
+	config.Loggers = nil
+	return config
+}
+
+func inheritBool(v *bool, parent bool) bool {
+	if nil == v {
+		return parent
+	}
+	return *v
+}
+
+// bindNamedLevel 配置热更新后同步命名日志的级别
+func bindNamedLevel(cfg *ZapConfig) {
+	for name, named := range cfg.Loggers {
+		config := inheritZapConfig(*cfg, named, name)
+		namedMtx.RLock()
+		module, ok := namedLoggers[name]
+		namedMtx.RUnlock()
+		if !ok {
+			continue
+		}
+
+		current := module.Level().String()
+		if err := module.SetLevel(config.ZapLevel); nil != err {
+			Warn("BindLevel invalid zap-level", zap.String("logger", name), zap.String("zap-level", config.ZapLevel))
+			continue
+		}
+		if to := module.Level().String(); to != current {
+			Warn("log level changed", zap.String("logger", name), zap.String("from", current), zap.String("to", to))
+		}
+	}
+}
@@ -0,0 +1,421 @@
+package logutils
+
+/**
+ * @Author: lee
+ * @Description: 日志文件切分，支持按时间、按大小切分，历史文件数量/时长保留及gzip压缩
+ * @File: rotate
+ * @Date: 2026-10-19 8:40 下午
+ */
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRotationTime = 24 * time.Hour
+	defaultMaxAge       = 7 * 24 * time.Hour
+	compressSuffix      = ".gz"
+	logSuffix           = ".log"
+)
+
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+type RotateConfig struct {
+	Directory    string
+	Archive      string        //文件名前缀，文件名为 Archive-时间[.序号].log
+	LinkName     string        //指向当前文件的软链接，空不创建
+	RotationTime time.Duration //按时间切分间隔，0 为24h
+	MaxSize      int64         //单个文件最大字节数，0 不按大小切分
+	MaxBackups   int           //保留的历史文件数量，0 不限制
+	MaxAge       time.Duration //历史文件保留时长，0 为7天，小于0不删除
+	Compress     bool          //历史文件gzip压缩
+	Clock        Clock         //nil 使用系统时间，测试时可替换
+}
+
+type RotateWriter struct {
+	cfg      RotateConfig
+	layout   string
+	file     *os.File
+	filename string
+	period   time.Time
+	size     int64
+	mtx      sync.Mutex
+	millMtx  sync.Mutex
+	wg       sync.WaitGroup
+}
+
+// NewRotateWriter
+/* @Description: 创建切分写入器，首次写入时打开文件
+ * @param cfg RotateConfig
+ * @return *RotateWriter
+ * @return error
+ */
+func NewRotateWriter(cfg RotateConfig) (*RotateWriter, error) {
+	if "" == cfg.Archive {
+		return nil, fmt.Errorf("NewRotateWriter archive is empty")
+	}
+	if cfg.RotationTime <= 0 {
+		cfg.RotationTime = defaultRotationTime
+	}
+	if 0 == cfg.MaxAge {
+		cfg.MaxAge = defaultMaxAge
+	}
+	if nil == cfg.Clock {
+		cfg.Clock = systemClock{}
+	}
+	if err := os.MkdirAll(cfg.Directory, os.ModePerm); nil != err {
+		return nil, err
+	}
+
+	//切分间隔小于一天时文件名需要精确到小时/分钟，否则会重名
+	layout := "2006-01-02"
+	if cfg.RotationTime < time.Hour {
+		layout = "2006-01-02-15-04"
+	} else if cfg.RotationTime < 24*time.Hour {
+		layout = "2006-01-02-15"
+	}
+
+	ret := &RotateWriter{
+		cfg:    cfg,
+		layout: layout,
+	}
+
+	return ret, nil
+}
+
+func (w *RotateWriter) Write(p []byte) (n int, err error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	period := w.periodStart(w.cfg.Clock.Now())
+	if nil == w.file || !period.Equal(w.period) {
+		if err = w.openPeriod(period); nil != err {
+			return 0, err
+		}
+	} else if w.cfg.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.cfg.MaxSize {
+		if err = w.openNext(); nil != err {
+			return 0, err
+		}
+	}
+
+	n, err = w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *RotateWriter) Sync() error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if nil == w.file {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close
+/* @Description: 关闭当前文件，等待压缩/清理完成
+ * @return error
+ */
+func (w *RotateWriter) Close() error {
+	unregisterLogFile(w.cfg.Archive, w)
+
+	w.mtx.Lock()
+	err := w.closeFile()
+	w.mtx.Unlock()
+
+	w.wg.Wait()
+	return err
+}
+
+// Rotate
+/* @Description: 立即切分到新文件，如收到 SIGHUP 时调用
+ * @return error
+ */
+func (w *RotateWriter) Rotate() error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	period := w.periodStart(w.cfg.Clock.Now())
+	if nil == w.file || !period.Equal(w.period) {
+		return w.openPeriod(period)
+	}
+	return w.openNext()
+}
+
+// Filename
+/* @Description: 当前写入的文件
+ * @return string
+ */
+func (w *RotateWriter) Filename() string {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.filename
+}
+
+// periodStart 与 rotatelogs 一致，按本地时间截断
+func (w *RotateWriter) periodStart(now time.Time) time.Time {
+	base := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second(), now.Nanosecond(), time.UTC)
+	base = base.Truncate(w.cfg.RotationTime)
+	return time.Date(base.Year(), base.Month(), base.Day(), base.Hour(), base.Minute(), base.Second(), 0, now.Location())
+}
+
+func (w *RotateWriter) genFilename(period time.Time, generation int) string {
+	name := w.cfg.Archive + "-" + period.Format(w.layout)
+	if generation > 0 {
+		name += "." + strconv.Itoa(generation)
+	}
+	return path.Join(w.cfg.Directory, name+logSuffix)
+}
+
+// openPeriod 进入新的时间段，重启时继续写入该时间段未写满的文件
+func (w *RotateWriter) openPeriod(period time.Time) error {
+	for generation := 0; ; generation++ {
+		filename := w.genFilename(period, generation)
+		if _, err := os.Stat(filename + compressSuffix); nil == err {
+			continue
+		}
+		info, err := os.Stat(filename)
+		if nil == err && w.cfg.MaxSize > 0 && info.Size() >= w.cfg.MaxSize {
+			continue
+		}
+		return w.openFile(period, filename)
+	}
+}
+
+// openNext 当前时间段内按大小切分到下一个序号
+func (w *RotateWriter) openNext() error {
+	for generation := 1; ; generation++ {
+		filename := w.genFilename(w.period, generation)
+		if filename == w.filename {
+			continue
+		}
+		if _, err := os.Stat(filename); nil == err {
+			continue
+		}
+		if _, err := os.Stat(filename + compressSuffix); nil == err {
+			continue
+		}
+		return w.openFile(w.period, filename)
+	}
+}
+
+func (w *RotateWriter) openFile(period time.Time, filename string) error {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if nil != err {
+		return fmt.Errorf("RotateWriter open %s err: %s", filename, err.Error())
+	}
+	info, err := file.Stat()
+	if nil != err {
+		_ = file.Close()
+		return err
+	}
+
+	prev := w.filename
+	_ = w.closeFile()
+
+	w.file = file
+	w.filename = filename
+	w.period = period
+	w.size = info.Size()
+
+	if "" != w.cfg.LinkName {
+		w.link(filename)
+	}
+
+	if "" != prev && prev != filename {
+		w.wg.Add(1)
+		go w.mill(filename)
+	}
+
+	return nil
+}
+
+func (w *RotateWriter) closeFile() error {
+	if nil == w.file {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *RotateWriter) link(filename string) {
+	target := filename
+	if rel, err := filepath.Rel(filepath.Dir(w.cfg.LinkName), filename); nil == err {
+		target = rel
+	}
+	tmp := w.cfg.LinkName + "_symlink"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(target, tmp); nil != err {
+		return
+	}
+	_ = os.Rename(tmp, w.cfg.LinkName)
+}
+
+type backupFile struct {
+	name       string
+	modTime    time.Time //保留时长按该时间计算，为文件名中时间段的结束时间
+	generation int
+}
+
+// mill 压缩历史文件，删除超出数量和时长的文件
+func (w *RotateWriter) mill(current string) {
+	defer w.wg.Done()
+	w.millMtx.Lock()
+	defer w.millMtx.Unlock()
+
+	backups, err := w.backups(current)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "RotateWriter list backups err: %s\n", err.Error())
+		return
+	}
+
+	var remove []backupFile
+	if w.cfg.MaxBackups > 0 && len(backups) > w.cfg.MaxBackups {
+		remove = append(remove, backups[w.cfg.MaxBackups:]...)
+		backups = backups[:w.cfg.MaxBackups]
+	}
+	if w.cfg.MaxAge > 0 {
+		cutoff := w.cfg.Clock.Now().Add(-w.cfg.MaxAge)
+		kept := backups[:0]
+		for _, f := range backups {
+			if f.modTime.Before(cutoff) {
+				remove = append(remove, f)
+			} else {
+				kept = append(kept, f)
+			}
+		}
+		backups = kept
+	}
+
+	for _, f := range remove {
+		_ = os.Remove(f.name)
+	}
+
+	if !w.cfg.Compress {
+		return
+	}
+	for _, f := range backups {
+		if strings.HasSuffix(f.name, compressSuffix) {
+			continue
+		}
+		if err = compressFile(f.name, f.modTime); nil != err {
+			fmt.Fprintf(os.Stderr, "RotateWriter compress %s err: %s\n", f.name, err.Error())
+		}
+	}
+}
+
+// backups 除当前文件外的历史文件，按时间从新到旧
+func (w *RotateWriter) backups(current string) ([]backupFile, error) {
+	entries, err := os.ReadDir(w.cfg.Directory)
+	if nil != err {
+		return nil, err
+	}
+
+	prefix := w.cfg.Archive + "-"
+	ret := make([]backupFile, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		//前缀后紧跟日期，避免 log 匹配到 log-error 之类的其他文件
+		if rest := name[len(prefix):]; "" == rest || rest[0] < '0' || rest[0] > '9' {
+			continue
+		}
+		if !strings.HasSuffix(name, logSuffix) && !strings.HasSuffix(name, logSuffix+compressSuffix) {
+			continue
+		}
+
+		filename := path.Join(w.cfg.Directory, name)
+		if filename == current {
+			continue
+		}
+		file, ok := w.parseBackup(name[len(prefix):])
+		if !ok {
+			//切分间隔修改前的文件按修改时间计算
+			info, err := entry.Info()
+			if nil != err {
+				continue
+			}
+			file.modTime = info.ModTime()
+		}
+		file.name = filename
+		ret = append(ret, file)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].modTime.Equal(ret[j].modTime) {
+			return ret[i].generation > ret[j].generation
+		}
+		return ret[i].modTime.After(ret[j].modTime)
+	})
+
+	return ret, nil
+}
+
+// parseBackup 从文件名 时间[.序号].log[.gz] 中解析时间段及序号
+func (w *RotateWriter) parseBackup(name string) (ret backupFile, ok bool) {
+	name = strings.TrimSuffix(strings.TrimSuffix(name, compressSuffix), logSuffix)
+	if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+		generation, err := strconv.Atoi(name[idx+1:])
+		if nil != err {
+			return ret, false
+		}
+		ret.generation = generation
+		name = name[:idx]
+	}
+
+	period, err := time.ParseInLocation(w.layout, name, time.Local)
+	if nil != err {
+		return ret, false
+	}
+	ret.modTime = period.Add(w.cfg.RotationTime)
+	return ret, true
+}
+
+func compressFile(filename string, modTime time.Time) error {
+	src, err := os.Open(filename)
+	if nil != err {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(filename+compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if nil != err {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); nil == err {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); nil == err {
+		err = closeErr
+	}
+	if nil != err {
+		_ = os.Remove(filename + compressSuffix)
+		return err
+	}
+
+	_ = os.Chtimes(filename+compressSuffix, modTime, modTime)
+	return os.Remove(filename)
+}
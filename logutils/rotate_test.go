@@ -0,0 +1,205 @@
+package logutils
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: rotate_test
+ * @Date: 2026-10-19 9:20 下午
+ */
+
+type fakeClock struct {
+	now time.Time
+	mtx sync.Mutex
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 10, 19, 10, 0, 0, 0, time.Local)}
+}
+
+func listFiles(t *testing.T, dir string) []string {
+	files, err := filepath.Glob(path.Join(dir, "app-*"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	ret := make([]string, 0, len(files))
+	for _, f := range files {
+		ret = append(ret, filepath.Base(f))
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func writeLine(t *testing.T, w *RotateWriter, line string) {
+	if _, err := w.Write([]byte(line + "\n")); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func Test_RotateBySize(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewRotateWriter(RotateConfig{
+		Directory: dir,
+		Archive:   "app",
+		LinkName:  path.Join(dir, "latest"),
+		MaxSize:   10,
+		Clock:     newFakeClock(),
+	})
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	writeLine(t, w, "aaaaaaaa")
+	writeLine(t, w, "bbbbbbbb")
+	writeLine(t, w, "cccccccc")
+	if err = w.Close(); nil != err {
+		t.Fatal(err)
+	}
+
+	expect := []string{"app-2026-10-19.1.log", "app-2026-10-19.2.log", "app-2026-10-19.log"}
+	if files := listFiles(t, dir); strings.Join(files, ",") != strings.Join(expect, ",") {
+		t.Errorf("files %v, expect %v", files, expect)
+	}
+
+	target, err := os.Readlink(path.Join(dir, "latest"))
+	if nil != err || target != "app-2026-10-19.2.log" {
+		t.Errorf("link target %s, err: %v", target, err)
+	}
+}
+
+func Test_RotateByTime(t *testing.T) {
+	dir := t.TempDir()
+	clock := newFakeClock()
+	w, err := NewRotateWriter(RotateConfig{
+		Directory:    dir,
+		Archive:      "app",
+		RotationTime: time.Hour,
+		Clock:        clock,
+	})
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	writeLine(t, w, "first")
+	clock.Add(30 * time.Minute)
+	writeLine(t, w, "same hour")
+	clock.Add(30 * time.Minute)
+	writeLine(t, w, "next hour")
+	_ = w.Close()
+
+	expect := []string{"app-2026-10-19-10.log", "app-2026-10-19-11.log"}
+	if files := listFiles(t, dir); strings.Join(files, ",") != strings.Join(expect, ",") {
+		t.Errorf("files %v, expect %v", files, expect)
+	}
+}
+
+func Test_RotateRetention(t *testing.T) {
+	dir := t.TempDir()
+	clock := newFakeClock()
+	w, err := NewRotateWriter(RotateConfig{
+		Directory:  dir,
+		Archive:    "app",
+		MaxBackups: 2,
+		MaxAge:     3 * 24 * time.Hour,
+		Compress:   true,
+		Clock:      clock,
+	})
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		writeLine(t, w, "day")
+		clock.Add(24 * time.Hour)
+		w.wg.Wait()
+	}
+	writeLine(t, w, "today")
+	w.wg.Wait()
+
+	//最多保留2个历史文件并压缩
+	expect := []string{"app-2026-10-21.log.gz", "app-2026-10-22.log.gz", "app-2026-10-23.log"}
+	if files := listFiles(t, dir); strings.Join(files, ",") != strings.Join(expect, ",") {
+		t.Fatalf("files %v, expect %v", files, expect)
+	}
+
+	f, err := os.Open(path.Join(dir, "app-2026-10-22.log.gz"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if nil != err {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(gz)
+	if string(content) != "day\n" {
+		t.Errorf("compressed content %q", content)
+	}
+
+	//超过保留时长的历史文件被删除，时长从文件所在时间段结束时算起
+	clock.Add(3*24*time.Hour + time.Hour)
+	if err = w.Rotate(); nil != err {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+
+	expect = []string{"app-2026-10-23.log.gz", "app-2026-10-26.log"}
+	if files := listFiles(t, dir); strings.Join(files, ",") != strings.Join(expect, ",") {
+		t.Errorf("files %v, expect %v", files, expect)
+	}
+}
+
+func Test_ErrorArchive(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DefaultZapConfig
+	cfg.Directory = dir
+	cfg.LinkName = path.Join(dir, "latest_log")
+	cfg.Archive = "app"
+	cfg.ErrorArchive = "error"
+	cfg.Format = "json"
+	cfg.LogInConsole = false
+	InitLogger(cfg)
+
+	Info("info")
+	Error("error")
+
+	errDir := t.TempDir()
+	files, _ := filepath.Glob(path.Join(dir, "error-*.log"))
+	if len(files) != 1 {
+		t.Fatalf("error files: %v", files)
+	}
+	if err := os.Rename(files[0], path.Join(errDir, filepath.Base(files[0]))); nil != err {
+		t.Fatal(err)
+	}
+
+	if entries := readTestLogs(t, dir); len(entries) != 2 {
+		t.Errorf("expect 2 entries, got %v", entries)
+	}
+	if entries := readTestLogs(t, errDir); len(entries) != 1 || entries[0]["message"] != "error" {
+		t.Errorf("error entries: %v", entries)
+	}
+}
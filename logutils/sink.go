@@ -0,0 +1,473 @@
+package logutils
+
+/**
+ * @Author: lee
+ * @Description: 远程日志输出，日志按批发送到 syslog/http/mq，发送失败时写入本地磁盘，恢复后补发
+ * @File: sink
+ * @Date: 2026-10-20 2:10 下午
+ */
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	SinkTypeSyslog = "syslog"
+	SinkTypeHTTP   = "http"
+)
+
+const (
+	defaultSinkBatchSize     = 100
+	defaultSinkFlushInterval = time.Second
+	defaultSinkQueueSize     = 10000
+	defaultSinkRetry         = 3
+	defaultSinkSpoolSize     = 512
+)
+
+// sinkFlushTimeout Panic/Fatal 及关闭时等待发送的最长时间，避免远程输出不可用时卡住退出
+var sinkFlushTimeout = 3 * time.Second
+
+type SinkConfig struct {
+	Type          string            `json:"type"     yaml:"type"                     mapstructure:"type"`           //syslog/http，代码中通过 AddSink 添加的可不填
+	Level         string            `json:"level"     yaml:"level"                   mapstructure:"level"`          //最低级别，默认info
+	Network       string            `json:"network"     yaml:"network"               mapstructure:"network"`        //syslog: udp/tcp，默认udp
+	Address       string            `json:"address"     yaml:"address"               mapstructure:"address"`        //syslog: host:port
+	AppName       string            `json:"app-name"     yaml:"app-name"             mapstructure:"app-name"`       //syslog: 默认进程名
+	Facility      int               `json:"facility"     yaml:"facility"             mapstructure:"facility"`       //syslog: 默认16(local0)
+	URL           string            `json:"url"     yaml:"url"                       mapstructure:"url"`            //http: 推送地址
+	Format        string            `json:"format"     yaml:"format"                 mapstructure:"format"`         //http: loki/elasticsearch
+	Index         string            `json:"index"     yaml:"index"                   mapstructure:"index"`          //http: elasticsearch 索引
+	Labels        map[string]string `json:"labels"     yaml:"labels"                 mapstructure:"labels"`         //http: loki 标签
+	Headers       map[string]string `json:"headers"     yaml:"headers"               mapstructure:"headers"`        //http: 请求头，如认证信息
+	BatchSize     int               `json:"batch-size"     yaml:"batch-size"         mapstructure:"batch-size"`     //每批条数，默认100
+	FlushInterval time.Duration     `json:"flush-interval"     yaml:"flush-interval" mapstructure:"flush-interval"` //发送间隔，默认1s
+	QueueSize     int               `json:"queue-size"     yaml:"queue-size"         mapstructure:"queue-size"`     //待发送队列容量，满了丢弃，默认10000
+	Retry         int               `json:"retry"     yaml:"retry"                   mapstructure:"retry"`          //发送失败重试次数，默认3
+	SpoolDir      string            `json:"spool-dir"     yaml:"spool-dir"           mapstructure:"spool-dir"`      //发送失败时落盘的目录，空则丢弃
+	MaxSpoolSize  int               `json:"max-spool-size"     yaml:"max-spool-size" mapstructure:"max-spool-size"` //落盘最大MB，超过删除最旧的，默认512
+}
+
+// SinkEntry 一条已编码为json的日志
+type SinkEntry struct {
+	Level zapcore.Level
+	Time  time.Time
+	Data  []byte
+}
+
+type Sink interface {
+	Name() string
+	// Send 发送一批日志，返回错误时整批重试或落盘
+	Send(entries []SinkEntry) error
+	Close() error
+}
+
+var (
+	sinkWriters   = map[*sinkWriter]struct{}{}
+	sinkWritersMu sync.Mutex
+)
+
+// NewSink
+/* @Description: 根据配置创建 syslog/http 输出
+ * @param cfg SinkConfig
+ * @return Sink
+ * @return error
+ */
+func NewSink(cfg SinkConfig) (Sink, error) {
+	switch strings.ToLower(cfg.Type) {
+	case SinkTypeSyslog:
+		return NewSyslogSink(cfg.Network, cfg.Address, cfg.AppName, cfg.Facility)
+	case SinkTypeHTTP:
+		return NewHTTPSink(cfg.URL, cfg.Format, cfg.Index, cfg.Labels, cfg.Headers)
+	default:
+		return nil, fmt.Errorf("NewSink unknown type: %s", cfg.Type)
+	}
+}
+
+// NewSinkCore
+/* @Description: 创建输出到 sink 的 zap core，可与其他 core 组合
+ * @param sink Sink
+ * @param cfg SinkConfig
+ * @return zapcore.Core
+ * @return error
+ */
+func NewSinkCore(sink Sink, cfg SinkConfig) (zapcore.Core, error) {
+	return newSinkCore(sink, cfg, nil)
+}
+
+// newSinkCore enab 非空时同时受日志本身级别控制
+func newSinkCore(sink Sink, cfg SinkConfig, enab zapcore.LevelEnabler) (zapcore.Core, error) {
+	lvl := zap.InfoLevel
+	if "" != cfg.Level {
+		var err error
+		if lvl, err = ParseLevel(cfg.Level); nil != err {
+			return nil, err
+		}
+	}
+
+	w, err := newSinkWriter(sink, cfg)
+	if nil != err {
+		return nil, err
+	}
+
+	var levelEnabler zapcore.LevelEnabler = lvl
+	if nil != enab {
+		levelEnabler = zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return lvl.Enabled(l) && enab.Enabled(l)
+		})
+	}
+
+	//远程输出统一json，不带颜色，时间带时区
+	encCfg := getEncoderConfig(ZapConfig{})
+	encCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	return &sinkCore{
+		LevelEnabler: levelEnabler,
+		enc:          zapcore.NewJSONEncoder(encCfg),
+		w:            w,
+	}, nil
+}
+
+// AddSink
+/* @Description: 全局日志增加一个远程输出，如 rabbitmq.NewLogSink，之后通过包级函数及 Logger() 获取的日志生效
+ * @param sink Sink
+ * @param cfg SinkConfig
+ * @return error
+ */
+func AddSink(sink Sink, cfg SinkConfig) error {
+	module := globalModule()
+	if nil == module {
+		return errorNotInit
+	}
+
+	core, err := newSinkCore(sink, cfg, module.level)
+	if nil != err {
+		return err
+	}
+	//随全局日志一起关闭
+	w := core.(*sinkCore).w
+	if err = addCore("", core, w); nil != err {
+		_ = w.Close()
+		return err
+	}
+	return nil
+}
+
+// SinkDropped
+/* @Description: 所有远程输出因队列满或落盘失败丢弃的日志数量
+ * @return uint64
+ */
+func SinkDropped() uint64 {
+	sinkWritersMu.Lock()
+	defer sinkWritersMu.Unlock()
+
+	var ret uint64
+	for w := range sinkWriters {
+		ret += atomic.LoadUint64(&w.dropped)
+	}
+	return ret
+}
+
+// newSinkCores 配置中的远程输出，同时返回需要关闭的写入器
+func newSinkCores(configs []SinkConfig, enab zapcore.LevelEnabler) ([]zapcore.Core, []io.Closer, error) {
+	ret := make([]zapcore.Core, 0, len(configs))
+	closers := make([]io.Closer, 0, len(configs))
+	for _, cfg := range configs {
+		sink, err := NewSink(cfg)
+		if nil != err {
+			closeAll(closers)
+			return nil, nil, err
+		}
+		core, err := newSinkCore(sink, cfg, enab)
+		if nil != err {
+			_ = sink.Close()
+			closeAll(closers)
+			return nil, nil, err
+		}
+		ret = append(ret, core)
+		closers = append(closers, core.(*sinkCore).w)
+	}
+	return ret, closers, nil
+}
+
+func syncSinks() error {
+	sinkWritersMu.Lock()
+	writers := make([]*sinkWriter, 0, len(sinkWriters))
+	for w := range sinkWriters {
+		writers = append(writers, w)
+	}
+	sinkWritersMu.Unlock()
+
+	var ret error
+	for _, w := range writers {
+		if err := w.Flush(); nil != err && nil == ret {
+			ret = err
+		}
+	}
+	return ret
+}
+
+type sinkCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	w   *sinkWriter
+}
+
+func (c *sinkCore) With(fields []zap.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &sinkCore{
+		LevelEnabler: c.LevelEnabler,
+		enc:          enc,
+		w:            c.w,
+	}
+}
+
+func (c *sinkCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *sinkCore) Write(ent zapcore.Entry, fields []zap.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if nil != err {
+		return err
+	}
+	data := make([]byte, 0, buf.Len())
+	data = append(data, strings.TrimRight(buf.String(), "\n")...)
+	buf.Free()
+
+	c.w.push(SinkEntry{Level: ent.Level, Time: ent.Time, Data: data})
+	if ent.Level > zap.ErrorLevel {
+		//进程可能随后退出，限时发送
+		return c.w.flushTimeout(sinkFlushTimeout)
+	}
+	return nil
+}
+
+func (c *sinkCore) Sync() error {
+	return c.w.Flush()
+}
+
+type sinkWriter struct {
+	sink     Sink
+	spool    *diskSpool
+	queue    chan SinkEntry
+	flushReq chan chan error
+	batch    int
+	retry    int
+	interval time.Duration
+	dropped  uint64
+	stop     chan struct{}
+	done     chan struct{}
+	closed   sync.Once
+}
+
+func newSinkWriter(sink Sink, cfg SinkConfig) (*sinkWriter, error) {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultSinkBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultSinkFlushInterval
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultSinkQueueSize
+	}
+	if cfg.Retry < 0 {
+		cfg.Retry = 0
+	} else if 0 == cfg.Retry {
+		cfg.Retry = defaultSinkRetry
+	}
+	if cfg.MaxSpoolSize <= 0 {
+		cfg.MaxSpoolSize = defaultSinkSpoolSize
+	}
+
+	ret := &sinkWriter{
+		sink:     sink,
+		queue:    make(chan SinkEntry, cfg.QueueSize),
+		flushReq: make(chan chan error),
+		batch:    cfg.BatchSize,
+		retry:    cfg.Retry,
+		interval: cfg.FlushInterval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if "" != cfg.SpoolDir {
+		spool, err := newDiskSpool(cfg.SpoolDir, sink.Name(), int64(cfg.MaxSpoolSize)<<20)
+		if nil != err {
+			return nil, err
+		}
+		ret.spool = spool
+	}
+	go ret.goSend()
+
+	sinkWritersMu.Lock()
+	sinkWriters[ret] = struct{}{}
+	sinkWritersMu.Unlock()
+
+	return ret, nil
+}
+
+func (w *sinkWriter) push(entry SinkEntry) {
+	select {
+	case w.queue <- entry:
+	default:
+		atomic.AddUint64(&w.dropped, 1)
+	}
+}
+
+// Flush 发送队列中已有的日志，失败的落盘
+func (w *sinkWriter) Flush() error {
+	ch := make(chan error, 1)
+	select {
+	case w.flushReq <- ch:
+	case <-w.done:
+		return nil
+	}
+	return <-ch
+}
+
+// flushTimeout 同 Flush，超时后不再等待，发送在后台继续
+func (w *sinkWriter) flushTimeout(timeout time.Duration) error {
+	ret := make(chan error, 1)
+	go func() {
+		ret <- w.Flush()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-ret:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("log sink %s flush timeout after %s", w.sink.Name(), timeout)
+	}
+}
+
+// Close 限时发送剩余日志后停止后台协程并关闭 sink，只执行一次
+func (w *sinkWriter) Close() error {
+	var ret error
+	w.closed.Do(func() {
+		ret = w.flushTimeout(sinkFlushTimeout)
+		close(w.stop)
+		select {
+		case <-w.done:
+		case <-time.After(sinkFlushTimeout):
+		}
+		if err := w.sink.Close(); nil != err && nil == ret {
+			ret = err
+		}
+
+		sinkWritersMu.Lock()
+		delete(sinkWriters, w)
+		sinkWritersMu.Unlock()
+	})
+	return ret
+}
+
+func (w *sinkWriter) goSend() {
+	defer close(w.done)
+	tick := time.NewTicker(w.interval)
+	defer tick.Stop()
+
+	pending := make([]SinkEntry, 0, w.batch)
+	for {
+		select {
+		case <-w.stop:
+			//Flush 之后才进入的日志不再发送
+			atomic.AddUint64(&w.dropped, uint64(len(pending)+len(w.queue)))
+			return
+		case entry := <-w.queue:
+			pending = append(pending, entry)
+			if len(pending) >= w.batch {
+				_ = w.send(pending)
+				pending = pending[:0]
+			}
+		case <-tick.C:
+			if len(pending) > 0 {
+				_ = w.send(pending)
+				pending = pending[:0]
+			}
+			_ = w.replay()
+		case ch := <-w.flushReq:
+			for n := len(w.queue); n > 0; n-- {
+				pending = append(pending, <-w.queue)
+			}
+			var err error
+			for len(pending) > 0 {
+				size := len(pending)
+				if size > w.batch {
+					size = w.batch
+				}
+				if e := w.send(pending[:size]); nil != e && nil == err {
+					err = e
+				}
+				pending = pending[size:]
+			}
+			pending = make([]SinkEntry, 0, w.batch)
+			ch <- err
+		}
+	}
+}
+
+// send 带重试发送，仍然失败时落盘；有未补发的日志时先补发，保证顺序
+func (w *sinkWriter) send(entries []SinkEntry) error {
+	err := w.replay()
+	for i := 0; nil == err && i <= w.retry; i++ {
+		if i > 0 {
+			time.Sleep(time.Duration(50<<uint(i-1)) * time.Millisecond)
+		}
+		if err = w.sink.Send(entries); nil == err {
+			return nil
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "log sink %s send err: %s\n", w.sink.Name(), err.Error())
+	if nil == w.spool {
+		atomic.AddUint64(&w.dropped, uint64(len(entries)))
+		return err
+	}
+	if spoolErr := w.spool.Append(entries); nil != spoolErr {
+		fmt.Fprintf(os.Stderr, "log sink %s spool err: %s\n", w.sink.Name(), spoolErr.Error())
+		atomic.AddUint64(&w.dropped, uint64(len(entries)))
+	}
+	return err
+}
+
+// replay 按写入顺序补发落盘的日志，失败时留到下次
+func (w *sinkWriter) replay() error {
+	if nil == w.spool {
+		return nil
+	}
+	for {
+		name, entries, err := w.spool.Oldest()
+		if nil != err {
+			fmt.Fprintf(os.Stderr, "log sink %s read spool err: %s\n", w.sink.Name(), err.Error())
+			//目录读取失败时没有可删除的文件，留到下次
+			if "" == name {
+				return err
+			}
+			w.spool.Remove(name)
+			continue
+		}
+		if "" == name {
+			return nil
+		}
+		if len(entries) > 0 {
+			if err = w.sink.Send(entries); nil != err {
+				return err
+			}
+		}
+		w.spool.Remove(name)
+	}
+}
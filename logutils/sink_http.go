@@ -0,0 +1,190 @@
+package logutils
+
+/**
+ * @Author: lee
+ * @Description: http 批量输出，支持 loki push 及 elasticsearch bulk 格式
+ * @File: sink_http
+ * @Date: 2026-10-20 3:20 下午
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HTTPFormatLoki          = "loki"
+	HTTPFormatElasticsearch = "elasticsearch"
+)
+
+const httpSinkTimeout = 10 * time.Second
+
+type HTTPSink struct {
+	url     string
+	format  string
+	index   string
+	labels  map[string]string
+	headers map[string]string
+	client  *http.Client
+}
+
+var _ Sink = (*HTTPSink)(nil)
+
+// NewHTTPSink
+/* @Description: 创建 http 批量输出
+ * @param rawURL string loki: http://host:3100/loki/api/v1/push，elasticsearch: http://host:9200/_bulk
+ * @param format string loki/elasticsearch
+ * @param index string elasticsearch 索引，支持时间格式如 app-{2006.01.02}
+ * @param labels map[string]string loki 标签，level 标签自动添加
+ * @param headers map[string]string 请求头
+ * @return *HTTPSink
+ * @return error
+ */
+func NewHTTPSink(rawURL string, format string, index string, labels map[string]string, headers map[string]string) (*HTTPSink, error) {
+	if _, err := url.ParseRequestURI(rawURL); nil != err {
+		return nil, fmt.Errorf("NewHTTPSink|ParseRequestURI err: %s", err.Error())
+	}
+	format = strings.ToLower(format)
+	switch format {
+	case HTTPFormatLoki:
+		if 0 == len(labels) {
+			labels = map[string]string{"job": "gutils"}
+		}
+	case HTTPFormatElasticsearch:
+		if "" == index {
+			return nil, fmt.Errorf("NewHTTPSink elasticsearch index is empty")
+		}
+	default:
+		return nil, fmt.Errorf("NewHTTPSink unsupported format: %s", format)
+	}
+
+	return &HTTPSink{
+		url:     rawURL,
+		format:  format,
+		index:   index,
+		labels:  labels,
+		headers: headers,
+		client:  &http.Client{Timeout: httpSinkTimeout},
+	}, nil
+}
+
+func (s *HTTPSink) Name() string {
+	return "http-" + s.format + "-" + s.url
+}
+
+func (s *HTTPSink) Send(entries []SinkEntry) error {
+	var (
+		body        []byte
+		contentType string
+		err         error
+	)
+	if HTTPFormatLoki == s.format {
+		body, err = s.lokiBody(entries)
+		contentType = "application/json"
+	} else {
+		body = s.bulkBody(entries)
+		contentType = "application/x-ndjson"
+	}
+	if nil != err {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if nil != err {
+		return fmt.Errorf("HTTPSink|NewRequest err: %s", err.Error())
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	rsp, err := s.client.Do(req)
+	if nil != err {
+		return fmt.Errorf("HTTPSink|Do err: %s", err.Error())
+	}
+	defer rsp.Body.Close()
+
+	rspBody, _ := ioutil.ReadAll(io.LimitReader(rsp.Body, 64<<10))
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		return fmt.Errorf("HTTPSink status: %d, body: %s", rsp.StatusCode, rspBody)
+	}
+	//bulk 部分失败时整批重发
+	if HTTPFormatElasticsearch == s.format {
+		var result struct {
+			Errors bool `json:"errors"`
+		}
+		if nil == json.Unmarshal(rspBody, &result) && result.Errors {
+			return fmt.Errorf("HTTPSink bulk has errors")
+		}
+	}
+	return nil
+}
+
+func (s *HTTPSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// lokiBody 按级别分 stream，{"streams":[{"stream":{...},"values":[["纳秒","日志"]]}]}
+func (s *HTTPSink) lokiBody(entries []SinkEntry) ([]byte, error) {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	streams := make([]*stream, 0)
+	byLevel := map[string]*stream{}
+	for _, e := range entries {
+		lvl := e.Level.String()
+		st, ok := byLevel[lvl]
+		if !ok {
+			labels := make(map[string]string, len(s.labels)+1)
+			for k, v := range s.labels {
+				labels[k] = v
+			}
+			labels["level"] = lvl
+			st = &stream{Stream: labels}
+			byLevel[lvl] = st
+			streams = append(streams, st)
+		}
+		st.Values = append(st.Values, [2]string{strconv.FormatInt(e.Time.UnixNano(), 10), string(e.Data)})
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"streams": streams})
+	if nil != err {
+		return nil, fmt.Errorf("HTTPSink|Marshal err: %s", err.Error())
+	}
+	return body, nil
+}
+
+// bulkBody 每条日志一行 index 动作加一行文档
+func (s *HTTPSink) bulkBody(entries []SinkEntry) []byte {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		action, _ := json.Marshal(map[string]interface{}{
+			"index": map[string]string{"_index": s.indexName(e.Time)},
+		})
+		buf.Write(action)
+		buf.WriteByte('\n')
+		buf.Write(e.Data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// indexName 花括号中的内容按时间格式化
+func (s *HTTPSink) indexName(t time.Time) string {
+	start := strings.Index(s.index, "{")
+	end := strings.LastIndex(s.index, "}")
+	if start < 0 || end < start {
+		return s.index
+	}
+	return s.index[:start] + t.Format(s.index[start+1:end]) + s.index[end+1:]
+}
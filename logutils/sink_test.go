@@ -0,0 +1,295 @@
+package logutils
+
+import (
+	"encoding/json"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: sink_test
+ * @Date: 2026-10-20 4:00 下午
+ */
+
+func Test_HTTPSinkSpool(t *testing.T) {
+	var (
+		down     int32 = 1
+		received []string
+		mtx      sync.Mutex
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if 1 == atomic.LoadInt32(&down) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var body struct {
+			Streams []struct {
+				Stream map[string]string `json:"stream"`
+				Values [][2]string       `json:"values"`
+			} `json:"streams"`
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &body); nil != err {
+			t.Errorf("invalid loki body: %s", data)
+		}
+		mtx.Lock()
+		for _, st := range body.Streams {
+			for _, v := range st.Values {
+				received = append(received, st.Stream["level"]+" "+v[1])
+			}
+		}
+		mtx.Unlock()
+	}))
+	defer srv.Close()
+
+	cfg := SinkConfig{
+		Type:          SinkTypeHTTP,
+		URL:           srv.URL + "/loki/api/v1/push",
+		Format:        HTTPFormatLoki,
+		Retry:         -1,
+		FlushInterval: time.Hour,
+		SpoolDir:      t.TempDir(),
+	}
+	sink, err := NewSink(cfg)
+	if nil != err {
+		t.Fatal(err)
+	}
+	core, err := NewSinkCore(sink, cfg)
+	if nil != err {
+		t.Fatal(err)
+	}
+	logger := zap.New(core)
+
+	logger.Info("first", zap.Int("n", 1))
+	logger.Debug("filtered")
+	if err = logger.Sync(); nil == err {
+		t.Fatal("expect send error while sink is down")
+	}
+	spool := core.(*sinkCore).w.spool
+	if spool.Size() == 0 {
+		t.Fatal("expect spooled entries")
+	}
+
+	atomic.StoreInt32(&down, 0)
+	logger.Warn("second")
+	if err = logger.Sync(); nil != err {
+		t.Fatal(err)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if 2 != len(received) {
+		t.Fatalf("expect 2 entries, got %v", received)
+	}
+	//落盘的先补发
+	if !strings.HasPrefix(received[0], "info ") || !strings.Contains(received[0], `"n":1`) {
+		t.Fatalf("unexpected replayed entry: %s", received[0])
+	}
+	if !strings.HasPrefix(received[1], "warn ") || !strings.Contains(received[1], `"message":"second"`) {
+		t.Fatalf("unexpected entry: %s", received[1])
+	}
+	if 0 != spool.Size() {
+		t.Fatal("expect spool empty after replay")
+	}
+}
+
+func Test_SyslogSink(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	sink, err := NewSyslogSink("udp", pc.LocalAddr().String(), "gutils", 0)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	core, err := NewSinkCore(sink, SinkConfig{})
+	if nil != err {
+		t.Fatal(err)
+	}
+	logger := zap.New(core)
+	logger.Error("syslog", zap.String("k", "v"))
+	if err = logger.Sync(); nil != err {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 4096)
+	_ = pc.SetReadDeadline(time.Now().Add(3 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if nil != err {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+	//local0(16)*8 + err(3)
+	if !strings.HasPrefix(msg, "<131>1 ") || !strings.Contains(msg, " gutils ") || !strings.Contains(msg, `"k":"v"`) {
+		t.Fatalf("unexpected syslog message: %s", msg)
+	}
+}
+
+type blockSink struct {
+	block  chan struct{}
+	closed int32
+}
+
+func (s *blockSink) Name() string {
+	return "block"
+}
+
+func (s *blockSink) Send(entries []SinkEntry) error {
+	<-s.block
+	return nil
+}
+
+func (s *blockSink) Close() error {
+	atomic.StoreInt32(&s.closed, 1)
+	return nil
+}
+
+func Test_SinkWriterClose(t *testing.T) {
+	timeout := sinkFlushTimeout
+	sinkFlushTimeout = 50 * time.Millisecond
+	defer func() {
+		sinkFlushTimeout = timeout
+	}()
+
+	sink := &blockSink{block: make(chan struct{})}
+	cfg := SinkConfig{Retry: -1, FlushInterval: time.Hour, SpoolDir: path.Join(t.TempDir(), "bad[")}
+	core, err := NewSinkCore(sink, cfg)
+	if nil != err {
+		t.Fatal(err)
+	}
+	w := core.(*sinkCore).w
+
+	//目录无法列出时返回错误，不再死循环
+	if err = w.replay(); nil == err {
+		t.Error("expect spool list error")
+	}
+
+	//远程输出不可用时 panic 级别的日志限时发送
+	start := time.Now()
+	if err = core.Write(zapcore.Entry{Level: zap.PanicLevel, Time: start, Message: "panic"}, nil); nil == err {
+		t.Error("expect flush timeout")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("flush blocked %s", elapsed)
+	}
+
+	close(sink.block)
+	if err = w.Close(); nil != err {
+		t.Fatal(err)
+	}
+	sinkWritersMu.Lock()
+	_, ok := sinkWriters[w]
+	sinkWritersMu.Unlock()
+	if ok || 1 != atomic.LoadInt32(&sink.closed) {
+		t.Error("expect sink closed and unregistered")
+	}
+	if err = w.Flush(); nil != err {
+		t.Error("expect flush after close returns")
+	}
+}
+
+// Test_TailLog 只写出本地异步队列，远程输出卡住时不等待
+func Test_TailLog(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DefaultZapConfig
+	cfg.Directory = dir
+	cfg.LinkName = ""
+	cfg.LogInConsole = false
+	cfg.Async = &AsyncConfig{FlushInterval: time.Hour}
+	InitLogger(cfg)
+
+	sink := &blockSink{block: make(chan struct{})}
+	defer close(sink.block)
+	if err := AddSink(sink, SinkConfig{FlushInterval: time.Hour}); nil != err {
+		t.Fatal(err)
+	}
+	Info("first")
+	Info("last")
+
+	lines := make(chan []string, 1)
+	go func() {
+		ret, _ := TailLog(1)
+		lines <- ret
+	}()
+	select {
+	case ret := <-lines:
+		if 1 != len(ret) || !strings.Contains(ret[0], "last") {
+			t.Fatalf("unexpected tail %v", ret)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("TailLog blocked on remote sink")
+	}
+}
+
+// Test_TailLogWriteSyncer 外部创建的输出不替换全局日志文件
+func Test_TailLogWriteSyncer(t *testing.T) {
+	cfg := DefaultZapConfig
+	cfg.Directory = t.TempDir()
+	cfg.LinkName = ""
+	cfg.LogInConsole = false
+	InitLogger(cfg)
+	file := LogFile("")
+
+	other := cfg
+	other.Directory = t.TempDir()
+	w, err := NewWriteSyncer(other)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer w.(*RotateWriter).Close()
+	if file != LogFile("") {
+		t.Fatalf("expect log file %s, got %s", file, LogFile(""))
+	}
+
+	Info("global")
+	_, _ = w.Write([]byte("other\n"))
+	lines, err := TailLog(1)
+	if nil != err || 1 != len(lines) || !strings.Contains(lines[0], "global") {
+		t.Fatalf("unexpected tail %v, err: %v", lines, err)
+	}
+}
+
+// Test_SyncTimeout 远程输出卡住时限时返回，本地异步队列已写出
+func Test_SyncTimeout(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DefaultZapConfig
+	cfg.Directory = dir
+	cfg.LinkName = ""
+	cfg.LogInConsole = false
+	cfg.Format = "json"
+	cfg.Async = &AsyncConfig{FlushInterval: time.Hour}
+	InitLogger(cfg)
+
+	sink := &blockSink{block: make(chan struct{})}
+	defer close(sink.block)
+	if err := AddSink(sink, SinkConfig{FlushInterval: time.Hour}); nil != err {
+		t.Fatal(err)
+	}
+	Info("before exit")
+
+	start := time.Now()
+	if err := SyncTimeout(50 * time.Millisecond); nil == err {
+		t.Error("expect sync timeout")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("SyncTimeout blocked %s", elapsed)
+	}
+	if entries := readTestLogs(t, dir); 1 != len(entries) || "before exit" != entries[0]["message"] {
+		t.Errorf("local entries not flushed: %v", entries)
+	}
+}
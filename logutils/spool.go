@@ -0,0 +1,184 @@
+package logutils
+
+/**
+ * @Author: lee
+ * @Description: 远程输出不可用时的本地磁盘缓存，每批一个文件，按写入顺序补发
+ * @File: spool
+ * @Date: 2026-10-20 2:40 下午
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/0DeOrg/gutils/fileutils"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const spoolExt = ".spool"
+
+type diskSpool struct {
+	dir     string
+	maxSize int64
+	seq     uint64
+	mtx     sync.Mutex
+}
+
+// newDiskSpool 目录为 dir/name，进程重启后继续补发上次遗留的文件
+func newDiskSpool(dir string, name string, maxSize int64) (*diskSpool, error) {
+	dir = filepath.Join(dir, sanitizeSpoolName(name))
+	if err := fileutils.CreateDirectoryIfNotExist(dir, os.ModePerm); nil != err {
+		return nil, fmt.Errorf("newDiskSpool|CreateDirectory err: %s", err.Error())
+	}
+	return &diskSpool{
+		dir:     dir,
+		maxSize: maxSize,
+	}, nil
+}
+
+// Append 一行一条: 级别 时间(纳秒) json
+func (s *diskSpool) Append(entries []SinkEntry) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var buf bytes.Buffer
+	for _, e := range entries {
+		buf.WriteString(strconv.Itoa(int(e.Level)))
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatInt(e.Time.UnixNano(), 10))
+		buf.WriteByte(' ')
+		buf.Write(e.Data)
+		buf.WriteByte('\n')
+	}
+
+	s.seq++
+	name := filepath.Join(s.dir, fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), s.seq%1000000, spoolExt))
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); nil != err {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, name); nil != err {
+		return err
+	}
+
+	s.trim()
+	return nil
+}
+
+// Oldest 最早的一个文件，没有时 name 为空
+func (s *diskSpool) Oldest() (name string, entries []SinkEntry, err error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	files, err := s.files()
+	if nil != err || 0 == len(files) {
+		return "", nil, err
+	}
+	name = files[0]
+
+	f, err := os.Open(name)
+	if nil != err {
+		return name, nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64<<10), 16<<20)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), " ", 3)
+		if 3 != len(parts) {
+			continue
+		}
+		lvl, err1 := strconv.Atoi(parts[0])
+		nano, err2 := strconv.ParseInt(parts[1], 10, 64)
+		if nil != err1 || nil != err2 {
+			continue
+		}
+		entries = append(entries, SinkEntry{
+			Level: zapcore.Level(lvl),
+			Time:  time.Unix(0, nano),
+			Data:  []byte(parts[2]),
+		})
+	}
+	return name, entries, scanner.Err()
+}
+
+func (s *diskSpool) Remove(name string) {
+	if "" == name {
+		return
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	_ = os.Remove(name)
+}
+
+// Size 缓存文件总大小
+func (s *diskSpool) Size() int64 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	files, _ := s.files()
+	var ret int64
+	for _, name := range files {
+		if info, err := os.Stat(name); nil == err {
+			ret += info.Size()
+		}
+	}
+	return ret
+}
+
+// trim 超过上限时删除最旧的文件
+func (s *diskSpool) trim() {
+	if s.maxSize <= 0 {
+		return
+	}
+	files, err := s.files()
+	if nil != err {
+		return
+	}
+
+	sizes := make([]int64, len(files))
+	var total int64
+	for i, name := range files {
+		if info, err := os.Stat(name); nil == err {
+			sizes[i] = info.Size()
+			total += sizes[i]
+		}
+	}
+	//最新的一个保留
+	for i := 0; i < len(files)-1 && total > s.maxSize; i++ {
+		if err := os.Remove(files[i]); nil == err {
+			total -= sizes[i]
+			fmt.Fprintf(os.Stderr, "log spool %s full, drop %s\n", s.dir, filepath.Base(files[i]))
+		}
+	}
+}
+
+// files 文件名以纳秒时间开头，按名称排序即按写入顺序
+func (s *diskSpool) files() ([]string, error) {
+	ret, err := filepath.Glob(filepath.Join(s.dir, "*"+spoolExt))
+	if nil != err {
+		return nil, err
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+func sanitizeSpoolName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', '-' == r, '_' == r, '.' == r:
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,162 @@
+package logutils
+
+/**
+ * @Author: lee
+ * @Description: 标准库 log 输出写入日志
+ * @File: stdlog
+ * @Date: 2026-10-19 9:50 下午
+ */
+
+import (
+	"bytes"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"log"
+	"strings"
+	"sync"
+)
+
+// stdLevelPrefixes 常见库输出中的级别前缀，如 raft/consul 的 [ERR]、[WARN]
+var stdLevelPrefixes = []struct {
+	prefix string
+	level  zapcore.Level
+}{
+	{"[ERROR]", zap.ErrorLevel},
+	{"[ERR]", zap.ErrorLevel},
+	{"[WARN]", zap.WarnLevel},
+	{"[WARNING]", zap.WarnLevel},
+	{"[INFO]", zap.InfoLevel},
+	{"[DEBUG]", zap.DebugLevel},
+	{"[TRACE]", zap.DebugLevel},
+}
+
+// StdWriter 每次 Write 为一条日志，可作为 log.Logger 或第三方库的 io.Writer 输出
+type StdWriter struct {
+	logger      ILogger
+	level       zapcore.Level
+	inferLevels bool
+}
+
+// NewStdWriter
+/* @Description: 创建写入日志的 io.Writer
+ * @param logger ILogger 需要调整好调用层级，标准库 log 调用时为 WithCallerSkip(3)
+ * @param lvl zapcore.Level 默认级别
+ * @param inferLevels bool 是否根据 [ERROR]/[WARN] 等前缀识别级别
+ * @return *StdWriter
+ */
+func NewStdWriter(logger ILogger, lvl zapcore.Level, inferLevels bool) *StdWriter {
+	return &StdWriter{
+		logger:      logger,
+		level:       lvl,
+		inferLevels: inferLevels,
+	}
+}
+
+func (w *StdWriter) Write(p []byte) (int, error) {
+	msg := string(bytes.TrimRight(p, "\r\n"))
+	lvl := w.level
+	if w.inferLevels {
+		lvl, msg = InferLevel(msg, w.level)
+	}
+
+	switch lvl {
+	case zap.DebugLevel:
+		w.logger.Debug(msg)
+	case zap.WarnLevel:
+		w.logger.Warn(msg)
+	case zap.ErrorLevel, zap.DPanicLevel, zap.PanicLevel, zap.FatalLevel:
+		//第三方库的 panic/fatal 不能让进程退出，统一按 error 输出
+		w.logger.Error(msg)
+	default:
+		w.logger.Info(msg)
+	}
+
+	return len(p), nil
+}
+
+// InferLevel
+/* @Description: 根据 [ERROR]/[WARN] 等前缀识别级别，返回去掉前缀的内容
+ * @param msg string
+ * @param def zapcore.Level 没有前缀时的级别
+ * @return zapcore.Level
+ * @return string
+ */
+func InferLevel(msg string, def zapcore.Level) (zapcore.Level, string) {
+	trimmed := strings.TrimLeft(msg, " ")
+	for _, p := range stdLevelPrefixes {
+		if strings.HasPrefix(trimmed, p.prefix) {
+			return p.level, strings.TrimLeft(trimmed[len(p.prefix):], " ")
+		}
+	}
+	return def, msg
+}
+
+// NewStdLogger
+/* @Description: 创建写入命名日志的标准库 log.Logger，用于只接受 *log.Logger 的库
+ * @param name string 命名日志，空为全局日志
+ * @param lvl zapcore.Level 默认级别，可被 [ERROR]/[WARN] 等前缀覆盖
+ * @return *log.Logger
+ */
+func NewStdLogger(name string, lvl zapcore.Level) *log.Logger {
+	return log.New(NewStdWriter(stdModule(name), lvl, true), "", 0)
+}
+
+var (
+	restoreStdLog func()
+	stdLogMtx     sync.Mutex
+)
+
+// RedirectStdLog
+/* @Description: 标准库 log 包的输出写入全局日志，InitLogger 时自动调用
+ * @return func() 恢复原来的输出
+ */
+func RedirectStdLog() func() {
+	stdLogMtx.Lock()
+	defer stdLogMtx.Unlock()
+
+	if nil != restoreStdLog {
+		restoreStdLog()
+	}
+
+	flags := log.Flags()
+	prefix := log.Prefix()
+	writer := log.Writer()
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(NewStdWriter(stdModule(""), zap.InfoLevel, true))
+
+	restore := func() {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(writer)
+	}
+	restoreStdLog = restore
+
+	return func() {
+		stdLogMtx.Lock()
+		defer stdLogMtx.Unlock()
+		restore()
+		restoreStdLog = nil
+	}
+}
+
+// refreshStdLog 已重定向时改为写入 AddCore 替换后的全局日志
+func refreshStdLog() {
+	stdLogMtx.Lock()
+	defer stdLogMtx.Unlock()
+	if nil == restoreStdLog {
+		return
+	}
+	log.SetOutput(NewStdWriter(stdModule(""), zap.InfoLevel, true))
+}
+
+// stdModule log.Printf -> Logger.output -> StdWriter.Write 多三层调用
+func stdModule(name string) ILogger {
+	var module *ZapLogModule
+	if "" == name {
+		module = mustModule().WithCallerSkip(-1)
+	} else {
+		module = namedModule(name)
+	}
+	return module.WithCallerSkip(3)
+}
@@ -0,0 +1,126 @@
+package logutils
+
+/**
+ * @Author: lee
+ * @Description: 读取日志文件末尾若干行，用于崩溃报告
+ * @File: tail
+ * @Date: 2026-10-21 11:10 上午
+ */
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+)
+
+const maxTailBytes = 1 << 20
+
+var (
+	logFiles   = map[string]*RotateWriter{}
+	logFilesMu sync.Mutex
+)
+
+func registerLogFile(archive string, w *RotateWriter) {
+	logFilesMu.Lock()
+	defer logFilesMu.Unlock()
+	logFiles[archive] = w
+}
+
+func unregisterLogFile(archive string, w *RotateWriter) {
+	logFilesMu.Lock()
+	defer logFilesMu.Unlock()
+	if logFiles[archive] == w {
+		delete(logFiles, archive)
+	}
+}
+
+// LogFile
+/* @Description: 日志当前写入的文件
+ * @param archive string 日志名称，为空时为全局日志
+ * @return string 未初始化时为空
+ */
+func LogFile(archive string) string {
+	if "" == archive {
+		archive = zapConfig.Archive
+		if "" == archive {
+			archive = "log"
+		}
+	}
+	logFilesMu.Lock()
+	w, ok := logFiles[archive]
+	logFilesMu.Unlock()
+	if !ok {
+		return ""
+	}
+	return w.Filename()
+}
+
+// syncLocalWriters 只写出全局日志异步队列中的日志，不刷新远程输出及告警，崩溃时读取日志不会等待网络
+func syncLocalWriters() {
+	module := globalModule()
+	if nil == module {
+		return
+	}
+	for _, closer := range module.closers {
+		if w, ok := closer.(*AsyncWriter); ok {
+			_ = w.Sync()
+		}
+	}
+}
+
+// TailLog
+/* @Description: 全局日志当前文件的最后 n 行，先写出异步队列中的日志，最多读取1MB
+ * @param n int
+ * @return []string
+ * @return error
+ */
+func TailLog(n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	//异步写入时文件在首次写出后才打开，先写出再取文件名
+	syncLocalWriters()
+	filename := LogFile("")
+	if "" == filename {
+		return nil, nil
+	}
+
+	f, err := os.Open(filename)
+	if nil != err {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if nil != err {
+		return nil, err
+	}
+	size := info.Size()
+	if 0 == size {
+		return nil, nil
+	}
+	offset := size - maxTailBytes
+	if offset < 0 {
+		offset = 0
+	}
+	data := make([]byte, size-offset)
+	if _, err = f.ReadAt(data, offset); nil != err && io.EOF != err {
+		return nil, err
+	}
+
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	//从中间开始读时第一行不完整
+	if offset > 0 && len(lines) > 0 {
+		lines = lines[1:]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	ret := make([]string, 0, len(lines))
+	for _, line := range lines {
+		ret = append(ret, string(line))
+	}
+	return ret, nil
+}
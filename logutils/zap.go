@@ -10,23 +10,52 @@ package logutils
 import (
 	"fmt"
 	"github.com/0DeOrg/gutils/fileutils"
-	"github.com/lestrrat-go/file-rotatelogs"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"io"
+	"net/http"
 	"os"
-	"path"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
 type ZapConfig struct {
-	Directory    string `json:"directory"     yaml:"directory"   mapstructure:"directory"`
-	ShowLine     bool   `json:"show-line"     yaml:"show-line"   mapstructure:"show-line"`
-	ZapLevel     string `json:"zap-level"     yaml:"zap-level"   mapstructure:"zap-level"`
-	Archive      string `json:"archive"     yaml:"archive"       mapstructure:"archive"`
-	Format       string `json:"format"     yaml:"format"         mapstructure:"format"`
-	LinkName     string `json:"link-name"     yaml:"link-name"   mapstructure:"link-name"`
-	LogInConsole bool   `json:"log-in-console"     yaml:"log-in-console"    mapstructure:"log-in-console"`
-	EncodeLevel  string `json:"encode-level"     yaml:"encode-level"        mapstructure:"encode-level"`
+	Directory    string          `json:"directory"     yaml:"directory"   mapstructure:"directory"`
+	ShowLine     bool            `json:"show-line"     yaml:"show-line"   mapstructure:"show-line"`
+	ZapLevel     string          `json:"zap-level"     yaml:"zap-level"   mapstructure:"zap-level"`
+	Archive      string          `json:"archive"     yaml:"archive"       mapstructure:"archive"`
+	Format       string          `json:"format"     yaml:"format"         mapstructure:"format"`
+	LinkName     string          `json:"link-name"     yaml:"link-name"   mapstructure:"link-name"`
+	LogInConsole bool            `json:"log-in-console"     yaml:"log-in-console"    mapstructure:"log-in-console"`
+	EncodeLevel  string          `json:"encode-level"     yaml:"encode-level"        mapstructure:"encode-level"`
+	RotationTime time.Duration   `json:"rotation-time"     yaml:"rotation-time"    mapstructure:"rotation-time"` //按时间切分间隔，默认24h
+	MaxSize      int             `json:"max-size"     yaml:"max-size"              mapstructure:"max-size"`      //单个文件最大MB，0 不按大小切分
+	MaxBackups   int             `json:"max-backups"     yaml:"max-backups"        mapstructure:"max-backups"`   //保留的历史文件数量，0 不限制
+	MaxAge       time.Duration   `json:"max-age"     yaml:"max-age"                mapstructure:"max-age"`       //历史文件保留时长，默认7天，小于0不删除
+	Compress     bool            `json:"compress"     yaml:"compress"              mapstructure:"compress"`      //历史文件gzip压缩
+	ErrorArchive string          `json:"error-archive"     yaml:"error-archive"    mapstructure:"error-archive"` //非空时error及以上级别额外写入该名称的文件
+	Async        *AsyncConfig    `json:"async"     yaml:"async"    mapstructure:"async"`                         //异步写入，nil 同步写入
+	Sampling     *SamplingConfig `json:"sampling"     yaml:"sampling"    mapstructure:"sampling"`                //采样，nil 不采样
+	Sinks        []SinkConfig    `json:"sinks"     yaml:"sinks"    mapstructure:"sinks"`                         //远程输出 syslog/http
+	//命名日志，如 access/trade/mq，未配置的字段继承本配置，Archive 默认为名称
+	Loggers map[string]NamedZapConfig `json:"loggers"     yaml:"loggers"    mapstructure:"loggers"`
+}
+
+// NamedZapConfig 命名日志配置，未配置的字段继承全局配置，远程输出 Sinks 不继承
+// bool 开关通过同名的指针字段区分未配置与显式关闭，未配置时继承全局配置
+type NamedZapConfig struct {
+	ZapConfig    `yaml:",inline"    mapstructure:",squash"`
+	ShowLine     *bool `json:"show-line"     yaml:"-"   mapstructure:"show-line"`
+	LogInConsole *bool `json:"log-in-console"     yaml:"-"    mapstructure:"log-in-console"`
+	Compress     *bool `json:"compress"     yaml:"-"              mapstructure:"compress"`
+}
+
+// SamplingConfig 每个周期内相同级别、相同内容的日志，前 Initial 条全部输出，之后每 Thereafter 条输出一条
+type SamplingConfig struct {
+	Initial    int           `json:"initial"     yaml:"initial"         mapstructure:"initial"`
+	Thereafter int           `json:"thereafter"     yaml:"thereafter"   mapstructure:"thereafter"`
+	Tick       time.Duration `json:"tick"     yaml:"tick"               mapstructure:"tick"` //周期，默认1s
 }
 
 var DefaultZapConfig = ZapConfig{
@@ -41,18 +70,137 @@ var DefaultZapConfig = ZapConfig{
 }
 
 type ZapLogModule struct {
-	logger *zap.Logger
-	config ZapConfig
+	logger  *zap.Logger
+	sugar   *zap.SugaredLogger
+	config  ZapConfig
+	level   zap.AtomicLevel
+	closers []io.Closer   //创建的文件、异步写入器等，Close 时关闭
+	base    *ZapLogModule //全局日志 AddCore 前的日志，添加的 core 在其上重新组合
+	extras  []extraCore
 }
 
 var _ ILogger = (*ZapLogModule)(nil)
 
+// WithCallerSkip
+/* @Description: 返回调整了调用层级的副本，封装一层调用时传1，行号才能指向真实调用处
+ * @param skip int
+ * @return *ZapLogModule
+ */
+func (m *ZapLogModule) WithCallerSkip(skip int) *ZapLogModule {
+	logger := m.logger.WithOptions(zap.AddCallerSkip(skip))
+	return &ZapLogModule{
+		logger:  logger,
+		sugar:   logger.Sugar(),
+		config:  m.config,
+		level:   m.level,
+		closers: m.closers,
+	}
+}
+
+// With
+/* @Description: 创建带固定字段的子日志，调用层级不变
+ * @param fields ...zap.Field
+ * @return *ZapLogModule
+ */
+func (m *ZapLogModule) With(fields ...zap.Field) *ZapLogModule {
+	logger := m.logger.With(fields...)
+	return &ZapLogModule{
+		logger:  logger,
+		sugar:   logger.Sugar(),
+		config:  m.config,
+		level:   m.level,
+		closers: m.closers,
+	}
+}
+
+// Sync
+/* @Description: 写出缓冲中的日志
+ * @return error
+ */
+func (m *ZapLogModule) Sync() error {
+	return m.logger.Sync()
+}
+
+// Close
+/* @Description: 写出缓冲中的日志并关闭创建的输出，子日志共用输出，关闭后不应再使用
+ * @return error
+ */
+func (m *ZapLogModule) Close() error {
+	_ = m.logger.Sync()
+
+	var ret error
+	for _, closer := range m.closers {
+		if err := closer.Close(); nil != err && nil == ret {
+			ret = err
+		}
+	}
+	return ret
+}
+
+// Named
+/* @Description: 返回写入同一输出、logger字段为 name 的子日志
+ * @param name string
+ * @return *ZapLogModule
+ */
+func (m *ZapLogModule) Named(name string) *ZapLogModule {
+	logger := m.logger.Named(name)
+	return &ZapLogModule{
+		logger:  logger,
+		sugar:   logger.Sugar(),
+		config:  m.config,
+		level:   m.level,
+		closers: m.closers,
+	}
+}
+
+// Config
+/* @Description: 日志配置
+ * @return ZapConfig
+ */
+func (m *ZapLogModule) Config() ZapConfig {
+	return m.config
+}
+
+// Level
+/* @Description: 当前日志级别
+ * @return zapcore.Level
+ */
+func (m *ZapLogModule) Level() zapcore.Level {
+	return m.level.Level()
+}
+
+// SetLevel
+/* @Description: 运行时修改日志级别
+ * @param lvl string debug/info/warn/error/dpanic/panic/fatal
+ * @return error
+ */
+func (m *ZapLogModule) SetLevel(lvl string) error {
+	l, err := ParseLevel(lvl)
+	if nil != err {
+		return err
+	}
+	m.level.SetLevel(l)
+	return nil
+}
+
+// LevelHandler
+/* @Description: 查看/修改日志级别的http接口，GET 返回 {"level":"info"}，PUT 传入 {"level":"debug"}
+ * @return http.Handler
+ */
+func (m *ZapLogModule) LevelHandler() http.Handler {
+	return m.level
+}
+
 func (m *ZapLogModule) Info(msg string, fields ...zap.Field) {
 	m.logger.Info(msg, fields...)
 }
 
 func (m *ZapLogModule) Infof(format string, vals ...interface{}) {
+	m.sugar.Infof(format, vals...)
+}
 
+func (m *ZapLogModule) Infow(msg string, keysAndValues ...interface{}) {
+	m.sugar.Infow(msg, keysAndValues...)
 }
 
 func (m *ZapLogModule) Error(msg string, fields ...zap.Field) {
@@ -60,7 +208,11 @@ func (m *ZapLogModule) Error(msg string, fields ...zap.Field) {
 }
 
 func (m *ZapLogModule) Errorf(format string, vals ...interface{}) {
+	m.sugar.Errorf(format, vals...)
+}
 
+func (m *ZapLogModule) Errorw(msg string, keysAndValues ...interface{}) {
+	m.sugar.Errorw(msg, keysAndValues...)
 }
 
 func (m *ZapLogModule) Warn(msg string, fields ...zap.Field) {
@@ -68,7 +220,11 @@ func (m *ZapLogModule) Warn(msg string, fields ...zap.Field) {
 }
 
 func (m *ZapLogModule) Warnf(format string, vals ...interface{}) {
+	m.sugar.Warnf(format, vals...)
+}
 
+func (m *ZapLogModule) Warnw(msg string, keysAndValues ...interface{}) {
+	m.sugar.Warnw(msg, keysAndValues...)
 }
 
 func (m *ZapLogModule) Debug(msg string, fields ...zap.Field) {
@@ -76,81 +232,160 @@ func (m *ZapLogModule) Debug(msg string, fields ...zap.Field) {
 }
 
 func (m *ZapLogModule) Debugf(format string, vals ...interface{}) {
+	m.sugar.Debugf(format, vals...)
+}
 
+func (m *ZapLogModule) Debugw(msg string, keysAndValues ...interface{}) {
+	m.sugar.Debugw(msg, keysAndValues...)
 }
 
 func (m *ZapLogModule) Fatal(msg string, fields ...zap.Field) {
+	//进程退出前写出所有缓冲中的日志，远程输出限时
+	_ = SyncTimeout(sinkFlushTimeout)
 	m.logger.Fatal(msg, fields...)
 }
 
 func (m *ZapLogModule) Fatalf(format string, vals ...interface{}) {
+	//进程退出前写出所有缓冲中的日志，远程输出限时
+	_ = SyncTimeout(sinkFlushTimeout)
+	m.sugar.Fatalf(format, vals...)
+}
 
+func (m *ZapLogModule) Fatalw(msg string, keysAndValues ...interface{}) {
+	//进程退出前写出所有缓冲中的日志，远程输出限时
+	_ = SyncTimeout(sinkFlushTimeout)
+	m.sugar.Fatalw(msg, keysAndValues...)
 }
+
 func (m *ZapLogModule) DPanic(msg string, fields ...zap.Field) {
 	m.logger.DPanic(msg, fields...)
 }
 
+func (m *ZapLogModule) DPanicf(format string, vals ...interface{}) {
+	m.sugar.DPanicf(format, vals...)
+}
+
+func (m *ZapLogModule) DPanicw(msg string, keysAndValues ...interface{}) {
+	m.sugar.DPanicw(msg, keysAndValues...)
+}
+
 func (m *ZapLogModule) Panic(msg string, fields ...zap.Field) {
+	//panic 可能被恢复，只写出异步队列中的日志，不刷新远程输出
+	_ = syncAsyncWriters()
 	m.logger.Panic(msg, fields...)
 }
 
+func (m *ZapLogModule) Panicf(format string, vals ...interface{}) {
+	//panic 可能被恢复，只写出异步队列中的日志，不刷新远程输出
+	_ = syncAsyncWriters()
+	m.sugar.Panicf(format, vals...)
+}
+
+func (m *ZapLogModule) Panicw(msg string, keysAndValues ...interface{}) {
+	//panic 可能被恢复，只写出异步队列中的日志，不刷新远程输出
+	_ = syncAsyncWriters()
+	m.sugar.Panicw(msg, keysAndValues...)
+}
+
+// zapConfig 全局日志的配置
 var zapConfig ZapConfig
-var level zapcore.Level
+
+// sampledDropped 被采样丢弃的日志数量
+var sampledDropped uint64
+
+// SampledDropped
+/* @Description: 被采样丢弃的日志数量，所有日志模块合计
+ * @return uint64
+ */
+func SampledDropped() uint64 {
+	return atomic.LoadUint64(&sampledDropped)
+}
+
+// NewZapLogModule
+/* @Description: 按配置创建独立的日志模块，不影响全局日志，输出目录、格式、级别互不干扰
+ * @param config ZapConfig
+ * @return *ZapLogModule
+ * @return error
+ */
+func NewZapLogModule(config ZapConfig) (*ZapLogModule, error) {
+	return newZapLogModule(config)
+}
 
 func newZapLogModule(config ZapConfig) (*ZapLogModule, error) {
-	logger, err := newZapLogger(config)
+	logger, lvl, closers, err := newZapLogger(config)
 	if nil != err {
 		return nil, err
 	}
 	ret := ZapLogModule{
-		logger: logger,
-		config: config,
+		logger:  logger,
+		sugar:   logger.Sugar(),
+		config:  config,
+		level:   lvl,
+		closers: closers,
 	}
 
 	return &ret, nil
 }
 
-func newZapLogger(config ZapConfig) (logger *zap.Logger, err error) {
-	zapConfig = config
-	if err = fileutils.CreateDirectoryIfNotExist(zapConfig.Directory, os.ModePerm); nil != err {
-		return nil, err
+func newZapLogger(config ZapConfig) (logger *zap.Logger, lvl zap.AtomicLevel, closers []io.Closer, err error) {
+	if err = fileutils.CreateDirectoryIfNotExist(config.Directory, os.ModePerm); nil != err {
+		return nil, lvl, nil, err
 	}
 
-	// 初始化配置文件的Level
-	switch zapConfig.ZapLevel {
-	case "debug":
-		level = zap.DebugLevel
-	case "info":
-		level = zap.InfoLevel
-	case "warn":
-		level = zap.WarnLevel
-	case "error":
-		level = zap.ErrorLevel
-	case "dPanic":
-		level = zap.DPanicLevel
-	case "panic":
-		level = zap.PanicLevel
-	case "fatal":
-		level = zap.FatalLevel
-	default:
-		level = zap.InfoLevel
+	// 初始化配置文件的Level，配置错误时默认info
+	initLevel, err := ParseLevel(config.ZapLevel)
+	if nil != err {
+		initLevel = zap.InfoLevel
 	}
+	lvl = zap.NewAtomicLevelAt(initLevel)
 
-	if level == zap.DebugLevel || level == zap.ErrorLevel {
-		logger = zap.New(getEncoderCore(), zap.AddStacktrace(level))
+	core, closers, err := getEncoderCore(config, lvl)
+	if nil != err {
+		return nil, lvl, nil, err
+	}
+	if nil != config.Sampling && config.Sampling.Initial > 0 {
+		tick := config.Sampling.Tick
+		if tick <= 0 {
+			tick = time.Second
+		}
+		core = zapcore.NewSamplerWithOptions(core, tick, config.Sampling.Initial, config.Sampling.Thereafter,
+			zapcore.SamplerHook(func(entry zapcore.Entry, dec zapcore.SamplingDecision) {
+				if dec&zapcore.LogDropped > 0 {
+					atomic.AddUint64(&sampledDropped, 1)
+				}
+			}))
+	}
+
+	if initLevel == zap.DebugLevel || initLevel == zap.ErrorLevel {
+		logger = zap.New(core, zap.AddStacktrace(initLevel))
 	} else {
-		logger = zap.New(getEncoderCore())
+		logger = zap.New(core)
 	}
-	if zapConfig.ShowLine {
+	if config.ShowLine {
 		logger = logger.WithOptions(zap.AddCaller())
-		logger = logger.WithOptions(zap.AddCallerSkip(2))
 	}
+	//ZapLogModule 的方法多一层调用
+	logger = logger.WithOptions(zap.AddCallerSkip(1))
+
+	return logger, lvl, closers, nil
+}
 
-	return logger, nil
+// ParseLevel
+/* @Description: 解析日志级别字符串，不区分大小写
+ * @param lvl string
+ * @return zapcore.Level
+ * @return error
+ */
+func ParseLevel(lvl string) (zapcore.Level, error) {
+	var ret zapcore.Level
+	if err := ret.UnmarshalText([]byte(strings.ToLower(strings.TrimSpace(lvl)))); nil != err {
+		return zap.InfoLevel, fmt.Errorf("invalid log level: %s", lvl)
+	}
+	return ret, nil
 }
 
 // getEncoderConfig 获取zapcore.EncoderConfig
-func getEncoderConfig() (config zapcore.EncoderConfig) {
+func getEncoderConfig(zapConfig ZapConfig) (config zapcore.EncoderConfig) {
 	config = zapcore.EncoderConfig{
 		MessageKey:     "message",
 		LevelKey:       "level",
@@ -180,19 +415,61 @@ func getEncoderConfig() (config zapcore.EncoderConfig) {
 }
 
 // getEncoder 获取zapcore.Encoder
-func getEncoder() zapcore.Encoder {
-	if "json" == zapConfig.Format {
-		return zapcore.NewJSONEncoder(getEncoderConfig())
+func getEncoder(config ZapConfig) zapcore.Encoder {
+	if "json" == config.Format {
+		return zapcore.NewJSONEncoder(getEncoderConfig(config))
 	}
-	return zapcore.NewConsoleEncoder(getEncoderConfig())
+	return zapcore.NewConsoleEncoder(getEncoderConfig(config))
 }
-func getEncoderCore() (core zapcore.Core) {
-	writer, err := GetWriteSyncer() // 使用file-rotatelogs进行日志分割
+
+func getEncoderCore(config ZapConfig, lvl zapcore.LevelEnabler) (core zapcore.Core, closers []io.Closer, err error) {
+	defer func() {
+		if nil != err {
+			closeAll(closers)
+			closers = nil
+		}
+	}()
+
+	writer, writerClosers, err := newWriteSyncer(config, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get write syncer failed, err: %s", err.Error())
+	}
+	closers = append(closers, writerClosers...)
+	core = zapcore.NewCore(getEncoder(config), writer, lvl)
+
+	if len(config.Sinks) > 0 {
+		sinkCores, sinkClosers, err := newSinkCores(config.Sinks, lvl)
+		if nil != err {
+			return nil, closers, fmt.Errorf("get sink core failed, err: %s", err.Error())
+		}
+		closers = append(closers, sinkClosers...)
+		core = zapcore.NewTee(append([]zapcore.Core{core}, sinkCores...)...)
+	}
+
+	if "" == config.ErrorArchive {
+		return core, closers, nil
+	}
+
+	//错误日志单独一份文件，便于排查
+	errConfig := config
+	errConfig.Archive = config.ErrorArchive
+	errConfig.LogInConsole = false
+	errWriter, errClosers, err := newWriteSyncer(errConfig, true)
 	if err != nil {
-		fmt.Printf("Get Write Syncer Failed err:%v", err.Error())
-		return
+		return nil, closers, fmt.Errorf("get error write syncer failed, err: %s", err.Error())
+	}
+	closers = append(closers, errClosers...)
+	errLevel := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l >= zap.ErrorLevel && lvl.Enabled(l)
+	})
+
+	return zapcore.NewTee(core, zapcore.NewCore(getEncoder(config), errWriter, errLevel)), closers, nil
+}
+
+func closeAll(closers []io.Closer) {
+	for _, closer := range closers {
+		_ = closer.Close()
 	}
-	return zapcore.NewCore(getEncoder(), writer, level)
 }
 
 // 自定义日志输出时间格式
@@ -200,26 +477,61 @@ func CustomTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
 	enc.AppendString(t.Format("2006-01-02 15:04:05.000"))
 }
 
-// zap logger中加入file-rotatelogs
+// GetWriteSyncer 全局日志配置的输出
 func GetWriteSyncer() (zapcore.WriteSyncer, error) {
-	var filePath string
-	filePath = path.Join(zapConfig.Directory, zapConfig.Archive+"-%Y-%m-%d.log")
+	globalMtx.Lock()
+	config := zapConfig
+	globalMtx.Unlock()
+	return NewWriteSyncer(config)
+}
+
+// NewWriteSyncer 按配置切分日志文件，由调用方关闭，不影响 LogFile/TailLog 读取的日志文件
+func NewWriteSyncer(zapConfig ZapConfig) (zapcore.WriteSyncer, error) {
+	writer, _, err := newWriteSyncer(zapConfig, false)
+	return writer, err
+}
 
-	var linkName rotatelogs.Option
+// newWriteSyncer 同时返回需要关闭的输出，按关闭顺序排列，owned 为日志自身的输出时登记到 LogFile
+func newWriteSyncer(zapConfig ZapConfig, owned bool) (zapcore.WriteSyncer, []io.Closer, error) {
+	var linkName string
 	if zapConfig.Archive == "" {
-		linkName = rotatelogs.WithLinkName(zapConfig.LinkName)
-	} else {
-		linkName = rotatelogs.WithLinkName(zapConfig.LinkName + "_" + zapConfig.Archive)
+		linkName = zapConfig.LinkName
+	} else if "" != zapConfig.LinkName {
+		linkName = zapConfig.LinkName + "_" + zapConfig.Archive
+	}
+
+	archive := zapConfig.Archive
+	if "" == archive {
+		archive = "log"
 	}
 
-	fileWriter, err := rotatelogs.New(
-		filePath,
-		linkName,
-		rotatelogs.WithMaxAge(7*24*time.Hour),
-		rotatelogs.WithRotationTime(24*time.Hour),
-	)
+	fileWriter, err := NewRotateWriter(RotateConfig{
+		Directory:    zapConfig.Directory,
+		Archive:      archive,
+		LinkName:     linkName,
+		RotationTime: zapConfig.RotationTime,
+		MaxSize:      int64(zapConfig.MaxSize) << 20,
+		MaxBackups:   zapConfig.MaxBackups,
+		MaxAge:       zapConfig.MaxAge,
+		Compress:     zapConfig.Compress,
+	})
+	if nil != err {
+		return nil, nil, err
+	}
+	if owned {
+		registerLogFile(archive, fileWriter)
+	}
+	closers := []io.Closer{fileWriter}
+
+	var writer zapcore.WriteSyncer = fileWriter
 	if zapConfig.LogInConsole {
-		return zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout), zapcore.AddSync(fileWriter)), err
+		writer = zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout), fileWriter)
+	}
+	if nil != zapConfig.Async {
+		asyncWriter := NewAsyncWriter(writer, *zapConfig.Async)
+		//先写出队列再关闭文件
+		closers = []io.Closer{asyncWriter, fileWriter}
+		writer = asyncWriter
 	}
-	return zapcore.AddSync(fileWriter), err
+	return writer, closers, nil
 }
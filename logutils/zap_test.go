@@ -0,0 +1,509 @@
+package logutils
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: zap_test
+ * @Date: 2026-10-19 6:20 下午
+ */
+
+func initTestLogger(t *testing.T) string {
+	dir := t.TempDir()
+	cfg := DefaultZapConfig
+	cfg.Directory = dir
+	cfg.LinkName = path.Join(dir, "latest_log")
+	cfg.Format = "json"
+	cfg.LogInConsole = false
+	cfg.ZapLevel = "debug"
+	InitLogger(cfg)
+	return dir
+}
+
+func readTestLogs(t *testing.T, dir string) []map[string]interface{} {
+	files, err := filepath.Glob(path.Join(dir, "*.log"))
+	if nil != err || len(files) == 0 {
+		t.Fatalf("log file not found, err: %v", err)
+	}
+
+	f, err := os.Open(files[0])
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	ret := make([]map[string]interface{}, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		entry := map[string]interface{}{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); nil != err {
+			t.Fatal(err)
+		}
+		ret = append(ret, entry)
+	}
+	return ret
+}
+
+func currentLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+func Test_FormatAndCaller(t *testing.T) {
+	dir := initTestLogger(t)
+
+	lines := make([]int, 0, 4)
+	Infof("hello %s %d", "world", 1)
+	lines = append(lines, currentLine()-1)
+	Warnw("kv", "key", "value", "n", 2)
+	lines = append(lines, currentLine()-1)
+	Error("plain")
+	lines = append(lines, currentLine()-1)
+	Logger().Debugf("direct %d", 3)
+	lines = append(lines, currentLine()-1)
+
+	entries := readTestLogs(t, dir)
+	if len(entries) != 4 {
+		t.Fatalf("expect 4 entries, got %d", len(entries))
+	}
+
+	if entries[0]["message"] != "hello world 1" {
+		t.Errorf("Infof message: %v", entries[0]["message"])
+	}
+	if entries[1]["key"] != "value" || entries[1]["n"] != float64(2) {
+		t.Errorf("Warnw fields: %v", entries[1])
+	}
+	if entries[3]["message"] != "direct 3" {
+		t.Errorf("Logger().Debugf message: %v", entries[3]["message"])
+	}
+
+	for i, entry := range entries {
+		expect := "logutils/zap_test.go:" + strconv.Itoa(lines[i])
+		if entry["caller"] != expect {
+			t.Errorf("entry %d caller %v, expect %s", i, entry["caller"], expect)
+		}
+	}
+}
+
+func Test_SetLevel(t *testing.T) {
+	dir := initTestLogger(t)
+	cfg := DefaultZapConfig
+	boundLevelOnce = sync.Once{}
+	var hooks []func()
+	register := func(fn func()) {
+		hooks = append(hooks, fn)
+	}
+	BindLevel(&cfg, register)
+	BindLevel(&cfg, register)
+	if 1 != len(hooks) {
+		t.Fatalf("expect hook registered once, got %d", len(hooks))
+	}
+
+	Debug("debug 1")
+	if err := SetLevel("warn"); nil != err {
+		t.Fatal(err)
+	}
+	Info("info dropped")
+	Warn("warn 1")
+
+	if err := SetLevel("verbose"); nil == err {
+		t.Error("expect invalid level error")
+	}
+
+	cfg.ZapLevel = "info"
+	hooks[0]()
+	Info("info 2")
+
+	entries := readTestLogs(t, dir)
+	messages := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		messages = append(messages, entry["message"].(string))
+	}
+
+	expect := []string{"debug 1", "warn 1", "log level changed", "info 2"}
+	if strings.Join(messages, ",") != strings.Join(expect, ",") {
+		t.Errorf("messages %v, expect %v", messages, expect)
+	}
+}
+
+func Test_ContextLogger(t *testing.T) {
+	dir := initTestLogger(t)
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = WithTraceID(ctx, "trace-1")
+	FromContext(ctx).Info("with ids")
+	FromContext(context.Background()).Info("without ids")
+
+	ids := ContextIDs(ctx)
+	if ids[FieldRequestID] != "req-1" || ids[FieldTraceID] != "trace-1" {
+		t.Errorf("context ids: %v", ids)
+	}
+
+	entries := readTestLogs(t, dir)
+	if len(entries) != 2 {
+		t.Fatalf("expect 2 entries, got %d", len(entries))
+	}
+	if entries[0][FieldRequestID] != "req-1" || entries[0][FieldTraceID] != "trace-1" {
+		t.Errorf("context fields: %v", entries[0])
+	}
+	if _, ok := entries[1][FieldRequestID]; ok {
+		t.Errorf("unexpected request id: %v", entries[1])
+	}
+}
+
+func Test_GinMiddleware(t *testing.T) {
+	initTestLogger(t)
+	gin.SetMode(gin.TestMode)
+
+	var ids, derived, kept map[string]string
+	var stored ILogger
+	logger := Logger()
+	engine := gin.New()
+	engine.Use(GinMiddleware())
+	engine.GET("/", func(c *gin.Context) {
+		ids = ContextIDs(c)
+		timeout, cancel := context.WithTimeout(c, time.Second)
+		defer cancel()
+		derived = ContextIDs(timeout)
+		ctx := ToContext(c, logger)
+		stored = FromContext(ctx)
+		kept = ContextIDs(ctx)
+	})
+
+	long := strings.Repeat("a", maxContextIDLen+1)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderRequestID, long)
+	req.Header.Set(HeaderTraceID, long)
+	req.Header.Set(HeaderUserID, "user-1")
+	rsp := httptest.NewRecorder()
+	engine.ServeHTTP(rsp, req)
+
+	requestID := rsp.Header().Get(HeaderRequestID)
+	if "" == requestID || long == requestID || ids[FieldRequestID] != requestID {
+		t.Errorf("expect regenerated request id, got %q, ids %v", requestID, ids)
+	}
+	if _, ok := ids[FieldTraceID]; ok || ids[FieldUserID] != "user-1" {
+		t.Errorf("context ids: %v", ids)
+	}
+	if derived[FieldRequestID] != requestID || kept[FieldRequestID] != requestID {
+		t.Errorf("derived context ids: %v, %v", derived, kept)
+	}
+	if stored != logger {
+		t.Error("expect logger stored from gin context")
+	}
+}
+
+func Test_NamedLogger(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DefaultZapConfig
+	cfg.Directory = dir
+	cfg.LinkName = path.Join(dir, "latest_log")
+	cfg.Format = "json"
+	cfg.LogInConsole = false
+	cfg.Loggers = map[string]NamedZapConfig{
+		"trade": {ZapConfig: ZapConfig{Directory: path.Join(dir, "trade"), ZapLevel: "warn"}},
+	}
+	InitLogger(cfg)
+
+	Named("trade").Info("trade info dropped")
+	Named("trade").Warn("trade warn")
+	Named("mq").Info("mq info")
+	Info("global info")
+
+	global := readTestLogs(t, dir)
+	if len(global) != 2 || global[0]["logger"] != "mq" || global[1]["message"] != "global info" {
+		t.Errorf("global entries: %v", global)
+	}
+
+	trade := readTestLogs(t, path.Join(dir, "trade"))
+	if len(trade) != 1 || trade[0]["message"] != "trade warn" || trade[0]["logger"] != "trade" {
+		t.Errorf("trade entries: %v", trade)
+	}
+
+	if err := SetNamedLevel("trade", "info"); nil != err {
+		t.Fatal(err)
+	}
+	if err := SetNamedLevel("unknown", "info"); nil == err {
+		t.Error("expect unknown logger error")
+	}
+
+	//替换时关闭原日志的文件
+	old := namedModule("trade")
+	if _, err := RegisterLogger("trade", old.Config()); nil != err {
+		t.Fatal(err)
+	}
+	file := old.closers[0].(*RotateWriter)
+	file.mtx.Lock()
+	closed := nil == file.file
+	file.mtx.Unlock()
+	if !closed {
+		t.Error("expect replaced logger closed")
+	}
+}
+
+func Test_NamedModuleNotInit(t *testing.T) {
+	initTestLogger(t)
+	global := globalModule()
+	loggerModule.Store((*ZapLogModule)(nil))
+	defer loggerModule.Store(global)
+
+	module := NamedModule(ModuleGorm)
+	if nil == module || "" != module.Config().Directory {
+		t.Fatal("expect stderr logger before init")
+	}
+	module.Debug("dropped before init")
+}
+
+func Test_InheritZapConfig(t *testing.T) {
+	parent := DefaultZapConfig
+	parent.Compress = true
+	parent.ErrorArchive = "error"
+	parent.Sinks = []SinkConfig{{Type: "http"}}
+
+	config := inheritZapConfig(parent, NamedZapConfig{}, "trade")
+	if !config.LogInConsole || !config.Compress || !config.ShowLine || 0 != len(config.Sinks) || "trade-error" != config.ErrorArchive {
+		t.Errorf("inherited config: %+v", config)
+	}
+
+	//显式关闭的开关不被全局配置覆盖
+	var named NamedZapConfig
+	if err := json.Unmarshal([]byte(`{"log-in-console":false,"compress":false,"error-archive":"trade-err"}`), &named); nil != err {
+		t.Fatal(err)
+	}
+	config = inheritZapConfig(parent, named, "trade")
+	if config.LogInConsole || config.Compress || !config.ShowLine || "trade-err" != config.ErrorArchive {
+		t.Errorf("overridden config: %+v", config)
+	}
+}
+
+func Test_RedirectStdLog(t *testing.T) {
+	dir := initTestLogger(t)
+	restore := RedirectStdLog()
+	defer restore()
+
+	log.Printf("[ERR] raft: %s", "failed")
+	line := currentLine() - 1
+	log.Println("plain")
+
+	entries := readTestLogs(t, dir)
+	if len(entries) != 2 {
+		t.Fatalf("expect 2 entries, got %d", len(entries))
+	}
+	if !strings.Contains(entries[0]["level"].(string), "error") || entries[0]["message"] != "raft: failed" {
+		t.Errorf("infer level: %v", entries[0])
+	}
+	if expect := "logutils/zap_test.go:" + strconv.Itoa(line); entries[0]["caller"] != expect {
+		t.Errorf("caller %v, expect %s", entries[0]["caller"], expect)
+	}
+	if !strings.Contains(entries[1]["level"].(string), "info") || entries[1]["message"] != "plain" {
+		t.Errorf("default level: %v", entries[1])
+	}
+}
+
+func Test_Limited(t *testing.T) {
+	dir := initTestLogger(t)
+
+	for i := 0; i < 5; i++ {
+		LimitedEvery("test", 100*time.Millisecond).Warn("repeat")
+	}
+	time.Sleep(150 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		LimitedEvery("test", 100*time.Millisecond).Warn("repeat")
+	}
+	if LimitedEvery("test", 100*time.Millisecond) != LimitedEvery("test", 100*time.Millisecond) {
+		t.Error("expect limited logger cached per key")
+	}
+	FlushLimited()
+
+	entries := readTestLogs(t, dir)
+	if len(entries) != 3 {
+		t.Fatalf("expect 3 entries, got %v", entries)
+	}
+	if _, ok := entries[0]["suppressed"]; ok || !strings.HasPrefix(entries[0]["caller"].(string), "logutils/zap_test.go:") {
+		t.Errorf("first entry: %v", entries[0])
+	}
+	if entries[1]["suppressed"] != float64(4) {
+		t.Errorf("second entry: %v", entries[1])
+	}
+	if entries[2]["suppressed"] != float64(2) || entries[2]["limit_key"] != "test" {
+		t.Errorf("summary entry: %v", entries[2])
+	}
+}
+
+func Test_Sampling(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DefaultZapConfig
+	cfg.Directory = dir
+	cfg.LinkName = path.Join(dir, "latest_log")
+	cfg.Format = "json"
+	cfg.LogInConsole = false
+	cfg.Sampling = &SamplingConfig{Initial: 2, Thereafter: 5, Tick: time.Minute}
+	InitLogger(cfg)
+
+	before := SampledDropped()
+	for i := 0; i < 12; i++ {
+		Info("hot path")
+	}
+
+	//前2条，之后第5、10条
+	if entries := readTestLogs(t, dir); len(entries) != 4 {
+		t.Errorf("expect 4 entries, got %d", len(entries))
+	}
+	if dropped := SampledDropped() - before; dropped != 8 {
+		t.Errorf("dropped %d, expect 8", dropped)
+	}
+}
+
+type slowSyncer struct {
+	mtx   sync.Mutex
+	lines []string
+	block chan struct{}
+}
+
+func (s *slowSyncer) Write(p []byte) (int, error) {
+	<-s.block
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.lines = append(s.lines, string(p))
+	return len(p), nil
+}
+
+func (s *slowSyncer) Sync() error {
+	return nil
+}
+
+func Test_AsyncWriter(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DefaultZapConfig
+	cfg.Directory = dir
+	cfg.LinkName = path.Join(dir, "latest_log")
+	cfg.Format = "json"
+	cfg.LogInConsole = false
+	cfg.Async = &AsyncConfig{FlushInterval: time.Hour}
+	InitLogger(cfg)
+
+	for i := 0; i < 100; i++ {
+		Info("async", zap.Int("i", i))
+	}
+	if err := Sync(); nil != err {
+		t.Fatal(err)
+	}
+	if entries := readTestLogs(t, dir); len(entries) != 100 || entries[99]["i"] != float64(99) {
+		t.Errorf("expect 100 entries after sync, got %d", len(entries))
+	}
+
+	//drop 策略下队列满了丢弃并计数
+	out := &slowSyncer{block: make(chan struct{})}
+	w := NewAsyncWriter(out, AsyncConfig{BufferSize: 2, Overflow: OverflowDrop, WriteBuffer: 1})
+	for i := 0; i < 10; i++ {
+		_, _ = w.Write([]byte("line\n"))
+	}
+	close(out.block)
+	if err := w.Close(); nil != err {
+		t.Fatal(err)
+	}
+	if w.Dropped() == 0 || uint64(len(out.lines))+w.Dropped() != 10 {
+		t.Errorf("written %d, dropped %d", len(out.lines), w.Dropped())
+	}
+	//关闭后不再写入实际输出
+	written, dropped := len(out.lines), w.Dropped()
+	if _, err := w.Write([]byte("line\n")); nil == err || written != len(out.lines) || dropped+1 != w.Dropped() {
+		t.Errorf("write after close should be dropped, err %v, written %d", err, len(out.lines))
+	}
+
+	//block 策略下关闭时等待中的写入全部写出
+	out = &slowSyncer{block: make(chan struct{})}
+	w = NewAsyncWriter(out, AsyncConfig{BufferSize: 1, WriteBuffer: 1})
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = w.Write([]byte("line\n"))
+		}()
+	}
+	closed := make(chan error, 1)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		closed <- w.Close()
+	}()
+	time.Sleep(40 * time.Millisecond)
+	close(out.block)
+	wg.Wait()
+	select {
+	case err := <-closed:
+		if nil != err {
+			t.Fatal(err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("close blocked")
+	}
+	if 5 != len(out.lines) {
+		t.Errorf("written %d, expect 5", len(out.lines))
+	}
+}
+
+type testCloser struct {
+	closed int32
+}
+
+func (c *testCloser) Close() error {
+	atomic.StoreInt32(&c.closed, 1)
+	return nil
+}
+
+func Test_ReplaceCore(t *testing.T) {
+	initTestLogger(t)
+
+	//输出时并发添加 core
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			Info("concurrent")
+		}
+	}()
+	first, firstLogs := observer.New(zap.InfoLevel)
+	firstCloser := &testCloser{}
+	if err := ReplaceCore("test", first, firstCloser); nil != err {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	second, secondLogs := observer.New(zap.InfoLevel)
+	if err := ReplaceCore("test", second, nil); nil != err {
+		t.Fatal(err)
+	}
+	if 1 != atomic.LoadInt32(&firstCloser.closed) {
+		t.Error("replaced closer should be closed")
+	}
+
+	before := firstLogs.Len()
+	Info("after replace")
+	log.Println("std after replace")
+	if before != firstLogs.Len() || 2 != secondLogs.Len() {
+		t.Errorf("expect only the new core written, first %d->%d, second %d", before, firstLogs.Len(), secondLogs.Len())
+	}
+}
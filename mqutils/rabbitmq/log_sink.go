@@ -0,0 +1,68 @@
+package rabbitmq
+
+/**
+ * @Author: lee
+ * @Description: 日志远程输出到 rabbitmq exchange，配合 logutils.AddSink 使用
+ * @File: log_sink
+ * @Date: 2026-10-20 3:40 下午
+ */
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/0DeOrg/gutils/logutils"
+	"github.com/streadway/amqp"
+)
+
+type LogSink struct {
+	rq         *RabbitMq
+	exchange   string
+	routingKey string
+}
+
+var _ logutils.Sink = (*LogSink)(nil)
+
+// NewLogSink
+/* @Description: 创建输出到 exchange 的日志 sink，每批日志一条消息，内容为每行一条的json
+ * 如 logutils.AddSink(rabbitmq.NewLogSink(rq, "logs", "app"), logutils.SinkConfig{SpoolDir: "log/spool"})
+ * @param rq *RabbitMq 建议使用 reliable 模式，确认失败的批次会重试或落盘
+ * @param exchange string
+ * @param routingKey string
+ * @return *LogSink
+ */
+func NewLogSink(rq *RabbitMq, exchange string, routingKey string) *LogSink {
+	return &LogSink{
+		rq:         rq,
+		exchange:   exchange,
+		routingKey: routingKey,
+	}
+}
+
+func (s *LogSink) Name() string {
+	return "rabbitmq-" + s.exchange + "-" + s.routingKey
+}
+
+func (s *LogSink) Send(entries []logutils.SinkEntry) error {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		buf.Write(e.Data)
+		buf.WriteByte('\n')
+	}
+
+	_, _, err := s.rq.Publish(&PublishContent{
+		ExchangeName: s.exchange,
+		RoutingKey:   s.routingKey,
+		Content:      buf.Bytes(),
+		ContentType:  "application/x-ndjson",
+		Headers:      amqp.Table{"count": int32(len(entries))},
+	})
+	if nil != err {
+		return fmt.Errorf("LogSink|Publish err: %s", err.Error())
+	}
+	return nil
+}
+
+// Close 连接由调用方管理，不在这里关闭
+func (s *LogSink) Close() error {
+	return nil
+}
@@ -8,6 +8,7 @@ package rabbitmq
  */
 
 import (
+	"context"
 	"fmt"
 	"github.com/0DeOrg/gutils/dumputils"
 	"github.com/0DeOrg/gutils/logutils"
@@ -110,7 +111,7 @@ func (rq *RabbitMq) Process() {
 				for {
 					_, _, err := rq.Publish(content)
 					if nil != err {
-						logutils.Error("RabbitMq|Process Publish fatal", zap.Any("content", content), zap.Error(err))
+						logutils.Limited("RabbitMq publish:"+content.ExchangeName).Error("RabbitMq|Process Publish fatal", zap.Any("content", content), zap.Error(err))
 						//当达到最大堵塞数量时，不堵塞了 防止影响正常流程，mq推送暂时就不保证了
 						if len(rq.publishCh) > max_traffic_count {
 							logutils.Warn("RabbitMq publish has reach max traffic count", zap.Int("traffic", len(rq.publishCh)))
@@ -145,6 +146,31 @@ func (rq *RabbitMq) PublishContent(exchangeName string, routingKey string, conte
 	rq.publishCh <- publishContent
 }
 
+// PublishContentWithContext
+/* @Description: 同PublishContent，上下文中的 request_id/trace_id 等写入消息头
+ * @param ctx context.Context
+ * @param exchangeName string
+ * @param routingKey string
+ * @param content []byte
+ */
+func (rq *RabbitMq) PublishContentWithContext(ctx context.Context, exchangeName string, routingKey string, content []byte) {
+	publishContent := &PublishContent{
+		ExchangeName: exchangeName,
+		RoutingKey:   routingKey,
+		Content:      content,
+	}
+
+	ids := logutils.ContextIDs(ctx)
+	if len(ids) > 0 {
+		publishContent.Headers = amqp.Table{}
+		for k, v := range ids {
+			publishContent.Headers[k] = v
+		}
+	}
+
+	rq.publishCh <- publishContent
+}
+
 func (rq *RabbitMq) Publish(content *PublishContent) (confirmed bool, deliveryTag uint64, err error) {
 	chProxy := rq.getChannelProxy()
 	if nil == chProxy {
@@ -53,6 +53,7 @@ type PublishContent struct {
 	RoutingKey   string
 	Content      []byte
 	ContentType  string
+	Headers      amqp.Table //消息头，可携带 request_id/trace_id 等跟踪字段
 }
 
 type connectionProxy struct {
@@ -297,6 +298,7 @@ func (p *channelProxy) Publish(content *PublishContent) (confirmed bool, deliver
 			ContentType: contentType,
 			Timestamp:   time.Now(),
 			Body:        content.Content,
+			Headers:     content.Headers,
 		})
 	return
 }
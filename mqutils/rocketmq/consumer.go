@@ -21,6 +21,7 @@ type ConsumerPushProxy struct {
 }
 
 func NewConsumerPushProxy(groupName string, m consumer.MessageModel, cfg *RocketMQConfig) (*ConsumerPushProxy, error) {
+	useRLogger()
 	c, err := consumer.NewPushConsumer(
 		consumer.WithGroupName(groupName),
 		consumer.WithNameServer(cfg.NameServers),
@@ -0,0 +1,111 @@
+package rocketmq
+
+/**
+ * @Author: lee
+ * @Description: rlog.Logger 适配，rocketmq 客户端内部日志写入 logutils
+ * @File: logger
+ * @Date: 2026-10-19 11:10 下午
+ */
+
+import (
+	"fmt"
+	"github.com/0DeOrg/gutils/logutils"
+	"github.com/apache/rocketmq-client-go/v2/rlog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sort"
+	"sync"
+)
+
+type rLogger struct {
+	level zapcore.Level
+}
+
+var _ rlog.Logger = (*rLogger)(nil)
+
+var rlogOnce sync.Once
+
+// NewRLogger
+/* @Description: 创建写入 logutils 的 rocketmq 日志，在日志配置 loggers 中配置 rocketmq 即可输出到单独的文件
+ * 每次输出时获取日志，日志初始化前输出到标准错误
+ * @return rlog.Logger
+ */
+func NewRLogger() rlog.Logger {
+	return &rLogger{
+		//rocketmq 客户端info日志很多，默认只输出warn以上
+		level: zap.WarnLevel,
+	}
+}
+
+// useRLogger 创建生产者/消费者时替换 rocketmq 默认的日志，只替换一次
+func useRLogger() {
+	rlogOnce.Do(func() {
+		rlog.SetLogger(NewRLogger())
+	})
+}
+
+func (l *rLogger) Debug(msg string, fields map[string]interface{}) {
+	if l.level.Enabled(zap.DebugLevel) {
+		l.module().Debug(msg, toZapFields(fields)...)
+	}
+}
+
+func (l *rLogger) Info(msg string, fields map[string]interface{}) {
+	if l.level.Enabled(zap.InfoLevel) {
+		l.module().Info(msg, toZapFields(fields)...)
+	}
+}
+
+func (l *rLogger) Warning(msg string, fields map[string]interface{}) {
+	if l.level.Enabled(zap.WarnLevel) {
+		l.module().Warn(msg, toZapFields(fields)...)
+	}
+}
+
+func (l *rLogger) Error(msg string, fields map[string]interface{}) {
+	l.module().Error(msg, toZapFields(fields)...)
+}
+
+// Fatal rocketmq 内部的fatal不能让进程退出，按error输出
+func (l *rLogger) Fatal(msg string, fields map[string]interface{}) {
+	l.module().Error(msg, toZapFields(fields)...)
+}
+
+// module rLogger 的方法多一层调用
+func (l *rLogger) module() *logutils.ZapLogModule {
+	return logutils.NamedModule(logutils.ModuleRocketMQ).WithCallerSkip(1)
+}
+
+// Level rlog.SetLogLevel 调用，取值 debug/info/warn/error/fatal
+func (l *rLogger) Level(level string) {
+	if lvl, err := logutils.ParseLevel(level); nil == err {
+		l.level = lvl
+	}
+}
+
+// OutputPath 输出由 logutils 配置决定，忽略
+func (l *rLogger) OutputPath(path string) (err error) {
+	return nil
+}
+
+func toZapFields(fields map[string]interface{}) []zap.Field {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	ret := make([]zap.Field, 0, len(fields))
+	for _, k := range keys {
+		if err, ok := fields[k].(error); ok {
+			ret = append(ret, zap.NamedError(k, err))
+			continue
+		}
+		if s, ok := fields[k].(fmt.Stringer); ok {
+			ret = append(ret, zap.Stringer(k, s))
+			continue
+		}
+		ret = append(ret, zap.Any(k, fields[k]))
+	}
+	return ret
+}
@@ -33,6 +33,7 @@ type ProducerProxy struct {
 }
 
 func NewProducerProxy(cfg *RocketMQConfig, idx int) (*ProducerProxy, error) {
+	useRLogger()
 	instName := strconv.Itoa(os.Getpid()) + "_" + strconv.Itoa(idx)
 	groupName := cfg.ProducerGroup
 	p, err := rocketmq.NewProducer(
@@ -133,7 +134,7 @@ func (proxy *ProducerProxy) goSendThread(ctx context.Context, ch chan []*primiti
 					failed += len(batch)
 					failSend++
 					time.Sleep(50 * time.Millisecond)
-					logutils.Error("ProducerProxy|SendSync err", zap.Error(err), zap.Int("idx", proxy.idx), zap.String("topic", batch[0].Topic))
+					logutils.Limited("ProducerProxy send:"+batch[0].Topic).Error("ProducerProxy|SendSync err", zap.Error(err), zap.Int("idx", proxy.idx), zap.String("topic", batch[0].Topic))
 				} else {
 					success += len(batch)
 					successSend++
@@ -285,6 +286,9 @@ func formatContent(content *PublishContent) *primitive.Message {
 	if len(content.Keys) > 0 {
 		msg.WithKeys(content.Keys)
 	}
+	if len(content.Properties) > 0 {
+		msg.WithProperties(content.Properties)
+	}
 
 	return msg
 }
@@ -78,6 +78,29 @@ func (mq *RocketMQ) PublishContent(content *PublishContent) {
 	mq.chContent <- content
 }
 
+// PublishContentWithContext
+/* @Description: 同PublishContent，上下文中的 request_id/trace_id 等写入消息属性
+ * @param ctx context.Context
+ * @param content *PublishContent
+ */
+func (mq *RocketMQ) PublishContentWithContext(ctx context.Context, content *PublishContent) {
+	ids := logutils.ContextIDs(ctx)
+	if len(ids) > 0 {
+		props := make(map[string]string, len(content.Properties)+len(ids))
+		for k, v := range content.Properties {
+			props[k] = v
+		}
+		for k, v := range ids {
+			if _, ok := props[k]; !ok {
+				props[k] = v
+			}
+		}
+		content.Properties = props
+	}
+
+	mq.chContent <- content
+}
+
 func (mq *RocketMQ) goSendThread() {
 	defer dumputils.HandlePanic()
 
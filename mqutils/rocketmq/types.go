@@ -16,10 +16,11 @@ type RocketMQConfig struct {
 }
 
 type PublishContent struct {
-	Topic string
-	Tag   string
-	Body  []byte
-	Keys  []string
+	Topic      string
+	Tag        string
+	Body       []byte
+	Keys       []string
+	Properties map[string]string //消息属性，可携带 request_id/trace_id 等跟踪字段
 }
 
 type ReqPing struct {
@@ -0,0 +1,70 @@
+package nacosutils
+
+/**
+ * @Author: lee
+ * @Description: nacos 客户端日志适配，日志初始化后写入 logutils
+ * @File: logger
+ * @Date: 2026-10-19 11:30 下午
+ */
+
+import (
+	"fmt"
+	"github.com/0DeOrg/gutils/logutils"
+	nacoslogger "github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+)
+
+type nacosLogger struct {
+	module *logutils.ZapLogModule
+}
+
+var _ nacoslogger.Logger = (*nacosLogger)(nil)
+
+// NewNacosLogger
+/* @Description: 创建写入 logutils 的 nacos 日志，在日志配置 loggers 中配置 nacos 即可输出到单独的文件
+ * @return nacoslogger.Logger
+ */
+func NewNacosLogger() nacoslogger.Logger {
+	return &nacosLogger{
+		module: logutils.Named(logutils.ModuleNacos).(*logutils.ZapLogModule).WithCallerSkip(1),
+	}
+}
+
+// useNacosLogger 创建客户端时 nacos 会重新初始化自己的日志，需要在之后替换
+// 读取配置通常早于日志初始化，此时保留 nacos 自己的日志
+func useNacosLogger() {
+	if logutils.IsInit() {
+		nacoslogger.SetLogger(NewNacosLogger())
+	}
+}
+
+func (l *nacosLogger) Info(args ...interface{}) {
+	l.module.Info(fmt.Sprint(args...))
+}
+
+func (l *nacosLogger) Warn(args ...interface{}) {
+	l.module.Warn(fmt.Sprint(args...))
+}
+
+func (l *nacosLogger) Error(args ...interface{}) {
+	l.module.Error(fmt.Sprint(args...))
+}
+
+func (l *nacosLogger) Debug(args ...interface{}) {
+	l.module.Debug(fmt.Sprint(args...))
+}
+
+func (l *nacosLogger) Infof(format string, args ...interface{}) {
+	l.module.Infof(format, args...)
+}
+
+func (l *nacosLogger) Warnf(format string, args ...interface{}) {
+	l.module.Warnf(format, args...)
+}
+
+func (l *nacosLogger) Errorf(format string, args ...interface{}) {
+	l.module.Errorf(format, args...)
+}
+
+func (l *nacosLogger) Debugf(format string, args ...interface{}) {
+	l.module.Debugf(format, args...)
+}
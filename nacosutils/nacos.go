@@ -75,6 +75,7 @@ func NacosGetConfig(nacosConf *NacosConfig, confPtr interface{}, confType string
 	if nil != err {
 		return fmt.Errorf("CreateConfigClient err: %s", err.Error())
 	}
+	useNacosLogger()
 
 	content, err := configClient.GetConfig(vo.ConfigParam{
 		DataId: nacosConf.Client.DataId,
@@ -113,6 +114,7 @@ func NacosGetConfig(nacosConf *NacosConfig, confPtr interface{}, confType string
 				log.Println("nacos config OnChange", "err", err.Error())
 			} else {
 				log.Println("nacos config OnChange success", "content", content)
+				gutils.TriggerConfigChange()
 			}
 		},
 	})
@@ -0,0 +1,176 @@
+package network
+
+/**
+ * @Author: lee
+ * @Description: tcp/udp 报文分帧
+ * @File: framer
+ * @Date: 2026-10-19 4:20 下午
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const defaultMaxFrameSize = 4 << 20
+
+type Framer interface {
+	// ReadFrame 从流中读取一帧，返回不含分隔符/长度头的内容
+	ReadFrame(r *bufio.Reader) ([]byte, error)
+	// EncodeFrame 将内容编码成一帧，内容不合法时返回错误，写出前调用，避免因内容错误断开连接
+	EncodeFrame(data []byte) ([]byte, error)
+}
+
+// LineFramer 按换行分帧，兼容 \r\n
+type LineFramer struct {
+	MaxSize int
+}
+
+func NewLineFramer() *LineFramer {
+	return &LineFramer{MaxSize: defaultMaxFrameSize}
+}
+
+func (f *LineFramer) ReadFrame(r *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		part, isPrefix, err := r.ReadLine()
+		if nil != err {
+			return nil, err
+		}
+		line = append(line, part...)
+		if f.MaxSize > 0 && len(line) > f.MaxSize {
+			return nil, fmt.Errorf("LineFramer frame size exceed %d", f.MaxSize)
+		}
+		if !isPrefix {
+			return line, nil
+		}
+	}
+}
+
+func (f *LineFramer) EncodeFrame(data []byte) ([]byte, error) {
+	if bytes.IndexByte(data, '\n') >= 0 {
+		return nil, fmt.Errorf("LineFramer frame contains newline")
+	}
+	if f.MaxSize > 0 && len(data) > f.MaxSize {
+		return nil, fmt.Errorf("LineFramer frame size %d exceed %d", len(data), f.MaxSize)
+	}
+	buf := make([]byte, 0, len(data)+1)
+	buf = append(buf, data...)
+	buf = append(buf, '\n')
+	return buf, nil
+}
+
+// LengthPrefixFramer 长度头分帧，长度头为 1/2/4/8 字节，长度不含头本身
+type LengthPrefixFramer struct {
+	HeaderSize int
+	Order      binary.ByteOrder
+	MaxSize    int
+}
+
+// NewLengthPrefixFramer
+/* @Description: 创建长度头分帧
+ * @param headerSize int 1/2/4/8
+ * @param order binary.ByteOrder 为 nil 时使用大端
+ * @return *LengthPrefixFramer
+ * @return error 长度头大小不合法
+ */
+func NewLengthPrefixFramer(headerSize int, order binary.ByteOrder) (*LengthPrefixFramer, error) {
+	switch headerSize {
+	case 1, 2, 4, 8:
+	default:
+		return nil, fmt.Errorf("NewLengthPrefixFramer invalid header size %d", headerSize)
+	}
+	if nil == order {
+		order = binary.BigEndian
+	}
+
+	return &LengthPrefixFramer{
+		HeaderSize: headerSize,
+		Order:      order,
+		MaxSize:    defaultMaxFrameSize,
+	}, nil
+}
+
+func (f *LengthPrefixFramer) ReadFrame(r *bufio.Reader) ([]byte, error) {
+	header := make([]byte, f.HeaderSize)
+	if _, err := io.ReadFull(r, header); nil != err {
+		return nil, err
+	}
+
+	var size uint64
+	switch f.HeaderSize {
+	case 1:
+		size = uint64(header[0])
+	case 2:
+		size = uint64(f.Order.Uint16(header))
+	case 4:
+		size = uint64(f.Order.Uint32(header))
+	case 8:
+		size = f.Order.Uint64(header)
+	}
+
+	if f.MaxSize > 0 && size > uint64(f.MaxSize) {
+		return nil, fmt.Errorf("LengthPrefixFramer frame size %d exceed %d", size, f.MaxSize)
+	}
+
+	ret := make([]byte, size)
+	if _, err := io.ReadFull(r, ret); nil != err {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (f *LengthPrefixFramer) EncodeFrame(data []byte) ([]byte, error) {
+	size := uint64(len(data))
+	if f.HeaderSize < 8 && size >= 1<<(8*uint(f.HeaderSize)) {
+		return nil, fmt.Errorf("LengthPrefixFramer frame size %d overflow %d bytes header", size, f.HeaderSize)
+	}
+	if f.MaxSize > 0 && size > uint64(f.MaxSize) {
+		return nil, fmt.Errorf("LengthPrefixFramer frame size %d exceed %d", size, f.MaxSize)
+	}
+
+	buf := make([]byte, f.HeaderSize, f.HeaderSize+len(data))
+	switch f.HeaderSize {
+	case 1:
+		buf[0] = byte(size)
+	case 2:
+		f.Order.PutUint16(buf, uint16(size))
+	case 4:
+		f.Order.PutUint32(buf, uint32(size))
+	case 8:
+		f.Order.PutUint64(buf, size)
+	}
+	buf = append(buf, data...)
+	return buf, nil
+}
+
+// FixedSizeFramer 固定长度分帧
+type FixedSizeFramer struct {
+	Size int
+}
+
+func NewFixedSizeFramer(size int) (*FixedSizeFramer, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("NewFixedSizeFramer invalid size %d", size)
+	}
+	return &FixedSizeFramer{Size: size}, nil
+}
+
+func (f *FixedSizeFramer) ReadFrame(r *bufio.Reader) ([]byte, error) {
+	ret := make([]byte, f.Size)
+	if _, err := io.ReadFull(r, ret); nil != err {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (f *FixedSizeFramer) EncodeFrame(data []byte) ([]byte, error) {
+	if len(data) != f.Size {
+		return nil, fmt.Errorf("FixedSizeFramer frame size %d, expect %d", len(data), f.Size)
+	}
+	return data, nil
+}
@@ -0,0 +1,68 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: framer_test
+ * @Date: 2026-10-19 11:20 下午
+ */
+
+func Test_Framer(t *testing.T) {
+	lengthFramer, err := NewLengthPrefixFramer(2, binary.LittleEndian)
+	if nil != err {
+		t.Fatal(err)
+	}
+	fixedFramer, err := NewFixedSizeFramer(3)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	for name, c := range map[string]struct {
+		framer Framer
+		frames []string
+	}{
+		"line":   {NewLineFramer(), []string{"abc", "", "d e"}},
+		"length": {lengthFramer, []string{"abc", "", "line\nbreak"}},
+		"fixed":  {fixedFramer, []string{"abc", "def"}},
+	} {
+		var buf bytes.Buffer
+		for _, frame := range c.frames {
+			data, err := c.framer.EncodeFrame([]byte(frame))
+			if nil != err {
+				t.Fatalf("%s encode %q err: %s", name, frame, err.Error())
+			}
+			buf.Write(data)
+		}
+		reader := bufio.NewReader(&buf)
+		for _, frame := range c.frames {
+			data, err := c.framer.ReadFrame(reader)
+			if nil != err || frame != string(data) {
+				t.Fatalf("%s expect %q, got %q err %v", name, frame, data, err)
+			}
+		}
+	}
+
+	if _, err = NewLengthPrefixFramer(3, nil); nil == err {
+		t.Fatal("expect invalid header size")
+	}
+	if _, err = NewFixedSizeFramer(0); nil == err {
+		t.Fatal("expect invalid size")
+	}
+	if _, err = fixedFramer.EncodeFrame([]byte("ab")); nil == err {
+		t.Fatal("expect size mismatch")
+	}
+	small, _ := NewLengthPrefixFramer(1, nil)
+	if _, err = small.EncodeFrame(make([]byte, 256)); nil == err {
+		t.Fatal("expect length overflow")
+	}
+	if _, err = NewLineFramer().EncodeFrame([]byte("a\nb")); nil == err {
+		t.Fatal("expect newline rejected")
+	}
+}
@@ -0,0 +1,84 @@
+package network
+
+/**
+ * @Author: lee
+ * @Description: go-kit log.Logger 适配，signalr 等库的内部日志写入 logutils
+ * @File: kit_logger
+ * @Date: 2026-10-19 10:50 下午
+ */
+
+import (
+	"fmt"
+	"github.com/0DeOrg/gutils/logutils"
+	"github.com/go-kit/log"
+	"go.uber.org/zap"
+)
+
+type KitLogger struct {
+	name   string
+	fields []zap.Field
+}
+
+var _ log.Logger = (*KitLogger)(nil)
+
+// NewKitLogger
+/* @Description: 创建写入 logutils 命名日志的 go-kit 日志，每次输出时获取日志，日志初始化前输出到标准错误
+ * @param name string 命名日志，如 logutils.ModuleSignalR
+ * @param fields ...zap.Field 固定字段，如连接地址
+ * @return *KitLogger
+ */
+func NewKitLogger(name string, fields ...zap.Field) *KitLogger {
+	return &KitLogger{
+		name:   name,
+		fields: fields,
+	}
+}
+
+// Log go-kit 的 keyvals 为 key/value 交替，level 决定日志级别，msg/message 为日志内容
+func (l *KitLogger) Log(keyVals ...interface{}) error {
+	fields := make([]zap.Field, 0, len(l.fields)+len(keyVals)/2)
+	fields = append(fields, l.fields...)
+	lvl := ""
+	msg := ""
+	hasErr := false
+	for i := 0; i < len(keyVals); i += 2 {
+		if i+1 >= len(keyVals) {
+			fields = append(fields, zap.Any("EXTRA_VALUE_AT_END", keyVals[i]))
+			break
+		}
+
+		key := fmt.Sprint(keyVals[i])
+		switch key {
+		case "level":
+			lvl = fmt.Sprint(keyVals[i+1])
+			continue
+		case "ts", "caller":
+			continue
+		case "msg", "message":
+			if "" == msg {
+				msg = fmt.Sprint(keyVals[i+1])
+				continue
+			}
+		case "error", "err":
+			hasErr = nil != keyVals[i+1]
+		}
+		fields = append(fields, zap.Any(key, keyVals[i+1]))
+	}
+
+	if "" == msg {
+		msg = l.name
+	}
+
+	module := logutils.NamedModule(l.name).WithCallerSkip(1)
+	switch {
+	case "debug" == lvl:
+		module.Debug(msg, fields...)
+	case "error" == lvl:
+		module.Error(msg, fields...)
+	case "warn" == lvl || hasErr:
+		module.Warn(msg, fields...)
+	default:
+		module.Info(msg, fields...)
+	}
+	return nil
+}
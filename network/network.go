@@ -11,6 +11,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"sync/atomic"
 )
 
 type HttpInterface interface {
@@ -29,13 +30,35 @@ type SocketInterface interface {
 
 type NetAgentBase struct {
 	URL      *url.URL
-	isAlive  bool
+	isAlive  int32 //通过 IsAlive/setAlive 读写，收发协程及连接池并发访问
 	timeout  int
-	isClosed bool
+	isClosed int32 //通过 IsClosed/setClosed 读写
 }
 
 var _ HttpInterface = (*NetAgentBase)(nil)
 
+// IsAlive 连接是否正常
+func (b *NetAgentBase) IsAlive() bool {
+	return 1 == atomic.LoadInt32(&b.isAlive)
+}
+
+// IsClosed 是否已主动关闭，关闭后不再重连
+func (b *NetAgentBase) IsClosed() bool {
+	return 1 == atomic.LoadInt32(&b.isClosed)
+}
+
+func (b *NetAgentBase) setClosed() {
+	atomic.StoreInt32(&b.isClosed, 1)
+}
+
+func (b *NetAgentBase) setAlive(alive bool) {
+	if alive {
+		atomic.StoreInt32(&b.isAlive, 1)
+	} else {
+		atomic.StoreInt32(&b.isAlive, 0)
+	}
+}
+
 func (b *NetAgentBase) SimpleGet(path string, params map[string]string) (string, error) {
 	return "", nil
 }
@@ -8,7 +8,9 @@ package network
  */
 
 import (
+	"context"
 	"fmt"
+	"github.com/0DeOrg/gutils/logutils"
 	"github.com/go-resty/resty/v2"
 	"golang.org/x/net/publicsuffix"
 	"net/http"
@@ -22,6 +24,7 @@ import (
 type RestAgent struct {
 	NetAgentBase
 	Client *resty.Client
+	ctx    context.Context
 }
 
 var _ HttpInterface = (*RestAgent)(nil)
@@ -56,6 +59,7 @@ func NewRestClient(host string, port uint, isHttps bool) (*RestAgent, error) {
 		Timeout: 20 * time.Second,
 	}
 	client := resty.NewWithClient(&hc)
+	client.OnBeforeRequest(propagateContextIDs)
 	ret := RestAgent{
 		NetAgentBase: NetAgentBase{
 			URL: url,
@@ -66,12 +70,42 @@ func NewRestClient(host string, port uint, isHttps bool) (*RestAgent, error) {
 	return &ret, nil
 }
 
+// WithContext
+/* @Description: 返回绑定上下文的副本，请求时上下文中的 request_id/trace_id 等会以请求头传给下游
+ * @param ctx context.Context
+ * @return *RestAgent
+ */
+func (h *RestAgent) WithContext(ctx context.Context) *RestAgent {
+	ret := *h
+	ret.ctx = ctx
+	return &ret
+}
+
+func (h *RestAgent) request() *resty.Request {
+	r := h.Client.R()
+	if nil != h.ctx {
+		r.SetContext(h.ctx)
+	}
+	return r
+}
+
+func propagateContextIDs(_ *resty.Client, r *resty.Request) error {
+	for field, value := range logutils.ContextIDs(r.Context()) {
+		header, ok := logutils.ContextHeaders[field]
+		if !ok || "" != r.Header.Get(header) {
+			continue
+		}
+		r.SetHeader(header, value)
+	}
+	return nil
+}
+
 func (h *RestAgent) SimpleGet(path string, params map[string]string) (string, error) {
 	url := h.URL.String() + path
 	if nil != params {
 
 	}
-	res, err := h.Client.R().SetQueryParams(params).Get(url)
+	res, err := h.request().SetQueryParams(params).Get(url)
 	if nil != err {
 		return "", err
 	}
@@ -85,7 +119,7 @@ func (h *RestAgent) SimpleGet(path string, params map[string]string) (string, er
 
 func (h *RestAgent) SimplePost(path string, reqBody string, params map[string]string) (string, error) {
 	url := h.URL.String() + path
-	r := h.Client.R()
+	r := h.request()
 
 	res, err := r.SetQueryParams(params).SetBody(reqBody).SetHeader("Content-Type", "application/json").Post(url)
 	if nil != err {
@@ -101,7 +135,7 @@ func (h *RestAgent) SimplePost(path string, reqBody string, params map[string]st
 
 func (h *RestAgent) Get(path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (string, error) {
 	url := h.URL.String() + path
-	r := h.Client.R()
+	r := h.request()
 	res, err := r.SetQueryParams(params).SetHeaders(headers).SetCookies(cookies).Get(url)
 	if nil != err {
 		return "", err
@@ -116,7 +150,7 @@ func (h *RestAgent) Get(path string, params map[string]string, headers map[strin
 
 func (h *RestAgent) Post(path string, reqBody string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (string, error) {
 	url := h.URL.String() + path
-	r := h.Client.R()
+	r := h.request()
 	res, err := r.SetQueryParams(params).SetBody(reqBody).SetHeaders(headers).SetCookies(cookies).Post(url)
 	if nil != err {
 		return "", err
@@ -131,7 +165,7 @@ func (h *RestAgent) Post(path string, reqBody string, params map[string]string,
 
 func (h *RestAgent) PostForm(path string, reqBody string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (string, error) {
 	url := h.URL.String() + path
-	res, err := h.Client.R().SetFormData(params).SetBody(reqBody).SetHeaders(headers).SetCookies(cookies).Post(url)
+	res, err := h.request().SetFormData(params).SetBody(reqBody).SetHeaders(headers).SetCookies(cookies).Post(url)
 	if nil != err {
 		return "", err
 	}
@@ -144,7 +178,7 @@ func (h *RestAgent) PostForm(path string, reqBody string, params map[string]stri
 
 func (h *RestAgent) Put(path string, reqBody string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (string, error) {
 	url := h.URL.String() + path
-	res, err := h.Client.R().SetQueryParams(params).SetBody(reqBody).SetHeaders(headers).SetCookies(cookies).Put(url)
+	res, err := h.request().SetQueryParams(params).SetBody(reqBody).SetHeaders(headers).SetCookies(cookies).Put(url)
 	if nil != err {
 		return "", err
 	}
@@ -158,7 +192,7 @@ func (h *RestAgent) Put(path string, reqBody string, params map[string]string, h
 
 func (h *RestAgent) Delete(path string, reqBody string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (string, error) {
 	url := h.URL.String() + path
-	res, err := h.Client.R().SetQueryParams(params).SetBody(reqBody).SetHeaders(headers).SetCookies(cookies).Delete(url)
+	res, err := h.request().SetQueryParams(params).SetBody(reqBody).SetHeaders(headers).SetCookies(cookies).Delete(url)
 	if nil != err {
 		return "", err
 	}
@@ -8,33 +8,135 @@ package network
  */
 
 import (
+	"bytes"
 	"context"
-	kitlog "github.com/go-kit/log"
+	"encoding/json"
+	"fmt"
+	"github.com/0DeOrg/gutils/logutils"
 	"github.com/philippseith/signalr"
+	"go.uber.org/zap"
+	"io/ioutil"
+	"net/http"
 	"net/url"
-	"os"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
-type agentHub struct {
-	signalr.Hub
+const (
+	SignalRTransportAuto       = ""
+	SignalRTransportWebSockets = "WebSockets"
+	SignalRTransportSSE        = "ServerSentEvents"
+)
+
+const (
+	defaultSignalRConnectTimeout = 30 * time.Second
+	defaultSignalRBackoffMin     = time.Second
+	defaultSignalRBackoffMax     = 30 * time.Second
+	signalRStableElapse          = 30 * time.Second //连接保持超过该时间后断开才重置退避
+)
+
+type signalROptions struct {
+	headers        map[string]string
+	accessToken    func() (string, error)
+	transport      string
+	backoffMin     time.Duration
+	backoffMax     time.Duration
+	connectTimeout time.Duration
+	httpClient     *http.Client
+	debug          bool
 }
 
-func (h *agentHub) OnConnected(connectionID string) {
+type SignalROption func(*signalROptions)
 
+// WithSignalRHeaders
+/* @Description: negotiate 及建立连接时附带的请求头
+ * @param headers map[string]string
+ */
+func WithSignalRHeaders(headers map[string]string) SignalROption {
+	return func(o *signalROptions) {
+		for k, v := range headers {
+			o.headers[k] = v
+		}
+	}
 }
 
-func (h *agentHub) OnDisconnected(connectionID string) {
+// WithSignalRAccessToken
+/* @Description: 每次建立连接时获取token，以 Authorization: Bearer 方式带上
+ * @param provider func() (string, error)
+ */
+func WithSignalRAccessToken(provider func() (string, error)) SignalROption {
+	return func(o *signalROptions) {
+		o.accessToken = provider
+	}
+}
+
+// WithSignalRTransport
+/* @Description: 指定传输方式 SignalRTransportWebSockets 或 SignalRTransportSSE，默认由服务端协商
+ * @param transport string
+ */
+func WithSignalRTransport(transport string) SignalROption {
+	return func(o *signalROptions) {
+		o.transport = transport
+	}
+}
 
+// WithSignalRBackoff
+/* @Description: 重连退避时间，连续失败或连接很快断开时从min开始翻倍直到max，连接保持30s以上后重置
+ * @param min time.Duration
+ * @param max time.Duration
+ */
+func WithSignalRBackoff(min, max time.Duration) SignalROption {
+	return func(o *signalROptions) {
+		o.backoffMin = min
+		o.backoffMax = max
+	}
+}
+
+// WithSignalRConnectTimeout
+/* @Description: WaitForConnected 的超时时间
+ * @param timeout time.Duration
+ */
+func WithSignalRConnectTimeout(timeout time.Duration) SignalROption {
+	return func(o *signalROptions) {
+		o.connectTimeout = timeout
+	}
+}
+
+func WithSignalRHttpClient(client *http.Client) SignalROption {
+	return func(o *signalROptions) {
+		o.httpClient = client
+	}
+}
+
+func WithSignalRDebug(debug bool) SignalROption {
+	return func(o *signalROptions) {
+		o.debug = debug
+	}
 }
 
 type SignalRAgent struct {
 	NetAgentBase
-	client signalr.Client
-	ctx    context.Context
+	client         signalr.Client
+	ctx            context.Context
+	cancel         context.CancelFunc
+	opts           *signalROptions
+	mtx            sync.Mutex
+	failures       int
+	generation     uint64    //成功建立的连接序号，用于识别被合并的状态变化
+	connectedAt    time.Time //当前连接建立时间
+	started        bool
+	OnConnected    func()          //首次连接成功回调
+	OnReconnected  func()          //断线重连成功回调
+	OnDisconnected func(err error) //连接断开回调
 }
 
-func NewSignalRAgent(host string, path string, port uint, hubs []string, isSecure bool, receiver signalr.ReceiverInterface) (*SignalRAgent, error) {
+// NewSignalRAgent
+/* @Description: 创建 signalR 客户端，hub 地址包含在 path 中，如 /ws/marketHub
+ * @param hubs []string 未使用，保留以兼容原有调用
+ */
+func NewSignalRAgent(host string, path string, port uint, hubs []string, isSecure bool, receiver signalr.ReceiverInterface, opts ...SignalROption) (*SignalRAgent, error) {
 	hostUrl := ""
 	if isSecure {
 		hostUrl = "https://" + host
@@ -50,35 +152,112 @@ func NewSignalRAgent(host string, path string, port uint, hubs []string, isSecur
 
 	rawUrl, err := url.Parse(hostUrl)
 	if nil != err {
-		panic(err.Error())
+		return nil, err
+	}
+
+	options := &signalROptions{
+		headers:        make(map[string]string),
+		backoffMin:     defaultSignalRBackoffMin,
+		backoffMax:     defaultSignalRBackoffMax,
+		connectTimeout: defaultSignalRConnectTimeout,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if nil == options.httpClient {
+		options.httpClient = &http.Client{}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ret := &SignalRAgent{
+		NetAgentBase: NetAgentBase{
+			URL:     rawUrl,
+			timeout: int(options.connectTimeout / time.Millisecond),
+		},
+		ctx:    ctx,
+		cancel: cancel,
+		opts:   options,
+	}
+
+	doer := &signalRDoer{
+		client:    options.httpClient,
+		transport: options.transport,
 	}
 
-	client, err := signalr.NewClient(context.Background(),
+	client, err := signalr.NewClient(ctx,
 		signalr.WithReceiver(receiver),
 		signalr.WithAutoReconnect(func() (signalr.Connection, error) {
-			return signalr.NewHTTPConnection(context.TODO(), hostUrl)
+			ret.waitBackoff()
+			conn, err := signalr.NewHTTPConnection(ctx, hostUrl,
+				signalr.WithHTTPClient(doer),
+				signalr.WithHTTPHeaders(ret.httpHeaders))
+			ret.mtx.Lock()
+			if nil != err {
+				ret.failures++
+			} else {
+				ret.generation++
+			}
+			ret.mtx.Unlock()
+			if nil != err {
+				logutils.Warn("SignalRAgent dial fatal", zap.String("url", hostUrl), zap.Error(err))
+			}
+			return conn, err
 		}),
-		signalr.Logger(kitlog.NewLogfmtLogger(os.Stdout), false))
+		signalr.Logger(NewKitLogger(logutils.ModuleSignalR, zap.String("url", hostUrl)), options.debug))
 	if nil != err {
+		cancel()
 		return nil, err
 	}
 
-	ret := &SignalRAgent{
-		NetAgentBase: NetAgentBase{
-			URL: rawUrl,
-		},
-		client: client,
-	}
+	ret.client = client
 
 	return ret, nil
 }
 
 func (sig *SignalRAgent) Connect() {
+	sig.mtx.Lock()
+	if sig.started {
+		sig.mtx.Unlock()
+		return
+	}
+	sig.started = true
+	sig.mtx.Unlock()
+
+	sig.observeState()
 	sig.client.Start()
 }
 
+// Close
+/* @Description: 停止客户端及自动重连
+ */
+func (sig *SignalRAgent) Close() {
+	sig.setClosed()
+	sig.cancel()
+}
+
+// WaitForConnected
+/* @Description: 等待连接成功，超时时间由 WithSignalRConnectTimeout 指定，默认30s
+ * @return error
+ */
 func (sig *SignalRAgent) WaitForConnected() error {
-	return <-sig.client.WaitForState(context.Background(), signalr.ClientConnected)
+	ctx, cancel := context.WithTimeout(sig.ctx, sig.opts.connectTimeout)
+	defer cancel()
+	return sig.WaitForConnectedWithContext(ctx)
+}
+
+func (sig *SignalRAgent) WaitForConnectedWithContext(ctx context.Context) error {
+	err := <-sig.client.WaitForState(ctx, signalr.ClientConnected)
+	if nil != err {
+		if lastErr := sig.client.Err(); nil != lastErr {
+			return fmt.Errorf("wait for signalR connect fatal, url: %s, err: %s, last err: %s", sig.URL.String(), err.Error(), lastErr.Error())
+		}
+		return fmt.Errorf("wait for signalR connect fatal, url: %s, err: %s", sig.URL.String(), err.Error())
+	}
+	return nil
+}
+
+func (sig *SignalRAgent) IsConnected() bool {
+	return sig.client.State() == signalr.ClientConnected
 }
 
 func (sig *SignalRAgent) Invoke(method string, arguments ...interface{}) signalr.InvokeResult {
@@ -88,3 +267,175 @@ func (sig *SignalRAgent) Invoke(method string, arguments ...interface{}) signalr
 func (sig *SignalRAgent) Send(method string, arguments ...interface{}) error {
 	return <-sig.client.Send(method, arguments...)
 }
+
+// PullStream
+/* @Description: 调用服务端流式方法，返回的管道逐条返回流数据，流结束时管道关闭
+ * @param method string
+ * @param arguments ...interface{}
+ * @return <-chan signalr.InvokeResult
+ */
+func (sig *SignalRAgent) PullStream(method string, arguments ...interface{}) <-chan signalr.InvokeResult {
+	return sig.client.PullStream(method, arguments...)
+}
+
+// PushStreams
+/* @Description: 上传流，参数中的管道内容会被推送到服务端，直到管道关闭
+ * @param method string
+ * @param arguments ...interface{}
+ * @return error
+ */
+func (sig *SignalRAgent) PushStreams(method string, arguments ...interface{}) error {
+	return <-sig.client.PushStreams(method, arguments...)
+}
+
+func (sig *SignalRAgent) httpHeaders() http.Header {
+	header := http.Header{}
+	for k, v := range sig.opts.headers {
+		header.Set(k, v)
+	}
+
+	if nil != sig.opts.accessToken {
+		token, err := sig.opts.accessToken()
+		if nil != err {
+			logutils.Warn("SignalRAgent get access token fatal", zap.String("url", sig.URL.String()), zap.Error(err))
+		} else if "" != token {
+			header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	return header
+}
+
+// waitBackoff 连续失败时按退避时间等待后再重连
+func (sig *SignalRAgent) waitBackoff() {
+	sig.mtx.Lock()
+	failures := sig.failures
+	sig.mtx.Unlock()
+	if failures <= 0 {
+		return
+	}
+
+	wait := sig.opts.backoffMin
+	for i := 1; i < failures && wait < sig.opts.backoffMax; i++ {
+		wait *= 2
+	}
+	if wait > sig.opts.backoffMax {
+		wait = sig.opts.backoffMax
+	}
+
+	select {
+	case <-time.After(wait):
+	case <-sig.ctx.Done():
+	}
+}
+
+// observeState 状态变化通知只表示有变化，读取时可能已合并了多次变化，通过连接序号识别被合并的断开重连
+func (sig *SignalRAgent) observeState() {
+	ch := make(chan struct{}, 16)
+	cancelObserve := sig.client.ObserveStateChanged(ch)
+	go func() {
+		defer cancelObserve()
+		connected := false
+		var connectedGen uint64
+		everConnected := false
+		for {
+			select {
+			case <-sig.ctx.Done():
+				return
+			case _, ok := <-ch:
+				if !ok {
+					return
+				}
+			}
+
+			state := sig.client.State()
+			sig.mtx.Lock()
+			gen := sig.generation
+			sig.mtx.Unlock()
+
+			if connected && (state != signalr.ClientConnected || gen != connectedGen) {
+				connected = false
+				sig.disconnected()
+			}
+			if !connected && state == signalr.ClientConnected {
+				connected = true
+				connectedGen = gen
+				sig.connected(everConnected)
+				everConnected = true
+			}
+		}
+	}()
+}
+
+func (sig *SignalRAgent) connected(reconnect bool) {
+	sig.mtx.Lock()
+	sig.connectedAt = time.Now()
+	sig.mtx.Unlock()
+	sig.setAlive(true)
+	logutils.Info("SignalRAgent connected", zap.String("url", sig.URL.String()), zap.Bool("reconnect", reconnect))
+	if reconnect {
+		if nil != sig.OnReconnected {
+			sig.OnReconnected()
+		}
+	} else if nil != sig.OnConnected {
+		sig.OnConnected()
+	}
+}
+
+// disconnected 连接很快被断开时也计入失败次数，避免服务端接受后立即断开时无退避地重连
+func (sig *SignalRAgent) disconnected() {
+	sig.mtx.Lock()
+	if time.Since(sig.connectedAt) < signalRStableElapse {
+		sig.failures++
+	} else {
+		sig.failures = 0
+	}
+	sig.mtx.Unlock()
+
+	sig.setAlive(false)
+	err := sig.client.Err()
+	logutils.Warn("SignalRAgent disconnected", zap.String("url", sig.URL.String()), zap.Error(err))
+	if nil != sig.OnDisconnected {
+		sig.OnDisconnected(err)
+	}
+}
+
+// signalRDoer 指定传输方式时过滤negotiate返回的可用传输方式
+type signalRDoer struct {
+	client    *http.Client
+	transport string
+}
+
+func (d *signalRDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.client.Do(req)
+	if nil != err || SignalRTransportAuto == d.transport || !strings.HasSuffix(req.URL.Path, "/negotiate") {
+		return resp, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if nil != err {
+		return nil, err
+	}
+
+	negotiate := map[string]interface{}{}
+	if err = json.Unmarshal(body, &negotiate); nil == err {
+		if list, ok := negotiate["availableTransports"].([]interface{}); ok {
+			filtered := make([]interface{}, 0, 1)
+			for _, item := range list {
+				if m, ok := item.(map[string]interface{}); ok && m["transport"] == d.transport {
+					filtered = append(filtered, item)
+				}
+			}
+			negotiate["availableTransports"] = filtered
+			if data, err := json.Marshal(negotiate); nil == err {
+				body = data
+			}
+		}
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return resp, nil
+}
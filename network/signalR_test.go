@@ -0,0 +1,158 @@
+package network
+
+import (
+	"context"
+	"github.com/go-kit/log"
+	"github.com/philippseith/signalr"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: signalR_test
+ * @Date: 2026-10-19 10:40 下午
+ */
+
+func Test_SignalRDoerTransport(t *testing.T) {
+	negotiate := `{"connectionId":"1","availableTransports":[{"transport":"WebSockets","transferFormats":["Text"]},{"transport":"ServerSentEvents","transferFormats":["Text"]}]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(negotiate)))
+		_, _ = w.Write([]byte(negotiate))
+	}))
+	defer srv.Close()
+
+	doer := &signalRDoer{client: srv.Client(), transport: SignalRTransportSSE}
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/hub/negotiate", nil)
+	resp, err := doer.Do(req)
+	if nil != err {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	if strings.Contains(string(body), "WebSockets") || !strings.Contains(string(body), "ServerSentEvents") {
+		t.Fatalf("unexpected negotiate %s", body)
+	}
+	if strconv.Itoa(len(body)) != resp.Header.Get("Content-Length") || int64(len(body)) != resp.ContentLength {
+		t.Fatalf("stale content length %s, body %d", resp.Header.Get("Content-Length"), len(body))
+	}
+}
+
+type testKickHub struct {
+	signalr.Hub
+}
+
+// Kick 服务端断开当前连接
+func (h *testKickHub) Kick() {
+	h.Abort()
+}
+
+type testReceiver struct {
+	signalr.Receiver
+}
+
+func Test_SignalRAgentReconnect(t *testing.T) {
+	initTestLogger(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server, err := signalr.NewServer(ctx, signalr.SimpleHubFactory(&testKickHub{}), signalr.Logger(log.NewNopLogger(), false))
+	if nil != err {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	server.MapHTTP(signalr.WithHTTPServeMux(mux), "/hub")
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	//hubs 不拼接到地址上
+	agent, err := NewSignalRAgent(host, "/hub", 0, []string{"hub", "other"}, false, &testReceiver{},
+		WithSignalRBackoff(10*time.Millisecond, 20*time.Millisecond), WithSignalRConnectTimeout(5*time.Second))
+	if nil != err {
+		t.Fatal(err)
+	}
+	if srv.URL+"/hub" != agent.URL.String() {
+		t.Fatalf("unexpected url %s", agent.URL.String())
+	}
+
+	events := make(chan string, 8)
+	agent.OnConnected = func() { events <- "connected" }
+	agent.OnReconnected = func() { events <- "reconnected" }
+	agent.OnDisconnected = func(err error) { events <- "disconnected" }
+	agent.Connect()
+	if err = agent.WaitForConnected(); nil != err {
+		t.Fatal(err)
+	}
+
+	_ = agent.Send("Kick")
+	for _, expect := range []string{"connected", "disconnected", "reconnected"} {
+		select {
+		case got := <-events:
+			if expect != got {
+				t.Fatalf("expect %s, got %s", expect, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("wait for %s timeout", expect)
+		}
+	}
+	//连接很快被断开计入失败次数
+	agent.mtx.Lock()
+	failures := agent.failures
+	agent.mtx.Unlock()
+	if 1 != failures {
+		t.Fatalf("expect 1 failure after quick disconnect, got %d", failures)
+	}
+
+	agent.Close()
+	if !agent.IsClosed() {
+		t.Fatal("agent should be closed")
+	}
+	for i := 0; agent.IsConnected(); i++ {
+		if i > 500 {
+			t.Fatal("client should stop after Close")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	//关闭后不再重连
+	time.Sleep(100 * time.Millisecond)
+	if 0 != len(events) && "disconnected" != <-events {
+		t.Fatal("agent should not reconnect after Close")
+	}
+}
+
+func Test_SignalRAgentBackoff(t *testing.T) {
+	agent, err := NewSignalRAgent("127.0.0.1", "/hub", 1, nil, false, &testReceiver{},
+		WithSignalRBackoff(10*time.Millisecond, 25*time.Millisecond))
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	agent.waitBackoff()
+	if time.Since(start) > 5*time.Millisecond {
+		t.Fatal("no failure should not wait")
+	}
+
+	//10ms 20ms 之后封顶25ms
+	agent.failures = 3
+	start = time.Now()
+	agent.waitBackoff()
+	if elapse := time.Since(start); elapse < 25*time.Millisecond || elapse > 200*time.Millisecond {
+		t.Fatalf("expect capped backoff 25ms, got %s", elapse)
+	}
+
+	//关闭后不再等待
+	agent.opts.backoffMax = time.Minute
+	agent.failures = 10
+	agent.Close()
+	start = time.Now()
+	agent.waitBackoff()
+	if time.Since(start) > time.Second {
+		t.Fatal("waitBackoff should return after Close")
+	}
+}
@@ -0,0 +1,351 @@
+package network
+
+/**
+ * @Author: lee
+ * @Description: Server-Sent Events 客户端，断线自动重连并通过 Last-Event-ID 续传
+ * @File: sse
+ * @Date: 2026-10-19 3:10 下午
+ */
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/0DeOrg/gutils/logutils"
+	"go.uber.org/zap"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SSEDefaultEvent = "message"
+	sseMinRetry     = 500 //服务端下发的重连间隔下限 单位ms，防止 retry: 0 时不停重连
+)
+
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  string
+	Retry int //ms
+}
+
+type SSEAgent struct {
+	NetAgentBase
+	client      *http.Client
+	headers     map[string]string
+	lastEventID string
+	handlers    map[string]func(*SSEAgent, *SSEEvent)
+	mtx         sync.RWMutex
+	cancel      context.CancelFunc //停止重连
+	cancelReq   context.CancelFunc //中断当前连接，Reconnect 时调用
+	errConn     error
+	retry       int //服务端下发的重连间隔 单位ms，0 使用默认值
+	OnMessage   func(*SSEAgent, *SSEEvent) //未注册事件类型的消息回调
+	OnConnected func()
+	OnClose     func(*SSEAgent, error)
+}
+
+func NewSSEAgent(host string, port uint, path string, isSecure bool) (*SSEAgent, error) {
+	hostUrl := ""
+	trimHost := strings.TrimLeft(host, " ")
+
+	if strings.HasPrefix(trimHost, "http") && strings.Contains(trimHost, "://") {
+		hostUrl = trimHost
+	} else {
+		if isSecure {
+			hostUrl += "https://" + trimHost
+		} else {
+			hostUrl += "http://" + trimHost
+		}
+	}
+
+	if 0 != port {
+		hostUrl += ":" + strconv.FormatUint(uint64(port), 10)
+	}
+
+	hostUrl += path
+
+	rawUrl, err := url.Parse(hostUrl)
+	if nil != err {
+		return nil, err
+	}
+
+	ret := &SSEAgent{
+		NetAgentBase: NetAgentBase{
+			URL:     rawUrl,
+			timeout: 5000,
+		},
+		//流式连接不能设置整体超时
+		client:   &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()},
+		headers:  make(map[string]string),
+		handlers: make(map[string]func(*SSEAgent, *SSEEvent)),
+	}
+
+	return ret, nil
+}
+
+// SetTLSConfig
+/* @Description: 设置TLS配置，如自签名证书、跳过校验等
+ * @param cfg *tls.Config
+ */
+func (s *SSEAgent) SetTLSConfig(cfg *tls.Config) {
+	if transport, ok := s.client.Transport.(*http.Transport); ok {
+		transport.TLSClientConfig = cfg
+	}
+}
+
+func (s *SSEAgent) SetHeader(key, value string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.headers[key] = value
+}
+
+// On
+/* @Description: 按事件类型注册回调，未注册的类型走 OnMessage
+ * @param event string
+ * @param handler func(*SSEAgent, *SSEEvent)
+ */
+func (s *SSEAgent) On(event string, handler func(*SSEAgent, *SSEEvent)) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.handlers[event] = handler
+}
+
+func (s *SSEAgent) LastEventID() string {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.lastEventID
+}
+
+func (s *SSEAgent) Connect() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mtx.Lock()
+	s.cancel = cancel
+	s.mtx.Unlock()
+	//Connect 之前已关闭
+	if s.IsClosed() {
+		cancel()
+	}
+
+	go func() {
+		for {
+			if s.IsClosed() {
+				break
+			}
+
+			reqCtx, reqCancel := context.WithCancel(ctx)
+			s.mtx.Lock()
+			s.cancelReq = reqCancel
+			s.mtx.Unlock()
+
+			err := s.stream(reqCtx)
+			s.setAlive(false)
+			if s.IsClosed() {
+				reqCancel()
+				break
+			}
+			//Reconnect 中断的请求
+			if nil != reqCtx.Err() {
+				err = fmt.Errorf("sse reconnect required")
+			}
+			reqCancel()
+			if nil != err {
+				s.mtx.Lock()
+				s.errConn = err
+				s.mtx.Unlock()
+				logutils.Limited("SSEAgent stream:"+s.URL.String()).Warn("SSEAgent stream fatal", zap.Error(err), zap.String("url", s.URL.String()))
+			}
+			if nil != s.OnClose {
+				s.OnClose(s, err)
+			}
+
+			select {
+			case <-time.After(s.retryElapse()):
+			case <-ctx.Done():
+			}
+		}
+	}()
+}
+
+// Reconnect
+/* @Description: 断开当前连接，按重连间隔重新连接
+ */
+func (s *SSEAgent) Reconnect() {
+	s.setAlive(false)
+	s.mtx.RLock()
+	cancelReq := s.cancelReq
+	s.mtx.RUnlock()
+	if nil != cancelReq {
+		cancelReq()
+	}
+}
+
+func (s *SSEAgent) Close() {
+	s.setClosed()
+	s.mtx.RLock()
+	cancel := s.cancel
+	s.mtx.RUnlock()
+	if nil != cancel {
+		cancel()
+	}
+}
+
+// retryElapse 服务端下发的重连间隔优先
+func (s *SSEAgent) retryElapse() time.Duration {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	if s.retry > 0 {
+		return time.Duration(s.retry) * time.Millisecond
+	}
+	return time.Duration(s.timeout) * time.Millisecond
+}
+
+func (s *SSEAgent) WaitForConnected() <-chan error {
+	ret := make(chan error, 1)
+	go func() {
+		tick := time.NewTicker(100 * time.Millisecond)
+		defer tick.Stop()
+		timeout := time.After(30 * time.Second)
+		for {
+			select {
+			case <-tick.C:
+				if s.IsAlive() {
+					ret <- nil
+					return
+				}
+			case <-timeout:
+				s.mtx.RLock()
+				errConn := s.errConn
+				s.mtx.RUnlock()
+				ret <- fmt.Errorf("wait for sse connect time out 30s, url: %s, err: %v", s.URL.String(), errConn)
+				return
+			}
+		}
+	}()
+
+	return ret
+}
+
+func (s *SSEAgent) stream(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL.String(), nil)
+	if nil != err {
+		return err
+	}
+
+	s.mtx.RLock()
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	if "" != s.lastEventID {
+		req.Header.Set("Last-Event-ID", s.lastEventID)
+	}
+	s.mtx.RUnlock()
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+
+	resp, err := s.client.Do(req)
+	if nil != err {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("sse response status: %s", resp.Status)
+	}
+
+	s.setAlive(true)
+	s.mtx.Lock()
+	s.errConn = nil
+	s.mtx.Unlock()
+	if nil != s.OnConnected {
+		s.OnConnected()
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	event := &SSEEvent{}
+	var data strings.Builder
+	hasData := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if "" == line {
+			if hasData {
+				event.Data = data.String()
+				s.dispatch(event)
+			}
+			event = &SSEEvent{}
+			data.Reset()
+			hasData = false
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := line, ""
+		if idx := strings.Index(line, ":"); idx >= 0 {
+			field = line[:idx]
+			value = strings.TrimPrefix(line[idx+1:], " ")
+		}
+
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			if hasData {
+				data.WriteString("\n")
+			}
+			data.WriteString(value)
+			hasData = true
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				event.ID = value
+				s.mtx.Lock()
+				s.lastEventID = value
+				s.mtx.Unlock()
+			}
+		case "retry":
+			//服务端指定的重连间隔覆盖默认值
+			if retry, err := strconv.Atoi(value); nil == err && retry >= 0 {
+				event.Retry = retry
+				if retry < sseMinRetry {
+					retry = sseMinRetry
+				}
+				s.mtx.Lock()
+				s.retry = retry
+				s.mtx.Unlock()
+			}
+		}
+	}
+
+	if err = scanner.Err(); nil != err {
+		return err
+	}
+
+	return fmt.Errorf("sse stream closed by server")
+}
+
+func (s *SSEAgent) dispatch(event *SSEEvent) {
+	if "" == event.Event {
+		event.Event = SSEDefaultEvent
+	}
+
+	s.mtx.RLock()
+	handler, ok := s.handlers[event.Event]
+	s.mtx.RUnlock()
+
+	if ok {
+		handler(s, event)
+		return
+	}
+
+	if nil != s.OnMessage {
+		s.OnMessage(s, event)
+	}
+}
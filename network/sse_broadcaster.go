@@ -0,0 +1,253 @@
+package network
+
+/**
+ * @Author: lee
+ * @Description: gin 可用的SSE推送，每个客户端独立缓冲，支持 Last-Event-ID 续传
+ * @File: sse_broadcaster
+ * @Date: 2026-10-19 3:40 下午
+ */
+
+import (
+	"fmt"
+	"github.com/0DeOrg/gutils/logutils"
+	"github.com/0DeOrg/gutils/structutils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSSEClientBuffer = 256
+	defaultSSEHistory      = 256
+	defaultSSEHeartbeat    = 15 * time.Second
+)
+
+type SSEBroadcasterConfig struct {
+	ClientBuffer int           //单个客户端缓冲消息数量，满了之后断开慢客户端
+	History      int           //保留用于续传的历史消息数量
+	Retry        int           //通知客户端的重连间隔 单位ms，0不下发
+	Heartbeat    time.Duration //心跳注释间隔，防止代理断开空闲连接
+}
+
+type sseClient struct {
+	id     uint64
+	ch     chan *SSEEvent
+	done   chan struct{}
+	closed bool
+}
+
+type SSEBroadcaster struct {
+	cfg      SSEBroadcasterConfig
+	clients  map[uint64]*sseClient
+	history  *structutils.Ring[*SSEEvent] //History 为0时为 nil
+	seq      uint64
+	clientID uint64
+	mtx      sync.Mutex
+	dropped  uint64
+}
+
+func NewSSEBroadcaster(cfg SSEBroadcasterConfig) *SSEBroadcaster {
+	if cfg.ClientBuffer <= 0 {
+		cfg.ClientBuffer = defaultSSEClientBuffer
+	}
+	if cfg.History < 0 {
+		cfg.History = 0
+	} else if 0 == cfg.History {
+		cfg.History = defaultSSEHistory
+	}
+	if cfg.Heartbeat <= 0 {
+		cfg.Heartbeat = defaultSSEHeartbeat
+	}
+
+	ret := &SSEBroadcaster{
+		cfg:     cfg,
+		clients: make(map[uint64]*sseClient),
+	}
+	if cfg.History > 0 {
+		ret.history = structutils.NewRing[*SSEEvent](cfg.History)
+	}
+
+	return ret
+}
+
+// Broadcast
+/* @Description: 推送消息给所有客户端，未指定ID时自动生成递增ID
+ * @param event string 事件类型，空为message
+ * @param data string
+ */
+func (b *SSEBroadcaster) Broadcast(event string, data string) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.seq++
+	msg := &SSEEvent{
+		ID:    strconv.FormatUint(b.seq, 10),
+		Event: event,
+		Data:  data,
+	}
+
+	if nil != b.history {
+		b.history.Push(msg)
+	}
+
+	for id, c := range b.clients {
+		select {
+		case c.ch <- msg:
+		default:
+			//慢客户端直接断开，由客户端携带 Last-Event-ID 重连续传
+			b.dropped++
+			b.removeLocked(id)
+			logutils.Warn("SSEBroadcaster client buffer full, disconnect", zap.Uint64("client", id))
+		}
+	}
+}
+
+func (b *SSEBroadcaster) ClientCount() int {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return len(b.clients)
+}
+
+// Dropped
+/* @Description: 因缓冲区满被断开的客户端次数
+ * @return uint64
+ */
+func (b *SSEBroadcaster) Dropped() uint64 {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.dropped
+}
+
+// Close
+/* @Description: 断开所有客户端
+ */
+func (b *SSEBroadcaster) Close() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	for id := range b.clients {
+		b.removeLocked(id)
+	}
+}
+
+// Handler
+/* @Description: gin 路由处理函数，如 router.GET("/stream", b.Handler())
+ * @return gin.HandlerFunc
+ */
+func (b *SSEBroadcaster) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		b.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+func (b *SSEBroadcaster) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	client, replay := b.register(req.Header.Get("Last-Event-ID"))
+	defer b.remove(client.id)
+
+	if b.cfg.Retry > 0 {
+		fmt.Fprintf(w, "retry: %d\n\n", b.cfg.Retry)
+	}
+	for _, msg := range replay {
+		writeSSEEvent(w, msg)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(b.cfg.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case msg := <-client.ch:
+			writeSSEEvent(w, msg)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case <-client.done:
+			return
+		case <-req.Context().Done():
+			return
+		}
+	}
+}
+
+func (b *SSEBroadcaster) register(lastEventID string) (*sseClient, []*SSEEvent) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.clientID++
+	client := &sseClient{
+		id:   b.clientID,
+		ch:   make(chan *SSEEvent, b.cfg.ClientBuffer),
+		done: make(chan struct{}),
+	}
+	b.clients[client.id] = client
+
+	var replay []*SSEEvent
+	if last, err := strconv.ParseUint(lastEventID, 10, 64); "" != lastEventID && nil == err && nil != b.history {
+		//ID 递增，从尾部找到第一个已收到的为止
+		b.history.Range(func(_ int, msg *SSEEvent) bool {
+			if id, _ := strconv.ParseUint(msg.ID, 10, 64); id <= last {
+				return false
+			}
+			replay = append(replay, msg)
+			return true
+		})
+		for i, j := 0, len(replay)-1; i < j; i, j = i+1, j-1 {
+			replay[i], replay[j] = replay[j], replay[i]
+		}
+	}
+
+	return client, replay
+}
+
+func (b *SSEBroadcaster) remove(id uint64) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.removeLocked(id)
+}
+
+func (b *SSEBroadcaster) removeLocked(id uint64) {
+	c, ok := b.clients[id]
+	if !ok {
+		return
+	}
+	delete(b.clients, id)
+	if !c.closed {
+		c.closed = true
+		close(c.done)
+	}
+}
+
+func writeSSEEvent(w http.ResponseWriter, msg *SSEEvent) {
+	var sb strings.Builder
+	if "" != msg.ID {
+		sb.WriteString("id: " + msg.ID + "\n")
+	}
+	if "" != msg.Event && SSEDefaultEvent != msg.Event {
+		sb.WriteString("event: " + msg.Event + "\n")
+	}
+	if msg.Retry > 0 {
+		sb.WriteString("retry: " + strconv.Itoa(msg.Retry) + "\n")
+	}
+	for _, line := range strings.Split(msg.Data, "\n") {
+		sb.WriteString("data: " + line + "\n")
+	}
+	sb.WriteString("\n")
+	_, _ = w.Write([]byte(sb.String()))
+}
@@ -0,0 +1,164 @@
+package network
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: sse_test
+ * @Date: 2026-10-19 11:00 下午
+ */
+
+func Test_SSEAgentParse(t *testing.T) {
+	initTestLogger(t)
+
+	resumed := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if last := r.Header.Get("Last-Event-ID"); "" != last {
+			resumed <- last
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "retry: 0\n: comment\n\nid: 7\nevent: tick\ndata: a\ndata:b\n\ndata: plain\n\n")
+	}))
+	defer srv.Close()
+
+	agent, err := NewSSEAgent(srv.URL, 0, "/stream", false)
+	if nil != err {
+		t.Fatal(err)
+	}
+	events := make(chan *SSEEvent, 2)
+	agent.On("tick", func(_ *SSEAgent, e *SSEEvent) {
+		events <- e
+	})
+	agent.OnMessage = func(_ *SSEAgent, e *SSEEvent) {
+		events <- e
+	}
+	agent.Connect()
+	defer agent.Close()
+
+	tick := <-events
+	if "7" != tick.ID || "a\nb" != tick.Data {
+		t.Fatalf("unexpected tick %+v", tick)
+	}
+	if plain := <-events; SSEDefaultEvent != plain.Event || "plain" != plain.Data {
+		t.Fatalf("unexpected message %+v", plain)
+	}
+
+	//retry: 0 按下限重连，并带上 Last-Event-ID
+	select {
+	case last := <-resumed:
+		if "7" != last {
+			t.Fatalf("unexpected Last-Event-ID %s", last)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("expect resume")
+	}
+}
+
+func Test_SSEAgentReconnectIdle(t *testing.T) {
+	initTestLogger(t)
+
+	requests := make(chan string, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r.Header.Get("Last-Event-ID")
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "retry: 0\nid: 1\ndata: first\n\n")
+		w.(http.Flusher).Flush()
+		//之后不再发送数据
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	agent, err := NewSSEAgent(srv.URL, 0, "/stream", false)
+	if nil != err {
+		t.Fatal(err)
+	}
+	closed := make(chan error, 4)
+	agent.OnClose = func(_ *SSEAgent, err error) {
+		closed <- err
+	}
+	agent.Connect()
+	defer agent.Close()
+
+	if err = <-agent.WaitForConnected(); nil != err {
+		t.Fatal(err)
+	}
+	<-requests
+	//等待 id 解析完成
+	for i := 0; "1" != agent.LastEventID(); i++ {
+		if i > 300 {
+			t.Fatal("expect last event id")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	agent.Reconnect()
+	select {
+	case err = <-closed:
+		if nil == err || !strings.Contains(err.Error(), "reconnect") {
+			t.Fatalf("unexpected close err %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("idle stream not interrupted by Reconnect")
+	}
+	select {
+	case last := <-requests:
+		if "1" != last {
+			t.Fatalf("unexpected Last-Event-ID %s", last)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("expect reconnect")
+	}
+}
+
+func Test_SSEBroadcasterResume(t *testing.T) {
+	initTestLogger(t)
+
+	b := NewSSEBroadcaster(SSEBroadcasterConfig{History: 2})
+	srv := httptest.NewServer(b)
+	defer srv.Close()
+	defer b.Close()
+
+	b.Broadcast("", "one")
+	b.Broadcast("tick", "two\nlines")
+	b.Broadcast("", "three")
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Last-Event-ID", "0")
+	resp, err := http.DefaultClient.Do(req)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	//历史只保留最近2条
+	expect := "id: 2\nevent: tick\ndata: two\ndata: lines\n\nid: 3\ndata: three\n\n"
+	reader := bufio.NewReader(resp.Body)
+	var got strings.Builder
+	for got.Len() < len(expect) {
+		line, err := reader.ReadString('\n')
+		if nil != err {
+			t.Fatal(err)
+		}
+		got.WriteString(line)
+	}
+	if expect != got.String() {
+		t.Fatalf("unexpected replay %q", got.String())
+	}
+
+	b.Broadcast("", "four")
+	line, _ := reader.ReadString('\n')
+	if "id: 4\n" != line {
+		t.Fatalf("unexpected live event %q", line)
+	}
+}
@@ -0,0 +1,269 @@
+package network
+
+/**
+ * @Author: lee
+ * @Description: 原始tcp客户端，断线自动重连，分帧方式可配置
+ * @File: tcp
+ * @Date: 2026-10-19 4:40 下午
+ */
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"github.com/0DeOrg/gutils/logutils"
+	"go.uber.org/zap"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type TCPAgent struct {
+	NetAgentBase
+	addr         string
+	conn         net.Conn
+	framer       Framer
+	reqChan      chan []byte
+	mtx          sync.Mutex
+	errConn      error
+	TLSConfig    *tls.Config             //非空时使用tls连接
+	DialTimeout  time.Duration           //连接超时
+	ReadTimeout  time.Duration           //读超时，0不设置，超时视为断线
+	WriteTimeout time.Duration           //写超时，0不设置
+	OnMessage    func(*TCPAgent, string) //收到一帧消息回调
+	OnSend       func(*TCPAgent, string) //发送消息回调
+	OnClose      func(*TCPAgent)
+	OnConnected  func()
+}
+
+var _ SocketInterface = (*TCPAgent)(nil)
+
+func NewTCPAgent(host string, port uint, framer Framer) *TCPAgent {
+	addr := strings.TrimSpace(host)
+	if 0 != port {
+		addr = net.JoinHostPort(addr, strconv.FormatUint(uint64(port), 10))
+	}
+
+	if nil == framer {
+		framer = NewLineFramer()
+	}
+
+	ret := &TCPAgent{
+		addr:        addr,
+		framer:      framer,
+		reqChan:     make(chan []byte, 128),
+		DialTimeout: 5 * time.Second,
+		NetAgentBase: NetAgentBase{
+			timeout: 5000,
+		},
+	}
+
+	return ret
+}
+
+func (t *TCPAgent) Addr() string {
+	return t.addr
+}
+
+func (t *TCPAgent) Connect() {
+	go func() {
+		for {
+			if t.IsClosed() {
+				break
+			}
+
+			if !t.IsAlive() && !t.IsClosed() {
+				if err := t.dial(); nil != err {
+					logutils.Limited("TCPAgent dial:"+t.addr).Warn("TCPAgent dial fatal", zap.Error(err), zap.String("addr", t.addr))
+				}
+			}
+
+			time.Sleep(time.Duration(t.timeout) * time.Millisecond)
+		}
+	}()
+	t.doSendThread()
+}
+
+func (t *TCPAgent) Reconnect() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.closeConnLocked()
+}
+
+func (t *TCPAgent) Close() {
+	t.setClosed()
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.closeConnLocked()
+}
+
+// Send
+/* @Description: 发送一帧，断线时丢弃
+ * @param msg string
+ */
+func (t *TCPAgent) Send(msg string) {
+	t.SendBytes([]byte(msg))
+}
+
+func (t *TCPAgent) SendBytes(data []byte) {
+	//断线了就不发了减少sendMsg阻塞
+	if !t.IsAlive() {
+		return
+	}
+	t.reqChan <- data
+}
+
+func (t *TCPAgent) WaitForConnected() <-chan error {
+	ret := make(chan error, 1)
+	go func() {
+		tick := time.NewTicker(100 * time.Millisecond)
+		defer tick.Stop()
+		timeout := time.After(30 * time.Second)
+		for {
+			select {
+			case <-tick.C:
+				if t.IsAlive() {
+					ret <- nil
+					return
+				}
+			case <-timeout:
+				ret <- fmt.Errorf("wait for tcp connect time out 30s, addr: %s, err: %v", t.addr, t.errConn)
+				return
+			}
+		}
+	}()
+
+	return ret
+}
+
+func (t *TCPAgent) dial() error {
+	dialer := &net.Dialer{Timeout: t.DialTimeout}
+	var conn net.Conn
+	var err error
+	if nil != t.TLSConfig {
+		conn, err = tls.DialWithDialer(dialer, "tcp", t.addr, t.TLSConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", t.addr)
+	}
+	if nil != err {
+		t.errConn = err
+		return err
+	}
+
+	t.mtx.Lock()
+	t.conn = conn
+	t.mtx.Unlock()
+
+	//先设置alive，OnConnected 里面可能会发送消息
+	t.setAlive(true)
+	t.errConn = nil
+
+	go t.doReceive(conn)
+
+	if nil != t.OnConnected {
+		t.OnConnected()
+	}
+
+	return nil
+}
+
+func (t *TCPAgent) closeConnLocked() {
+	t.setAlive(false)
+	if nil != t.conn {
+		_ = t.conn.Close()
+		t.conn = nil
+	}
+}
+
+// lost 连接出错时关闭，只处理当前连接，避免误关重连后的新连接
+func (t *TCPAgent) lost(conn net.Conn) {
+	t.mtx.Lock()
+	if t.conn != conn {
+		t.mtx.Unlock()
+		return
+	}
+	t.closeConnLocked()
+	t.mtx.Unlock()
+
+	if nil != t.OnClose {
+		t.OnClose(t)
+	}
+}
+
+func (t *TCPAgent) doReceive(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	for {
+		if t.IsClosed() {
+			return
+		}
+
+		if t.ReadTimeout > 0 {
+			_ = conn.SetReadDeadline(time.Now().Add(t.ReadTimeout))
+		}
+
+		frame, err := t.framer.ReadFrame(reader)
+		if nil != err {
+			if !t.IsClosed() {
+				logutils.Warn("TCPAgent doReceive fatal", zap.String("addr", t.addr), zap.Error(err))
+			}
+			t.lost(conn)
+			return
+		}
+
+		if nil != t.OnMessage {
+			t.OnMessage(t, string(frame))
+		}
+	}
+}
+
+func (t *TCPAgent) doSendThread() {
+	go func() {
+		for {
+			if t.IsClosed() {
+				break
+			}
+
+			if !t.IsAlive() {
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+
+			var data []byte
+			select {
+			case data = <-t.reqChan:
+			case <-time.After(time.Second):
+				continue
+			}
+
+			//内容不合法只丢弃该条，不影响连接
+			frame, err := t.framer.EncodeFrame(data)
+			if nil != err {
+				logutils.Limited("TCPAgent encode:"+t.addr).Warn("TCPAgent encode frame fatal", zap.String("addr", t.addr), zap.Error(err))
+				continue
+			}
+
+			t.mtx.Lock()
+			conn := t.conn
+			t.mtx.Unlock()
+			if nil == conn {
+				continue
+			}
+
+			if t.WriteTimeout > 0 {
+				_ = conn.SetWriteDeadline(time.Now().Add(t.WriteTimeout))
+			}
+
+			if _, err = conn.Write(frame); nil != err {
+				logutils.Limited("TCPAgent send:"+t.addr).Warn("TCPAgent doSendThread fatal", zap.String("addr", t.addr), zap.Error(err))
+				t.lost(conn)
+				continue
+			}
+
+			if nil != t.OnSend {
+				t.OnSend(t, string(data))
+			}
+		}
+	}()
+}
@@ -0,0 +1,111 @@
+package network
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: tcp_test
+ * @Date: 2026-10-19 11:30 下午
+ */
+
+// Test_TCPAgent 内容不合法的帧只丢弃，不断开连接
+func Test_TCPAgent(t *testing.T) {
+	initTestLogger(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	accepted := make(chan struct{}, 4)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if nil != err {
+				return
+			}
+			accepted <- struct{}{}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					_, _ = conn.Write([]byte("echo " + scanner.Text() + "\n"))
+				}
+			}(conn)
+		}
+	}()
+
+	agent := NewTCPAgent(ln.Addr().String(), 0, nil)
+	received := make(chan string, 4)
+	agent.OnMessage = func(_ *TCPAgent, msg string) {
+		received <- msg
+	}
+	agent.Connect()
+	defer agent.Close()
+	if err = <-agent.WaitForConnected(); nil != err {
+		t.Fatal(err)
+	}
+
+	agent.Send("bad\nframe")
+	agent.Send("hello")
+	select {
+	case msg := <-received:
+		if "echo hello" != msg {
+			t.Fatalf("unexpected echo %q", msg)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("expect echo")
+	}
+	if 1 != len(accepted) || !agent.IsAlive() {
+		t.Fatalf("expect one connection kept alive, accepted %d", len(accepted))
+	}
+}
+
+func Test_UDPAgent(t *testing.T) {
+	initTestLogger(t)
+
+	server, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	go func() {
+		buf := make([]byte, maxUDPPacketSize)
+		for {
+			n, addr, err := server.ReadFromUDP(buf)
+			if nil != err {
+				return
+			}
+			_, _ = server.WriteToUDP(buf[:n], addr)
+		}
+	}()
+
+	framer, _ := NewFixedSizeFramer(4)
+	agent := NewUDPAgent("127.0.0.1", uint(server.LocalAddr().(*net.UDPAddr).Port), framer)
+	received := make(chan string, 4)
+	agent.OnMessage = func(_ *UDPAgent, msg string) {
+		received <- msg
+	}
+	agent.Connect()
+	defer agent.Close()
+	if err = <-agent.WaitForConnected(); nil != err {
+		t.Fatal(err)
+	}
+
+	agent.Send("toolong")
+	agent.Send("ping")
+	select {
+	case msg := <-received:
+		if "ping" != msg {
+			t.Fatalf("unexpected echo %q", msg)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("expect echo")
+	}
+}
@@ -0,0 +1,313 @@
+package network
+
+/**
+ * @Author: lee
+ * @Description: udp客户端，支持单播和组播，出错时自动重建socket
+ * @File: udp
+ * @Date: 2026-10-19 5:05 下午
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/0DeOrg/gutils/logutils"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxUDPPacketSize = 65535
+
+type UDPAgent struct {
+	NetAgentBase
+	addr         string
+	remote       *net.UDPAddr
+	conn         *net.UDPConn
+	multicast    bool
+	framer       Framer
+	reqChan      chan []byte
+	mtx          sync.Mutex
+	errConn      error
+	Interface    *net.Interface          //组播使用的网卡，nil 为系统默认
+	LocalAddr    string                  //单播时绑定的本地地址，空为系统分配
+	ReadTimeout  time.Duration           //读超时，0不设置，超时后重建socket
+	WriteTimeout time.Duration           //写超时，0不设置
+	OnMessage    func(*UDPAgent, string) //收到一帧消息回调
+	OnSend       func(*UDPAgent, string) //发送消息回调
+	OnClose      func(*UDPAgent)
+	OnConnected  func()
+}
+
+var _ SocketInterface = (*UDPAgent)(nil)
+
+// NewUDPAgent
+/* @Description: 创建udp客户端，host 为组播地址时加入组播组接收
+ * @param host string
+ * @param port uint
+ * @param framer Framer 为nil时一个数据报为一帧，否则按framer从数据报中切分
+ * @return *UDPAgent
+ */
+func NewUDPAgent(host string, port uint, framer Framer) *UDPAgent {
+	addr := strings.TrimSpace(host)
+	if 0 != port {
+		addr = net.JoinHostPort(addr, strconv.FormatUint(uint64(port), 10))
+	}
+
+	ret := &UDPAgent{
+		addr:    addr,
+		framer:  framer,
+		reqChan: make(chan []byte, 128),
+		NetAgentBase: NetAgentBase{
+			timeout: 5000,
+		},
+	}
+
+	return ret
+}
+
+func (u *UDPAgent) Addr() string {
+	return u.addr
+}
+
+func (u *UDPAgent) IsMulticast() bool {
+	return u.multicast
+}
+
+func (u *UDPAgent) Connect() {
+	go func() {
+		for {
+			if u.IsClosed() {
+				break
+			}
+
+			if !u.IsAlive() && !u.IsClosed() {
+				if err := u.dial(); nil != err {
+					logutils.Limited("UDPAgent dial:"+u.addr).Warn("UDPAgent dial fatal", zap.Error(err), zap.String("addr", u.addr))
+				}
+			}
+
+			time.Sleep(time.Duration(u.timeout) * time.Millisecond)
+		}
+	}()
+	u.doSendThread()
+}
+
+func (u *UDPAgent) Reconnect() {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	u.closeConnLocked()
+}
+
+func (u *UDPAgent) Close() {
+	u.setClosed()
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	u.closeConnLocked()
+}
+
+func (u *UDPAgent) Send(msg string) {
+	u.SendBytes([]byte(msg))
+}
+
+func (u *UDPAgent) SendBytes(data []byte) {
+	if !u.IsAlive() {
+		return
+	}
+	u.reqChan <- data
+}
+
+func (u *UDPAgent) WaitForConnected() <-chan error {
+	ret := make(chan error, 1)
+	go func() {
+		tick := time.NewTicker(100 * time.Millisecond)
+		defer tick.Stop()
+		timeout := time.After(30 * time.Second)
+		for {
+			select {
+			case <-tick.C:
+				if u.IsAlive() {
+					ret <- nil
+					return
+				}
+			case <-timeout:
+				ret <- fmt.Errorf("wait for udp connect time out 30s, addr: %s, err: %v", u.addr, u.errConn)
+				return
+			}
+		}
+	}()
+
+	return ret
+}
+
+func (u *UDPAgent) dial() error {
+	remote, err := net.ResolveUDPAddr("udp", u.addr)
+	if nil != err {
+		u.errConn = err
+		return err
+	}
+
+	var conn *net.UDPConn
+	multicast := remote.IP.IsMulticast()
+	if multicast {
+		conn, err = net.ListenMulticastUDP("udp", u.Interface, remote)
+	} else {
+		var local *net.UDPAddr
+		if "" != u.LocalAddr {
+			local, err = net.ResolveUDPAddr("udp", u.LocalAddr)
+			if nil != err {
+				u.errConn = err
+				return err
+			}
+		}
+		conn, err = net.DialUDP("udp", local, remote)
+	}
+	if nil != err {
+		u.errConn = err
+		return err
+	}
+
+	u.mtx.Lock()
+	u.conn = conn
+	u.remote = remote
+	u.multicast = multicast
+	u.mtx.Unlock()
+
+	u.setAlive(true)
+	u.errConn = nil
+
+	go u.doReceive(conn)
+
+	if nil != u.OnConnected {
+		u.OnConnected()
+	}
+
+	return nil
+}
+
+func (u *UDPAgent) closeConnLocked() {
+	u.setAlive(false)
+	if nil != u.conn {
+		_ = u.conn.Close()
+		u.conn = nil
+	}
+}
+
+func (u *UDPAgent) lost(conn *net.UDPConn) {
+	u.mtx.Lock()
+	if u.conn != conn {
+		u.mtx.Unlock()
+		return
+	}
+	u.closeConnLocked()
+	u.mtx.Unlock()
+
+	if nil != u.OnClose {
+		u.OnClose(u)
+	}
+}
+
+func (u *UDPAgent) doReceive(conn *net.UDPConn) {
+	buf := make([]byte, maxUDPPacketSize)
+	for {
+		if u.IsClosed() {
+			return
+		}
+
+		if u.ReadTimeout > 0 {
+			_ = conn.SetReadDeadline(time.Now().Add(u.ReadTimeout))
+		}
+
+		n, _, err := conn.ReadFromUDP(buf)
+		if nil != err {
+			if !u.IsClosed() {
+				logutils.Warn("UDPAgent doReceive fatal", zap.String("addr", u.addr), zap.Error(err))
+			}
+			u.lost(conn)
+			return
+		}
+
+		if nil == u.OnMessage {
+			continue
+		}
+
+		if nil == u.framer {
+			u.OnMessage(u, string(buf[:n]))
+			continue
+		}
+
+		reader := bufio.NewReader(bytes.NewReader(buf[:n]))
+		for {
+			frame, err := u.framer.ReadFrame(reader)
+			if nil != err {
+				if io.EOF != err {
+					logutils.Warn("UDPAgent read frame fatal", zap.String("addr", u.addr), zap.Error(err))
+				}
+				break
+			}
+			u.OnMessage(u, string(frame))
+		}
+	}
+}
+
+func (u *UDPAgent) doSendThread() {
+	go func() {
+		for {
+			if u.IsClosed() {
+				break
+			}
+
+			if !u.IsAlive() {
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+
+			var data []byte
+			select {
+			case data = <-u.reqChan:
+			case <-time.After(time.Second):
+				continue
+			}
+
+			u.mtx.Lock()
+			conn, remote, multicast := u.conn, u.remote, u.multicast
+			u.mtx.Unlock()
+			if nil == conn {
+				continue
+			}
+
+			packet := data
+			if nil != u.framer {
+				frame, err := u.framer.EncodeFrame(data)
+				if nil != err {
+					logutils.Limited("UDPAgent encode:"+u.addr).Warn("UDPAgent encode frame fatal", zap.String("addr", u.addr), zap.Error(err))
+					continue
+				}
+				packet = frame
+			}
+
+			if u.WriteTimeout > 0 {
+				_ = conn.SetWriteDeadline(time.Now().Add(u.WriteTimeout))
+			}
+
+			var err error
+			if multicast {
+				_, err = conn.WriteToUDP(packet, remote)
+			} else {
+				_, err = conn.Write(packet)
+			}
+			if nil != err {
+				logutils.Limited("UDPAgent send:"+u.addr).Warn("UDPAgent doSendThread fatal", zap.String("addr", u.addr), zap.Error(err))
+				continue
+			}
+
+			if nil != u.OnSend {
+				u.OnSend(u, string(data))
+			}
+		}
+	}()
+}
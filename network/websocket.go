@@ -64,10 +64,8 @@ func NewWebsocketAgent(host string, port uint, path string, isSecure bool, elaps
 
 	ret := &WebsocketAgent{
 		NetAgentBase: NetAgentBase{
-			URL:      rawUrl,
-			isAlive:  false,
-			timeout:  5000,
-			isClosed: false,
+			URL:     rawUrl,
+			timeout: 5000,
 		},
 		reqChan:    make(chan string, 128),
 		sendCache:  make([]string, 0, 16),
@@ -92,13 +90,13 @@ func (ws *WebsocketAgent) SetCloseHandler(handler func(code int, text string) er
 func (ws *WebsocketAgent) Connect() {
 	go func() {
 		for {
-			if ws.isClosed {
+			if ws.IsClosed() {
 				break
 			}
 
-			if !ws.isAlive && !ws.isClosed {
+			if !ws.IsAlive() && !ws.IsClosed() {
 				if err := ws.dial(); nil != err {
-					logutils.Warn("WebsocketAgent dial fatal", zap.Error(err), zap.String("url", ws.URL.String()))
+					logutils.Limited("WebsocketAgent dial:"+ws.URL.String()).Warn("WebsocketAgent dial fatal", zap.Error(err), zap.String("url", ws.URL.String()))
 				}
 			}
 
@@ -110,11 +108,11 @@ func (ws *WebsocketAgent) Connect() {
 }
 
 func (ws *WebsocketAgent) Reconnect() {
-	ws.isAlive = false
+	ws.setAlive(false)
 }
 
 func (ws *WebsocketAgent) Close() error {
-	ws.isClosed = true
+	ws.setClosed()
 	if nil != ws.client {
 		return ws.client.Close()
 	}
@@ -123,7 +121,7 @@ func (ws *WebsocketAgent) Close() error {
 
 func (ws *WebsocketAgent) Send(msg string) {
 	//断线了就不发了减少sendMsg阻塞
-	if !ws.isAlive {
+	if !ws.IsAlive() {
 		return
 	}
 	messageType := fmt.Sprintf("%02d", websocket.TextMessage)
@@ -133,7 +131,7 @@ func (ws *WebsocketAgent) Send(msg string) {
 
 func (ws *WebsocketAgent) SendPongMsg(data []byte) {
 	//断线了就不发了减少sendMsg阻塞
-	if !ws.isAlive {
+	if !ws.IsAlive() {
 		return
 	}
 	messageType := fmt.Sprintf("%02d", websocket.PongMessage)
@@ -141,7 +139,7 @@ func (ws *WebsocketAgent) SendPongMsg(data []byte) {
 }
 func (ws *WebsocketAgent) SendPingMsg(data []byte) {
 	//断线了就不发了减少sendMsg阻塞
-	if !ws.isAlive {
+	if !ws.IsAlive() {
 		return
 	}
 	messageType := fmt.Sprintf("%02d", websocket.PingMessage)
@@ -157,7 +155,7 @@ func (ws *WebsocketAgent) WaitForConnected() <-chan error {
 			select {
 			case <-tick:
 				{
-					if ws.isAlive {
+					if ws.IsAlive() {
 						ret <- nil
 						return
 					}
@@ -197,7 +195,7 @@ func (ws *WebsocketAgent) dial() error {
 	}
 
 	ws.client.SetCloseHandler(func(code int, text string) error {
-		ws.isAlive = false
+		ws.setAlive(false)
 		if nil != ws.OnClose {
 			ws.OnClose(ws)
 		}
@@ -207,7 +205,7 @@ func (ws *WebsocketAgent) dial() error {
 	})
 
 	//将alive设置提前，不能放在ws.OnConnected()后面，里面可能会发送消息，如果管道满了导致阻塞alive将不被设置，发送协程因alive未设置不发送消息了导致死锁了
-	ws.isAlive = true
+	ws.setAlive(true)
 
 	if nil != ws.OnConnected {
 		ws.OnConnected()
@@ -222,11 +220,11 @@ func (ws *WebsocketAgent) doSendThread() {
 	//logutils.Warn("doSendThread", zap.String("url", ws.URL.String()))
 	go func() {
 		for {
-			if ws.isClosed {
+			if ws.IsClosed() {
 				break
 			}
 
-			if !ws.isAlive {
+			if !ws.IsAlive() {
 				time.Sleep(100 * time.Millisecond)
 				continue
 			}
@@ -251,8 +249,8 @@ func (ws *WebsocketAgent) doSendThread() {
 				}
 
 				if nil != err {
-					ws.isAlive = false
-					logutils.Warn("doSendThread fatal", zap.String("url", ws.URL.String()), zap.Error(err))
+					ws.setAlive(false)
+					logutils.Limited("WebsocketAgent send:"+ws.URL.String()).Warn("doSendThread fatal", zap.String("url", ws.URL.String()), zap.Error(err))
 					time.Sleep(100 * time.Millisecond)
 					//控制消息不用重发了
 					if messageType != websocket.TextMessage && messageType != websocket.BinaryMessage {
@@ -287,18 +285,18 @@ func (ws *WebsocketAgent) doReceiveThread() {
 	//logutils.Warn("doReceiveThread", zap.String("url", ws.URL.String()))
 	go func() {
 		for {
-			if ws.isClosed {
+			if ws.IsClosed() {
 				break
 			}
-			if !ws.isAlive {
+			if !ws.IsAlive() {
 				time.Sleep(100 * time.Millisecond)
 				continue
 			}
 
 			_, msg, err := ws.client.ReadMessage()
 			if nil != err {
-				ws.isAlive = false
-				logutils.Warn("doReceiveThread fatal", zap.String("url", ws.URL.String()), zap.Error(err))
+				ws.setAlive(false)
+				logutils.Limited("WebsocketAgent receive:"+ws.URL.String()).Warn("doReceiveThread fatal", zap.String("url", ws.URL.String()), zap.Error(err))
 				continue
 			}
 
@@ -0,0 +1,429 @@
+package network
+
+/**
+ * @Author: lee
+ * @Description: 多连接websocket池，按单连接最大订阅数将订阅分片到多个连接上
+ * @File: websocket_pool
+ * @Date: 2026-10-19 2:30 下午
+ */
+
+import (
+	"fmt"
+	"github.com/0DeOrg/gutils/logutils"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPoolMaxPerConn  = 50
+	defaultPoolDeadTimeout = 60 * time.Second
+	defaultPoolCheckElapse = 5 * time.Second
+)
+
+// SubscribeFormatter 将一批订阅主题格式化为交易所要求的订阅/取消订阅消息
+type SubscribeFormatter func(topics []string) []string
+
+type WebsocketPoolConfig struct {
+	Host        string
+	Port        uint
+	Path        string
+	IsSecure    bool
+	Elapse      int           //单个连接发送消息时间间隔 单位ms
+	MaxPerConn  int           //单个连接最大订阅数量
+	MaxConn     int           //最大连接数量，0表示不限制
+	DeadTimeout time.Duration //连接断开超过该时间认为连接彻底失效，重新分配其订阅
+}
+
+type poolConn struct {
+	id        int
+	agent     *WebsocketAgent
+	topics    map[string]struct{}
+	downSince time.Time //断开开始时间，零值表示连接正常
+}
+
+type WebsocketPool struct {
+	cfg               WebsocketPoolConfig
+	conns             []*poolConn
+	topicConn         map[string]*poolConn
+	pending           map[string]struct{} //重新分配时达到最大连接数未能分配的订阅，下次检查时重试
+	mtx               sync.Mutex
+	seq               int
+	isClosed          bool
+	stop              chan struct{}
+	OnMessage         func(*WebsocketAgent, string) //所有连接的消息统一回调
+	OnConnected       func(*WebsocketAgent)         //单个连接连上回调
+	FormatSubscribe   SubscribeFormatter            //订阅消息格式化
+	FormatUnsubscribe SubscribeFormatter            //取消订阅消息格式化
+	OnRebalance       func(topics []string, to int) //订阅被重新分配回调
+	connect           func(*WebsocketAgent)         //新连接发起连接，测试时替换
+}
+
+func NewWebsocketPool(cfg WebsocketPoolConfig, subscribe SubscribeFormatter, unsubscribe SubscribeFormatter) *WebsocketPool {
+	if cfg.MaxPerConn <= 0 {
+		cfg.MaxPerConn = defaultPoolMaxPerConn
+	}
+
+	if cfg.DeadTimeout <= 0 {
+		cfg.DeadTimeout = defaultPoolDeadTimeout
+	}
+
+	ret := &WebsocketPool{
+		cfg:               cfg,
+		conns:             make([]*poolConn, 0, 4),
+		topicConn:         make(map[string]*poolConn),
+		pending:           make(map[string]struct{}),
+		stop:              make(chan struct{}),
+		FormatSubscribe:   subscribe,
+		FormatUnsubscribe: unsubscribe,
+		connect:           (*WebsocketAgent).Connect,
+	}
+
+	return ret
+}
+
+// poolCall 持锁时收集的发送、新建连接及关闭操作，解锁后执行，避免 Send 阻塞或回调重入时死锁
+type poolCall struct {
+	sends     []poolSend
+	connects  []*WebsocketAgent
+	closes    []*poolConn
+	rebalance map[int][]string
+}
+
+type poolSend struct {
+	agent *WebsocketAgent
+	msgs  []string
+}
+
+// Start
+/* @Description: 启动连接健康检查，连接彻底失效后重新分配订阅
+ */
+func (p *WebsocketPool) Start() {
+	go func() {
+		ticker := time.NewTicker(defaultPoolCheckElapse)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.checkConns()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (p *WebsocketPool) Close() {
+	p.mtx.Lock()
+	if p.isClosed {
+		p.mtx.Unlock()
+		return
+	}
+	p.isClosed = true
+	close(p.stop)
+	call := &poolCall{closes: p.conns}
+	p.conns = nil
+	p.topicConn = make(map[string]*poolConn)
+	p.pending = make(map[string]struct{})
+	p.mtx.Unlock()
+
+	p.do(call)
+}
+
+// Subscribe
+/* @Description: 订阅主题，已订阅的会被忽略，按单连接最大订阅数分配到连接上
+ * @param topics ...string
+ * @return error 达到最大连接数仍无法分配时返回
+ */
+func (p *WebsocketPool) Subscribe(topics ...string) error {
+	p.mtx.Lock()
+	if p.isClosed {
+		p.mtx.Unlock()
+		return fmt.Errorf("WebsocketPool is closed")
+	}
+
+	call := &poolCall{}
+	_, _, err := p.assign(p.filterNew(topics), call)
+	p.mtx.Unlock()
+
+	p.do(call)
+	return err
+}
+
+// Unsubscribe
+/* @Description: 取消订阅主题，没有订阅的连接将被关闭
+ * @param topics ...string
+ */
+func (p *WebsocketPool) Unsubscribe(topics ...string) {
+	p.mtx.Lock()
+	byConn := make(map[*poolConn][]string)
+	for _, topic := range topics {
+		delete(p.pending, topic)
+		conn, ok := p.topicConn[topic]
+		if !ok {
+			continue
+		}
+		delete(p.topicConn, topic)
+		delete(conn.topics, topic)
+		byConn[conn] = append(byConn[conn], topic)
+	}
+
+	call := &poolCall{}
+	for conn, list := range byConn {
+		if 0 == len(conn.topics) {
+			p.removeConn(conn)
+			call.closes = append(call.closes, conn)
+			continue
+		}
+		p.send(call, conn, p.FormatUnsubscribe, list)
+	}
+	p.mtx.Unlock()
+
+	p.do(call)
+}
+
+// Topics
+/* @Description: 当前所有订阅，包括等待重新分配的
+ * @return []string
+ */
+func (p *WebsocketPool) Topics() []string {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	ret := make([]string, 0, len(p.topicConn)+len(p.pending))
+	for topic := range p.topicConn {
+		ret = append(ret, topic)
+	}
+	for topic := range p.pending {
+		ret = append(ret, topic)
+	}
+	return ret
+}
+
+// PendingTopics
+/* @Description: 连接失效后达到最大连接数未能重新分配的订阅，每次健康检查时重试
+ * @return []string
+ */
+func (p *WebsocketPool) PendingTopics() []string {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	ret := make([]string, 0, len(p.pending))
+	for topic := range p.pending {
+		ret = append(ret, topic)
+	}
+	return ret
+}
+
+// ConnCount
+/* @Description: 当前连接数量
+ * @return int
+ */
+func (p *WebsocketPool) ConnCount() int {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return len(p.conns)
+}
+
+func (p *WebsocketPool) filterNew(topics []string) []string {
+	ret := make([]string, 0, len(topics))
+	seen := make(map[string]struct{}, len(topics))
+	for _, topic := range topics {
+		if _, ok := p.topicConn[topic]; ok {
+			continue
+		}
+		if _, ok := p.pending[topic]; ok {
+			continue
+		}
+		if _, ok := seen[topic]; ok {
+			continue
+		}
+		seen[topic] = struct{}{}
+		ret = append(ret, topic)
+	}
+	return ret
+}
+
+// assign 将主题分配到未满的正常连接上，不够时新建连接，需持有锁，同时返回未能分配的主题
+func (p *WebsocketPool) assign(topics []string, call *poolCall) (map[*poolConn][]string, []string, error) {
+	byConn := make(map[*poolConn][]string)
+	remain := topics
+	for _, conn := range p.conns {
+		if len(remain) == 0 {
+			break
+		}
+		if !conn.downSince.IsZero() {
+			continue
+		}
+		remain = p.fill(conn, remain, byConn)
+	}
+
+	var err error
+	for len(remain) > 0 {
+		if p.cfg.MaxConn > 0 && len(p.conns) >= p.cfg.MaxConn {
+			err = fmt.Errorf("WebsocketPool reach max conn %d, %d topics unassigned", p.cfg.MaxConn, len(remain))
+			break
+		}
+		conn := p.newConn()
+		call.connects = append(call.connects, conn.agent)
+		remain = p.fill(conn, remain, byConn)
+	}
+
+	for conn, list := range byConn {
+		p.send(call, conn, p.FormatSubscribe, list)
+	}
+
+	return byConn, remain, err
+}
+
+func (p *WebsocketPool) fill(conn *poolConn, topics []string, byConn map[*poolConn][]string) []string {
+	free := p.cfg.MaxPerConn - len(conn.topics)
+	if free <= 0 {
+		return topics
+	}
+	if free > len(topics) {
+		free = len(topics)
+	}
+
+	for _, topic := range topics[:free] {
+		conn.topics[topic] = struct{}{}
+		p.topicConn[topic] = conn
+	}
+	byConn[conn] = append(byConn[conn], topics[:free]...)
+
+	return topics[free:]
+}
+
+// newConn 新建连接，需持有锁，解锁后由 do 发起连接
+func (p *WebsocketPool) newConn() *poolConn {
+	agent := NewWebsocketAgent(p.cfg.Host, p.cfg.Port, p.cfg.Path, p.cfg.IsSecure, p.cfg.Elapse)
+	conn := &poolConn{
+		id:     p.seq,
+		agent:  agent,
+		topics: make(map[string]struct{}),
+	}
+	p.seq++
+
+	agent.OnMessage = func(ws *WebsocketAgent, msg string) {
+		if nil != p.OnMessage {
+			p.OnMessage(ws, msg)
+		}
+	}
+
+	//重连后重新订阅该连接上的所有主题
+	agent.OnConnected = func() {
+		p.mtx.Lock()
+		conn.downSince = time.Time{}
+		list := make([]string, 0, len(conn.topics))
+		for topic := range conn.topics {
+			list = append(list, topic)
+		}
+		call := &poolCall{}
+		p.send(call, conn, p.FormatSubscribe, list)
+		p.mtx.Unlock()
+
+		p.do(call)
+		if nil != p.OnConnected {
+			p.OnConnected(agent)
+		}
+	}
+
+	p.conns = append(p.conns, conn)
+	return conn
+}
+
+func (p *WebsocketPool) removeConn(conn *poolConn) {
+	for i, c := range p.conns {
+		if c == conn {
+			p.conns = append(p.conns[:i], p.conns[i+1:]...)
+			return
+		}
+	}
+}
+
+// send 格式化订阅消息，需持有锁
+func (p *WebsocketPool) send(call *poolCall, conn *poolConn, formatter SubscribeFormatter, topics []string) {
+	if nil == formatter || len(topics) == 0 {
+		return
+	}
+	call.sends = append(call.sends, poolSend{agent: conn.agent, msgs: formatter(topics)})
+}
+
+// do 解锁后执行收集的操作
+func (p *WebsocketPool) do(call *poolCall) {
+	for _, conn := range call.closes {
+		if err := conn.agent.Close(); nil != err {
+			logutils.Warn("WebsocketPool close agent fatal", zap.Int("id", conn.id), zap.Error(err))
+		}
+	}
+	for _, agent := range call.connects {
+		p.connect(agent)
+	}
+	for _, s := range call.sends {
+		for _, msg := range s.msgs {
+			s.agent.Send(msg)
+		}
+	}
+	if nil != p.OnRebalance {
+		for to, list := range call.rebalance {
+			p.OnRebalance(list, to)
+		}
+	}
+}
+
+// checkConns 检查连接状态，断开超过DeadTimeout的连接将被关闭，其订阅重新分配到其他连接，
+// 达到最大连接数未能分配的订阅保留到下次检查时重试
+func (p *WebsocketPool) checkConns() {
+	p.mtx.Lock()
+	if p.isClosed {
+		p.mtx.Unlock()
+		return
+	}
+
+	now := time.Now()
+	var dead []*poolConn
+	alive := p.conns[:0]
+	for _, conn := range p.conns {
+		if conn.agent.IsAlive() {
+			conn.downSince = time.Time{}
+		} else if conn.downSince.IsZero() {
+			conn.downSince = now
+		}
+		if !conn.downSince.IsZero() && now.Sub(conn.downSince) >= p.cfg.DeadTimeout {
+			dead = append(dead, conn)
+			continue
+		}
+		alive = append(alive, conn)
+	}
+	p.conns = alive
+
+	call := &poolCall{closes: dead, rebalance: make(map[int][]string)}
+	topics := make([]string, 0, len(p.pending))
+	for topic := range p.pending {
+		topics = append(topics, topic)
+	}
+	for _, conn := range dead {
+		logutils.Warn("WebsocketPool conn dead, rebalance", zap.Int("id", conn.id), zap.Int("topics", len(conn.topics)),
+			zap.String("url", conn.agent.URL.String()), zap.Duration("down", now.Sub(conn.downSince)))
+
+		for topic := range conn.topics {
+			delete(p.topicConn, topic)
+			topics = append(topics, topic)
+		}
+	}
+
+	if len(topics) > 0 {
+		byConn, remain, err := p.assign(topics, call)
+		p.pending = make(map[string]struct{}, len(remain))
+		for _, topic := range remain {
+			p.pending[topic] = struct{}{}
+		}
+		if nil != err {
+			logutils.Error("WebsocketPool rebalance fatal, retry next check", zap.Int("pending", len(remain)), zap.Error(err))
+		}
+		for to, list := range byConn {
+			call.rebalance[to.id] = append(call.rebalance[to.id], list...)
+		}
+	}
+	p.mtx.Unlock()
+
+	p.do(call)
+}
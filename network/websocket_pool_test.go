@@ -0,0 +1,147 @@
+package network
+
+import (
+	"github.com/0DeOrg/gutils/logutils"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: websocket_pool_test
+ * @Date: 2026-10-19 10:10 下午
+ */
+
+func initTestLogger(t *testing.T) {
+	if logutils.IsInit() {
+		return
+	}
+	cfg := logutils.DefaultZapConfig
+	cfg.Directory = t.TempDir()
+	cfg.LinkName = ""
+	cfg.LogInConsole = false
+	logutils.InitLogger(cfg)
+}
+
+func newTestPool() *WebsocketPool {
+	format := func(prefix string) SubscribeFormatter {
+		return func(topics []string) []string {
+			list := append([]string(nil), topics...)
+			sort.Strings(list)
+			return []string{prefix + strings.Join(list, ",")}
+		}
+	}
+	p := NewWebsocketPool(WebsocketPoolConfig{Host: "127.0.0.1", Path: "/ws", MaxPerConn: 2, DeadTimeout: time.Millisecond},
+		format("sub:"), format("unsub:"))
+	p.connect = func(agent *WebsocketAgent) {
+		agent.setAlive(true)
+	}
+	return p
+}
+
+func drain(agent *WebsocketAgent) []string {
+	var ret []string
+	for {
+		select {
+		case msg := <-agent.reqChan:
+			_, text := ParseMessage(msg)
+			ret = append(ret, text)
+		default:
+			return ret
+		}
+	}
+}
+
+func Test_WebsocketPoolShard(t *testing.T) {
+	initTestLogger(t)
+	p := newTestPool()
+	if err := p.Subscribe("a", "b", "c", "a", "d", "e"); nil != err {
+		t.Fatal(err)
+	}
+	if 3 != p.ConnCount() || 5 != len(p.Topics()) {
+		t.Fatalf("expect 3 conns 5 topics, got %d %v", p.ConnCount(), p.Topics())
+	}
+	for _, conn := range p.conns {
+		if msgs := drain(conn.agent); 1 != len(msgs) || !strings.HasPrefix(msgs[0], "sub:") {
+			t.Fatalf("unexpected sub msgs %v", msgs)
+		}
+	}
+
+	//连接上的订阅都取消后关闭连接
+	last := p.conns[2]
+	p.Unsubscribe("e")
+	if 2 != p.ConnCount() || !last.agent.IsClosed() {
+		t.Fatalf("expect empty conn closed, got %d", p.ConnCount())
+	}
+	p.Unsubscribe("a")
+	if msgs := drain(p.conns[0].agent); 1 != len(msgs) || "unsub:a" != msgs[0] {
+		t.Fatalf("unexpected unsub msgs %v", msgs)
+	}
+	p.Close()
+}
+
+func Test_WebsocketPoolRebalance(t *testing.T) {
+	initTestLogger(t)
+	p := newTestPool()
+	_ = p.Subscribe("a", "b", "c")
+
+	done := make(chan []string, 4)
+	p.OnRebalance = func(topics []string, to int) {
+		//回调中访问连接池不能死锁
+		_ = p.Topics()
+		done <- topics
+	}
+
+	dead := p.conns[0]
+	dead.agent.setAlive(false)
+	p.checkConns()
+	time.Sleep(5 * time.Millisecond)
+	p.checkConns()
+
+	var moved []string
+	for len(done) > 0 {
+		moved = append(moved, <-done...)
+	}
+	sort.Strings(moved)
+	if "a,b" != strings.Join(moved, ",") {
+		t.Fatalf("unexpected rebalance %v", moved)
+	}
+	if !dead.agent.IsClosed() || 2 != p.ConnCount() || 3 != len(p.Topics()) {
+		t.Fatalf("unexpected pool state, conns %d topics %v", p.ConnCount(), p.Topics())
+	}
+	for _, conn := range p.conns {
+		if conn == dead {
+			t.Fatal("dead conn not removed")
+		}
+	}
+	p.Close()
+}
+
+func Test_WebsocketPoolPending(t *testing.T) {
+	initTestLogger(t)
+	p := newTestPool()
+	_ = p.Subscribe("a", "b", "c")
+	//只剩一个连接的空位，另一个订阅等待下次检查
+	p.cfg.MaxConn = 1
+
+	p.conns[0].agent.setAlive(false)
+	p.checkConns()
+	time.Sleep(5 * time.Millisecond)
+	p.checkConns()
+	if 1 != p.ConnCount() || 1 != len(p.PendingTopics()) || 3 != len(p.Topics()) {
+		t.Fatalf("expect 1 pending topic, got conns %d pending %v topics %v", p.ConnCount(), p.PendingTopics(), p.Topics())
+	}
+	if err := p.Subscribe(p.PendingTopics()...); nil != err {
+		t.Fatalf("pending topic should not be subscribed again, err: %s", err.Error())
+	}
+
+	p.cfg.MaxConn = 2
+	p.checkConns()
+	if 2 != p.ConnCount() || 0 != len(p.PendingTopics()) || 3 != len(p.Topics()) {
+		t.Fatalf("expect pending topic assigned, got conns %d pending %v topics %v", p.ConnCount(), p.PendingTopics(), p.Topics())
+	}
+	p.Close()
+}
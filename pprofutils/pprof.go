@@ -2,6 +2,7 @@ package pprofutils
 
 import (
 	"fmt"
+	"github.com/gin-gonic/gin"
 	"log"
 	"net/http"
 	"net/http/pprof"
@@ -20,11 +21,7 @@ func InitPProf(cfg *PProfConfig) {
 	}
 
 	perfMux := http.NewServeMux()
-	perfMux.HandleFunc("/debug/pprof/", pprof.Index)
-	perfMux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
-	perfMux.HandleFunc("/debug/pprof/profile", pprof.Profile)
-	perfMux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
-	perfMux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	RegisterRoutes(perfMux)
 
 	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
 
@@ -41,3 +38,30 @@ func InitPProf(cfg *PProfConfig) {
 
 	log.Println("pprof enabled, addr: ", addr)
 }
+
+// RegisterRoutes
+/* @Description: 在已有的mux上挂载 /debug/pprof 路由
+ * @param mux *http.ServeMux
+ */
+func RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
+
+// RegisterGinRoutes
+/* @Description: 在gin上挂载 /debug/pprof 路由，与业务共用端口
+ * @param router gin.IRouter
+ */
+func RegisterGinRoutes(router gin.IRouter) {
+	group := router.Group("/debug/pprof")
+	group.GET("/", gin.WrapF(pprof.Index))
+	group.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	group.GET("/profile", gin.WrapF(pprof.Profile))
+	group.POST("/symbol", gin.WrapF(pprof.Symbol))
+	group.GET("/symbol", gin.WrapF(pprof.Symbol))
+	group.GET("/trace", gin.WrapF(pprof.Trace))
+	group.GET("/:name", gin.WrapF(pprof.Index))
+}
@@ -0,0 +1,224 @@
+package raftutils
+
+/**
+ * @Author: lee
+ * @Description: hclog.Logger 适配，raft 内部日志写入 logutils
+ * @File: logger
+ * @Date: 2026-10-19 10:10 下午
+ */
+
+import (
+	"fmt"
+	"github.com/0DeOrg/gutils/logutils"
+	"github.com/hashicorp/go-hclog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"log"
+	"strings"
+)
+
+type hcLogger struct {
+	sub     string //raft 命名日志下的子名称
+	name    string
+	implied []interface{}
+	level   hclog.Level
+}
+
+var _ hclog.Logger = (*hcLogger)(nil)
+
+// NewHCLogger
+/* @Description: 创建写入 logutils 的 hclog.Logger，在日志配置 loggers 中配置 raft 即可输出到单独的文件
+ * 每次输出时获取日志，日志初始化前输出到标准错误
+ * @param name string 日志名称，空为 raft
+ * @return hclog.Logger
+ */
+func NewHCLogger(name string) hclog.Logger {
+	if "" == name {
+		name = logutils.ModuleRaft
+	}
+	sub := ""
+	if logutils.ModuleRaft != name {
+		sub = name
+	}
+
+	return &hcLogger{
+		sub:   sub,
+		name:  name,
+		level: hclog.Trace,
+	}
+}
+
+func (l *hcLogger) Log(level hclog.Level, msg string, args ...interface{}) {
+	l.log(level, msg, args)
+}
+
+func (l *hcLogger) Trace(msg string, args ...interface{}) {
+	l.log(hclog.Trace, msg, args)
+}
+
+func (l *hcLogger) Debug(msg string, args ...interface{}) {
+	l.log(hclog.Debug, msg, args)
+}
+
+func (l *hcLogger) Info(msg string, args ...interface{}) {
+	l.log(hclog.Info, msg, args)
+}
+
+func (l *hcLogger) Warn(msg string, args ...interface{}) {
+	l.log(hclog.Warn, msg, args)
+}
+
+func (l *hcLogger) Error(msg string, args ...interface{}) {
+	l.log(hclog.Error, msg, args)
+}
+
+func (l *hcLogger) IsTrace() bool {
+	return l.enabled(hclog.Trace)
+}
+
+func (l *hcLogger) IsDebug() bool {
+	return l.enabled(hclog.Debug)
+}
+
+func (l *hcLogger) IsInfo() bool {
+	return l.enabled(hclog.Info)
+}
+
+func (l *hcLogger) IsWarn() bool {
+	return l.enabled(hclog.Warn)
+}
+
+func (l *hcLogger) IsError() bool {
+	return l.enabled(hclog.Error)
+}
+
+func (l *hcLogger) ImpliedArgs() []interface{} {
+	return l.implied
+}
+
+func (l *hcLogger) With(args ...interface{}) hclog.Logger {
+	ret := *l
+	ret.implied = make([]interface{}, 0, len(l.implied)+len(args))
+	ret.implied = append(ret.implied, l.implied...)
+	ret.implied = append(ret.implied, args...)
+	return &ret
+}
+
+func (l *hcLogger) Name() string {
+	return l.name
+}
+
+func (l *hcLogger) Named(name string) hclog.Logger {
+	ret := *l
+	ret.name = l.name + "." + name
+	ret.sub = name
+	if "" != l.sub {
+		ret.sub = l.sub + "." + name
+	}
+	return &ret
+}
+
+func (l *hcLogger) ResetNamed(name string) hclog.Logger {
+	ret := NewHCLogger(name).(*hcLogger)
+	ret.implied = l.implied
+	ret.level = l.level
+	return ret
+}
+
+// SetLevel 只在 logutils 级别的基础上额外过滤，不修改全局日志级别
+func (l *hcLogger) SetLevel(level hclog.Level) {
+	l.level = level
+}
+
+func (l *hcLogger) StandardLogger(opts *hclog.StandardLoggerOptions) *log.Logger {
+	return log.New(l.StandardWriter(opts), "", 0)
+}
+
+func (l *hcLogger) StandardWriter(opts *hclog.StandardLoggerOptions) io.Writer {
+	if nil == opts {
+		opts = &hclog.StandardLoggerOptions{}
+	}
+
+	lvl := zap.InfoLevel
+	infer := opts.InferLevels
+	if hclog.NoLevel != opts.ForceLevel {
+		lvl = toZapLevel(opts.ForceLevel)
+		infer = false
+	}
+	//log.Printf -> Logger.output -> StdWriter.Write，hcLogger 本身不在调用链上
+	return logutils.NewStdWriter(l.module().WithCallerSkip(2), lvl, infer)
+}
+
+// module hcLogger 的方法多一层调用
+func (l *hcLogger) module() *logutils.ZapLogModule {
+	module := logutils.NamedModule(logutils.ModuleRaft).WithCallerSkip(1)
+	if "" != l.sub {
+		module = module.Named(l.sub)
+	}
+	return module
+}
+
+func (l *hcLogger) enabled(level hclog.Level) bool {
+	return level >= l.level && l.module().Level().Enabled(toZapLevel(level))
+}
+
+func (l *hcLogger) log(level hclog.Level, msg string, args []interface{}) {
+	if level < l.level {
+		return
+	}
+
+	fields := toZapFields(l.implied, args)
+	module := l.module()
+	switch level {
+	case hclog.Trace, hclog.Debug:
+		module.Debug(msg, fields...)
+	case hclog.Warn:
+		module.Warn(msg, fields...)
+	case hclog.Error:
+		module.Error(msg, fields...)
+	default:
+		module.Info(msg, fields...)
+	}
+}
+
+func toZapLevel(level hclog.Level) zapcore.Level {
+	switch level {
+	case hclog.Trace, hclog.Debug:
+		return zap.DebugLevel
+	case hclog.Warn:
+		return zap.WarnLevel
+	case hclog.Error:
+		return zap.ErrorLevel
+	default:
+		return zap.InfoLevel
+	}
+}
+
+// toZapFields hclog 的参数为 key/value 交替，单个的 hclog.Format 为格式化内容
+func toZapFields(implied []interface{}, args []interface{}) []zap.Field {
+	if 1 == len(args) {
+		if format, ok := args[0].(hclog.Format); ok && len(format) > 0 {
+			args = []interface{}{"detail", fmt.Sprintf(fmt.Sprint(format[0]), format[1:]...)}
+		}
+	}
+
+	all := make([]interface{}, 0, len(implied)+len(args))
+	all = append(all, implied...)
+	all = append(all, args...)
+
+	fields := make([]zap.Field, 0, len(all)/2+1)
+	for i := 0; i < len(all); i += 2 {
+		if i+1 >= len(all) {
+			fields = append(fields, zap.Any("EXTRA_VALUE_AT_END", all[i]))
+			break
+		}
+		key := strings.TrimSpace(fmt.Sprint(all[i]))
+		if err, ok := all[i+1].(error); ok {
+			fields = append(fields, zap.NamedError(key, err))
+			continue
+		}
+		fields = append(fields, zap.Any(key, all[i+1]))
+	}
+	return fields
+}
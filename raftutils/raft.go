@@ -37,10 +37,10 @@ type RaftNode struct {
 }
 
 func NewRaftNode(options *options, fsm raft.FSM) (*RaftNode, error) {
-	logger := hclog.Default()
+	logger := NewHCLogger(logutils.ModuleRaft)
 	defaultCfg := raft.DefaultConfig()
 	defaultCfg.LocalID = options.serverID
-	defaultCfg.Logger = hclog.Default()
+	defaultCfg.Logger = logger
 	notifyCh := make(chan bool, 10)
 	defaultCfg.NotifyCh = notifyCh
 	defaultCfg.SnapshotInterval = options.snapInterval
@@ -51,7 +51,7 @@ func NewRaftNode(options *options, fsm raft.FSM) (*RaftNode, error) {
 	}
 
 	//raft节点内部的通信通道
-	transport, err := raft.NewTCPTransport(tcpAddr.String(), tcpAddr, 3, 3*time.Second, os.Stderr)
+	transport, err := raft.NewTCPTransportWithLogger(tcpAddr.String(), tcpAddr, 3, 3*time.Second, logger.Named("transport"))
 	if nil != err {
 		return nil, fmt.Errorf("NewRaftNode, NewTCPTransport err: %s", err.Error())
 	}
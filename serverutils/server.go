@@ -0,0 +1,237 @@
+package serverutils
+
+/**
+ * @Author: lee
+ * @Description: gin服务启动，健康检查、优雅退出及退出钩子
+ * @File: server
+ * @Date: 2026-10-19 5:40 下午
+ */
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/0DeOrg/gutils/dumputils"
+	"github.com/0DeOrg/gutils/logutils"
+	"github.com/0DeOrg/gutils/pprofutils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Server struct {
+	cfg         ServerConfig
+	Engine      *gin.Engine
+	srv         *http.Server
+	ready       int32
+	mtx         sync.Mutex
+	hooks       []*shutdownHook
+	readyChecks []*readyCheck
+	register    sync.Once
+	stopOnce    sync.Once
+	stopErr     error
+	done        chan struct{}
+}
+
+func NewServer(cfg ServerConfig) *Server {
+	if "" != cfg.Mode {
+		gin.SetMode(cfg.Mode)
+	}
+	if "" == cfg.HealthPath {
+		cfg.HealthPath = DefaultHealthPath
+	}
+	if "" == cfg.ReadyPath {
+		cfg.ReadyPath = DefaultReadyPath
+	}
+
+	engine := gin.New()
+	engine.Use(gin.Recovery())
+
+	ret := &Server{
+		cfg:    cfg,
+		Engine: engine,
+		done:   make(chan struct{}),
+	}
+
+	engine.GET(cfg.HealthPath, ret.handleHealth)
+	engine.GET(cfg.ReadyPath, ret.handleReady)
+	if cfg.EnablePProf {
+		pprofutils.RegisterGinRoutes(engine)
+	}
+
+	ret.srv = &http.Server{
+		Addr:         net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Handler:      engine,
+		ReadTimeout:  time.Duration(cfg.ReadTimeout) * time.Second,
+		WriteTimeout: time.Duration(cfg.WriteTimeout) * time.Second,
+		IdleTimeout:  time.Duration(cfg.IdleTimeout) * time.Second,
+	}
+
+	return ret
+}
+
+// AddShutdownHook
+/* @Description: 注册该服务的退出钩子，在http服务停止后按注册顺序执行，Shutdown 及进程退出时都会执行，只执行一次
+ * 进程级别的组件直接使用 dumputils.OnShutdown 指定优先级、超时，如consul注销、mq关闭
+ * @param name string
+ * @param fn func(ctx context.Context) error ctx 为服务退出截止时间
+ */
+func (s *Server) AddShutdownHook(name string, fn func(ctx context.Context) error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.hooks = append(s.hooks, &shutdownHook{name: name, fn: fn})
+}
+
+// AddReadyCheck
+/* @Description: 注册就绪检查，所有检查通过且服务已启动时 /ready 返回200
+ * @param name string
+ * @param fn func() error
+ */
+func (s *Server) AddReadyCheck(name string, fn func() error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.readyChecks = append(s.readyChecks, &readyCheck{name: name, fn: fn})
+}
+
+func (s *Server) SetReady(ready bool) {
+	if ready {
+		atomic.StoreInt32(&s.ready, 1)
+	} else {
+		atomic.StoreInt32(&s.ready, 0)
+	}
+}
+
+func (s *Server) IsReady() bool {
+	return 1 == atomic.LoadInt32(&s.ready)
+}
+
+func (s *Server) Addr() string {
+	return s.srv.Addr
+}
+
+// Start
+/* @Description: 非阻塞启动http服务
+ * @return error 监听失败时返回
+ */
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.srv.Addr)
+	if nil != err {
+		return fmt.Errorf("server listen %s err: %s", s.srv.Addr, err.Error())
+	}
+	s.srv.Addr = ln.Addr().String()
+
+	//http 服务作为 dumputils 的退出钩子，每个 Server 只注册一次
+	s.register.Do(func() {
+		dumputils.OnShutdown("http-server "+s.srv.Addr, dumputils.PriorityServer, s.shutdownTimeout(), s.stop)
+	})
+
+	go func() {
+		if err := s.srv.Serve(ln); nil != err && !errors.Is(err, http.ErrServerClosed) {
+			logutils.Error("server serve fatal", zap.String("addr", s.srv.Addr), zap.Error(err))
+		}
+	}()
+
+	s.SetReady(true)
+	logutils.Info("server started", zap.String("addr", s.srv.Addr))
+	return nil
+}
+
+// Run
+/* @Description: 启动服务并阻塞，收到 SIGINT/SIGTERM 后按优先级执行 dumputils 的所有退出钩子，调用 Shutdown 时只停止该服务并返回
+ * 其他组件通过 dumputils.OnShutdown 注册，如 consul 注销、mq 关闭
+ * @return error
+ */
+func (s *Server) Run() error {
+	if err := s.Start(); nil != err {
+		return err
+	}
+
+	dumputils.ListenSignal()
+	select {
+	case <-dumputils.Done():
+		return dumputils.Wait()
+	case <-s.done:
+		return s.stopErr
+	}
+}
+
+// Shutdown
+/* @Description: 只停止该服务，停止接收新请求，等待处理中的请求完成后按注册顺序执行该服务的退出钩子，只执行一次
+ * 不影响其他组件，进程退出使用 dumputils.Shutdown
+ * @return error
+ */
+func (s *Server) Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout())
+	defer cancel()
+	return s.stop(ctx)
+}
+
+// stop 停止接收新请求并等待处理中的请求完成后执行退出钩子，只执行一次
+func (s *Server) stop(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		defer close(s.done)
+		s.SetReady(false)
+
+		if err := s.srv.Shutdown(ctx); nil != err {
+			logutils.Error("server shutdown fatal", zap.Error(err))
+			s.stopErr = err
+		}
+
+		s.mtx.Lock()
+		hooks := make([]*shutdownHook, len(s.hooks))
+		copy(hooks, s.hooks)
+		s.mtx.Unlock()
+
+		for _, hook := range hooks {
+			start := time.Now()
+			if err := hook.fn(ctx); nil != err {
+				logutils.Error("server shutdown hook fatal", zap.String("hook", hook.name), zap.Error(err))
+				if nil == s.stopErr {
+					s.stopErr = err
+				}
+				continue
+			}
+			logutils.Info("server shutdown hook done", zap.String("hook", hook.name), zap.Duration("elapse", time.Since(start)))
+		}
+	})
+
+	<-s.done
+	return s.stopErr
+}
+
+func (s *Server) shutdownTimeout() time.Duration {
+	if s.cfg.ShutdownTimeout > 0 {
+		return time.Duration(s.cfg.ShutdownTimeout) * time.Second
+	}
+	return DefaultShutdownTimeout
+}
+
+func (s *Server) handleHealth(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (s *Server) handleReady(c *gin.Context) {
+	if !s.IsReady() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready"})
+		return
+	}
+
+	s.mtx.Lock()
+	checks := make([]*readyCheck, len(s.readyChecks))
+	copy(checks, s.readyChecks)
+	s.mtx.Unlock()
+
+	for _, check := range checks {
+		if err := check.fn(); nil != err {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "check": check.name, "err": err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,161 @@
+package serverutils
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: server_test
+ * @Date: 2026-10-19 11:45 下午
+ */
+
+import (
+	"context"
+	"errors"
+	"github.com/0DeOrg/gutils/dumputils"
+	"github.com/0DeOrg/gutils/logutils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func initTestLogger(t *testing.T) {
+	if logutils.IsInit() {
+		return
+	}
+	cfg := logutils.DefaultZapConfig
+	cfg.Directory = t.TempDir()
+	cfg.LinkName = ""
+	cfg.LogInConsole = false
+	logutils.InitLogger(cfg)
+}
+
+func newTestServer(t *testing.T) *Server {
+	initTestLogger(t)
+	s := NewServer(ServerConfig{Host: "127.0.0.1", Mode: gin.TestMode, ShutdownTimeout: 5})
+	return s
+}
+
+func getStatus(t *testing.T, url string) int {
+	rsp, err := http.Get(url)
+	if nil != err {
+		t.Fatal(err)
+	}
+	_ = rsp.Body.Close()
+	return rsp.StatusCode
+}
+
+func Test_ServerHealthReady(t *testing.T) {
+	s := newTestServer(t)
+	var checkErr error
+	s.AddReadyCheck("dep", func() error {
+		return checkErr
+	})
+	if err := s.Start(); nil != err {
+		t.Fatal(err)
+	}
+	defer s.Shutdown()
+
+	base := "http://" + s.Addr()
+	if code := getStatus(t, base+DefaultHealthPath); http.StatusOK != code {
+		t.Fatalf("health expect 200, got %d", code)
+	}
+	if code := getStatus(t, base+DefaultReadyPath); http.StatusOK != code {
+		t.Fatalf("ready expect 200, got %d", code)
+	}
+
+	checkErr = errors.New("dep down")
+	if code := getStatus(t, base+DefaultReadyPath); http.StatusServiceUnavailable != code {
+		t.Fatalf("ready expect 503 on failed check, got %d", code)
+	}
+
+	checkErr = nil
+	s.SetReady(false)
+	if code := getStatus(t, base+DefaultReadyPath); http.StatusServiceUnavailable != code {
+		t.Fatalf("ready expect 503 when not ready, got %d", code)
+	}
+	if code := getStatus(t, base+DefaultHealthPath); http.StatusOK != code {
+		t.Fatalf("health expect 200 when not ready, got %d", code)
+	}
+}
+
+// Test_ServerGracefulShutdown 处理中的请求完成后才执行退出钩子
+func Test_ServerGracefulShutdown(t *testing.T) {
+	s := newTestServer(t)
+	entered := make(chan struct{})
+	handled := make(chan struct{})
+	s.Engine.GET("/slow", func(c *gin.Context) {
+		close(entered)
+		time.Sleep(200 * time.Millisecond)
+		c.String(http.StatusOK, "done")
+		close(handled)
+	})
+
+	var order []string
+	s.AddShutdownHook("first", func(ctx context.Context) error {
+		select {
+		case <-handled:
+			order = append(order, "first")
+		default:
+			order = append(order, "first before drained")
+		}
+		return nil
+	})
+	s.AddShutdownHook("second", func(ctx context.Context) error {
+		order = append(order, "second")
+		return errors.New("second failed")
+	})
+
+	finished := make(chan struct{})
+	code := 0
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- s.Run()
+	}()
+	deadline := time.Now().Add(3 * time.Second)
+	for !s.IsReady() {
+		if time.Now().After(deadline) {
+			t.Fatal("expect server started")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	go func() {
+		defer close(finished)
+		rsp, err := http.Get("http://" + s.Addr() + "/slow")
+		if nil == err {
+			code = rsp.StatusCode
+			_ = rsp.Body.Close()
+		}
+	}()
+	<-entered
+
+	if err := s.Shutdown(); nil == err || "second failed" != err.Error() {
+		t.Fatalf("expect hook err, got %v", err)
+	}
+	if s.IsReady() {
+		t.Fatal("expect not ready after shutdown")
+	}
+	if "first,second" != strings.Join(order, ",") {
+		t.Fatalf("unexpected hook order: %v", order)
+	}
+	//只停止该服务，不触发进程退出
+	select {
+	case err := <-runErr:
+		if nil == err {
+			t.Fatal("expect Run returns shutdown err")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expect Run returns after Shutdown")
+	}
+	if dumputils.ShuttingDown() {
+		t.Fatal("expect process lifecycle untouched")
+	}
+	<-finished
+	if http.StatusOK != code {
+		t.Fatalf("in-flight request expect 200, got %d", code)
+	}
+	if _, err := http.Get("http://" + s.Addr() + DefaultHealthPath); nil == err {
+		t.Fatal("expect server stopped")
+	}
+}
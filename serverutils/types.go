@@ -0,0 +1,42 @@
+package serverutils
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: types
+ * @Date: 2026-10-19 5:40 下午
+ */
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DefaultHealthPath      = "/health"
+	DefaultReadyPath       = "/ready"
+	DefaultShutdownTimeout = 30 * time.Second
+)
+
+type ServerConfig struct {
+	Host            string `mapstructure:"host"              json:"host"              yaml:"host"`
+	Port            int    `mapstructure:"port"              json:"port"              yaml:"port"`
+	Mode            string `mapstructure:"mode"              json:"mode"              yaml:"mode"`             // gin 模式 debug/release/test
+	ReadTimeout     int    `mapstructure:"read-timeout"      json:"read-timeout"      yaml:"read-timeout"`     //单位秒
+	WriteTimeout    int    `mapstructure:"write-timeout"     json:"write-timeout"     yaml:"write-timeout"`    //单位秒
+	IdleTimeout     int    `mapstructure:"idle-timeout"      json:"idle-timeout"      yaml:"idle-timeout"`     //单位秒
+	ShutdownTimeout int    `mapstructure:"shutdown-timeout"  json:"shutdown-timeout"  yaml:"shutdown-timeout"` //优雅退出等待请求处理完的时间，单位秒
+	HealthPath      string `mapstructure:"health-path"       json:"health-path"       yaml:"health-path"`
+	ReadyPath       string `mapstructure:"ready-path"        json:"ready-path"        yaml:"ready-path"`
+	EnablePProf     bool   `mapstructure:"enable-pprof"      json:"enable-pprof"      yaml:"enable-pprof"`
+}
+
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+type readyCheck struct {
+	name string
+	fn   func() error
+}
@@ -0,0 +1,163 @@
+package structutils
+
+/**
+ * @Author: lee
+ * @Description: 有界阻塞队列，Put/Take 支持 context，关闭后 Take 取完剩余元素再返回 ErrQueueClosed
+ * @File: blocking_queue
+ * @Date: 2026-10-19 8:50 下午
+ */
+
+import (
+	"context"
+	"sync"
+)
+
+// BlockingQueue 并发安全的有界 FIFO 队列
+type BlockingQueue[T any] struct {
+	items    []T
+	head     int
+	length   int
+	notEmpty chan struct{} //有元素入队或关闭时 close 并替换
+	notFull  chan struct{} //有元素出队或关闭时 close 并替换
+	closed   bool
+	mtx      sync.Mutex
+}
+
+// NewBlockingQueue
+/* @Description: 创建有界阻塞队列
+ * @param capacity int 容量，<=0 时为1
+ * @return *BlockingQueue[T]
+ */
+func NewBlockingQueue[T any](capacity int) *BlockingQueue[T] {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &BlockingQueue[T]{
+		items:    make([]T, capacity),
+		notEmpty: make(chan struct{}),
+		notFull:  make(chan struct{}),
+	}
+}
+
+// Put
+/* @Description: 入队，队列满时阻塞
+ * @param ctx context.Context
+ * @param value T
+ * @return error ctx 取消时返回 ctx.Err()，关闭时返回 ErrQueueClosed
+ */
+func (q *BlockingQueue[T]) Put(ctx context.Context, value T) error {
+	for {
+		q.mtx.Lock()
+		if q.closed {
+			q.mtx.Unlock()
+			return ErrQueueClosed
+		}
+		if q.length < len(q.items) {
+			q.push(value)
+			q.mtx.Unlock()
+			return nil
+		}
+		notFull := q.notFull
+		q.mtx.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-notFull:
+		}
+	}
+}
+
+// Offer 入队，队列满或已关闭时返回 false，不阻塞
+func (q *BlockingQueue[T]) Offer(value T) bool {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	if q.closed || q.length == len(q.items) {
+		return false
+	}
+	q.push(value)
+	return true
+}
+
+// Take
+/* @Description: 出队，队列为空时阻塞
+ * @param ctx context.Context
+ * @return T
+ * @return error ctx 取消时返回 ctx.Err()，关闭且为空时返回 ErrQueueClosed
+ */
+func (q *BlockingQueue[T]) Take(ctx context.Context) (T, error) {
+	var zero T
+	for {
+		q.mtx.Lock()
+		if q.length > 0 {
+			v := q.pop()
+			q.mtx.Unlock()
+			return v, nil
+		}
+		if q.closed {
+			q.mtx.Unlock()
+			return zero, ErrQueueClosed
+		}
+		notEmpty := q.notEmpty
+		q.mtx.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case <-notEmpty:
+		}
+	}
+}
+
+// Poll 出队，队列为空时返回 false，不阻塞
+func (q *BlockingQueue[T]) Poll() (T, bool) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	if 0 == q.length {
+		var zero T
+		return zero, false
+	}
+	return q.pop(), true
+}
+
+func (q *BlockingQueue[T]) Len() int {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	return q.length
+}
+
+func (q *BlockingQueue[T]) Cap() int {
+	return len(q.items)
+}
+
+// Close 之后 Put 返回 ErrQueueClosed，Take 取完剩余元素后返回 ErrQueueClosed，重复调用无影响
+func (q *BlockingQueue[T]) Close() {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	if q.closed {
+		return
+	}
+	q.closed = true
+	close(q.notEmpty)
+	close(q.notFull)
+}
+
+func (q *BlockingQueue[T]) push(value T) {
+	q.items[(q.head+q.length)%len(q.items)] = value
+	q.length++
+	close(q.notEmpty)
+	q.notEmpty = make(chan struct{})
+}
+
+func (q *BlockingQueue[T]) pop() T {
+	var zero T
+	ret := q.items[q.head]
+	q.items[q.head] = zero
+	q.head = (q.head + 1) % len(q.items)
+	q.length--
+	if !q.closed {
+		close(q.notFull)
+		q.notFull = make(chan struct{})
+	}
+	return ret
+}
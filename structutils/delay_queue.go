@@ -0,0 +1,144 @@
+package structutils
+
+/**
+ * @Author: lee
+ * @Description: 延迟队列，元素到期后才能取出，用于 mq 重新投递、重试退避
+ * @File: delay_queue
+ * @Date: 2026-10-19 8:30 下午
+ */
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type delayItem[T any] struct {
+	value T
+	at    time.Time
+	seq   uint64 //到期时间相同时按插入顺序
+}
+
+// DelayQueue 并发安全的延迟队列
+type DelayQueue[T any] struct {
+	heap   binaryHeap[delayItem[T]]
+	seq    uint64
+	wake   chan struct{} //有新元素或关闭时 close 并替换，唤醒等待的 Take
+	closed bool
+	mtx    sync.Mutex
+}
+
+func NewDelayQueue[T any]() *DelayQueue[T] {
+	return &DelayQueue[T]{
+		heap: binaryHeap[delayItem[T]]{less: func(a, b delayItem[T]) bool {
+			if a.at.Equal(b.at) {
+				return a.seq < b.seq
+			}
+			return a.at.Before(b.at)
+		}},
+		wake: make(chan struct{}),
+	}
+}
+
+// Put
+/* @Description: 插入元素，at 之后才能取出
+ * @param value T
+ * @param at time.Time 到期时间
+ * @return error 已关闭时返回 ErrQueueClosed
+ */
+func (q *DelayQueue[T]) Put(value T, at time.Time) error {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	q.heap.push(delayItem[T]{value: value, at: at, seq: q.seq})
+	q.seq++
+	q.notify()
+	return nil
+}
+
+// PutAfter delay 之后才能取出
+func (q *DelayQueue[T]) PutAfter(value T, delay time.Duration) error {
+	return q.Put(value, time.Now().Add(delay))
+}
+
+// Poll 取出已到期的元素，没有时返回 false，不阻塞
+func (q *DelayQueue[T]) Poll() (T, bool) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	return q.pollAt(time.Now())
+}
+
+// Take
+/* @Description: 阻塞到有元素到期，关闭后立即返回
+ * @param ctx context.Context
+ * @return T
+ * @return error ctx 取消时返回 ctx.Err()，关闭时返回 ErrQueueClosed
+ */
+func (q *DelayQueue[T]) Take(ctx context.Context) (T, error) {
+	var zero T
+	for {
+		q.mtx.Lock()
+		if q.closed {
+			q.mtx.Unlock()
+			return zero, ErrQueueClosed
+		}
+		if v, ok := q.pollAt(time.Now()); ok {
+			q.mtx.Unlock()
+			return v, nil
+		}
+		wake := q.wake
+		var timer *time.Timer
+		var expired <-chan time.Time
+		if len(q.heap.items) > 0 {
+			timer = time.NewTimer(time.Until(q.heap.items[0].at))
+			expired = timer.C
+		}
+		q.mtx.Unlock()
+
+		select {
+		case <-ctx.Done():
+			if nil != timer {
+				timer.Stop()
+			}
+			return zero, ctx.Err()
+		case <-wake:
+		case <-expired:
+		}
+		if nil != timer {
+			timer.Stop()
+		}
+	}
+}
+
+func (q *DelayQueue[T]) Len() int {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	return len(q.heap.items)
+}
+
+// Close 唤醒所有等待的 Take，未到期的元素丢弃
+func (q *DelayQueue[T]) Close() {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	if q.closed {
+		return
+	}
+	q.closed = true
+	q.heap.items = nil
+	q.notify()
+}
+
+func (q *DelayQueue[T]) pollAt(now time.Time) (T, bool) {
+	if 0 == len(q.heap.items) || q.heap.items[0].at.After(now) {
+		var zero T
+		return zero, false
+	}
+	return q.heap.pop().value, true
+}
+
+func (q *DelayQueue[T]) notify() {
+	close(q.wake)
+	q.wake = make(chan struct{})
+}
@@ -0,0 +1,66 @@
+package structutils
+
+/**
+ * @Author: lee
+ * @Description: 滚动统计使用的数值运算，支持 float64 及 decimal.Decimal
+ * @File: numeric
+ * @Date: 2026-10-19 6:10 下午
+ */
+
+import (
+	"github.com/shopspring/decimal"
+	"math"
+)
+
+// Numeric T 的四则运算，decimal 没有运算符，通过该接口统一
+type Numeric[T any] interface {
+	Zero() T
+	Add(a, b T) T
+	Sub(a, b T) T
+	Mul(a, b T) T
+	Div(a, b T) T //b 不为0
+	Cmp(a, b T) int
+	Sqrt(a T) T //a >= 0
+	FromInt(n int) T
+	FromFloat(f float64) T
+}
+
+type Float64Numeric struct{}
+
+var _ Numeric[float64] = Float64Numeric{}
+
+func (Float64Numeric) Zero() float64               { return 0 }
+func (Float64Numeric) Add(a, b float64) float64    { return a + b }
+func (Float64Numeric) Sub(a, b float64) float64    { return a - b }
+func (Float64Numeric) Mul(a, b float64) float64    { return a * b }
+func (Float64Numeric) Div(a, b float64) float64    { return a / b }
+func (Float64Numeric) Sqrt(a float64) float64      { return math.Sqrt(a) }
+func (Float64Numeric) FromInt(n int) float64       { return float64(n) }
+func (Float64Numeric) FromFloat(f float64) float64 { return f }
+
+func (Float64Numeric) Cmp(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// DecimalNumeric 除法精度为 decimal.DivisionPrecision，开方通过 float64 计算
+type DecimalNumeric struct{}
+
+var _ Numeric[decimal.Decimal] = DecimalNumeric{}
+
+func (DecimalNumeric) Zero() decimal.Decimal                    { return decimal.Zero }
+func (DecimalNumeric) Add(a, b decimal.Decimal) decimal.Decimal { return a.Add(b) }
+func (DecimalNumeric) Sub(a, b decimal.Decimal) decimal.Decimal { return a.Sub(b) }
+func (DecimalNumeric) Mul(a, b decimal.Decimal) decimal.Decimal { return a.Mul(b) }
+func (DecimalNumeric) Div(a, b decimal.Decimal) decimal.Decimal { return a.Div(b) }
+func (DecimalNumeric) Cmp(a, b decimal.Decimal) int             { return a.Cmp(b) }
+func (DecimalNumeric) FromInt(n int) decimal.Decimal            { return decimal.NewFromInt(int64(n)) }
+func (DecimalNumeric) FromFloat(f float64) decimal.Decimal      { return decimal.NewFromFloat(f) }
+func (DecimalNumeric) Sqrt(a decimal.Decimal) decimal.Decimal {
+	return decimal.NewFromFloat(math.Sqrt(a.InexactFloat64()))
+}
@@ -0,0 +1,123 @@
+package structutils
+
+/**
+ * @Author: lee
+ * @Description: 基于堆的泛型优先队列
+ * @File: priority_queue
+ * @Date: 2026-10-19 8:10 下午
+ */
+
+import (
+	"errors"
+	"sync"
+)
+
+var ErrQueueClosed = errors.New("queue is closed")
+
+// binaryHeap less 为 true 的元素在堆顶
+type binaryHeap[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+func (h *binaryHeap[T]) push(v T) {
+	h.items = append(h.items, v)
+	h.up(len(h.items) - 1)
+}
+
+func (h *binaryHeap[T]) pop() T {
+	n := len(h.items) - 1
+	ret := h.items[0]
+	h.items[0] = h.items[n]
+	var zero T
+	h.items[n] = zero
+	h.items = h.items[:n]
+	if n > 0 {
+		h.down(0)
+	}
+	return ret
+}
+
+func (h *binaryHeap[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(h.items[i], h.items[parent]) {
+			break
+		}
+		h.items[i], h.items[parent] = h.items[parent], h.items[i]
+		i = parent
+	}
+}
+
+func (h *binaryHeap[T]) down(i int) {
+	n := len(h.items)
+	for {
+		smallest := i
+		if l := 2*i + 1; l < n && h.less(h.items[l], h.items[smallest]) {
+			smallest = l
+		}
+		if r := 2*i + 2; r < n && h.less(h.items[r], h.items[smallest]) {
+			smallest = r
+		}
+		if smallest == i {
+			return
+		}
+		h.items[i], h.items[smallest] = h.items[smallest], h.items[i]
+		i = smallest
+	}
+}
+
+// PriorityQueue 并发安全的优先队列
+type PriorityQueue[T any] struct {
+	heap binaryHeap[T]
+	mtx  sync.Mutex
+}
+
+// NewPriorityQueue
+/* @Description: 创建优先队列，如按重试时间、任务权重排序
+ * @param less func(a, b T) bool a 是否先于 b 出队，如 func(a, b int) bool { return a < b } 为小顶堆
+ * @return *PriorityQueue[T]
+ */
+func NewPriorityQueue[T any](less func(a, b T) bool) *PriorityQueue[T] {
+	return &PriorityQueue[T]{heap: binaryHeap[T]{less: less}}
+}
+
+func (q *PriorityQueue[T]) Push(value T) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	q.heap.push(value)
+}
+
+// Pop 取出优先级最高的，队列为空时返回 false
+func (q *PriorityQueue[T]) Pop() (T, bool) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	if 0 == len(q.heap.items) {
+		var zero T
+		return zero, false
+	}
+	return q.heap.pop(), true
+}
+
+// Peek 优先级最高的，不出队
+func (q *PriorityQueue[T]) Peek() (T, bool) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	if 0 == len(q.heap.items) {
+		var zero T
+		return zero, false
+	}
+	return q.heap.items[0], true
+}
+
+func (q *PriorityQueue[T]) Len() int {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	return len(q.heap.items)
+}
+
+func (q *PriorityQueue[T]) Clear() {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	q.heap.items = nil
+}
@@ -0,0 +1,231 @@
+package structutils
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: priority_queue_test
+ * @Date: 2026-10-19 9:10 下午
+ */
+
+func intLess(a, b int) bool {
+	return a < b
+}
+
+func Test_PriorityQueue(t *testing.T) {
+	q := NewPriorityQueue[int](intLess)
+	for _, v := range []int{5, 1, 4, 1, 3} {
+		q.Push(v)
+	}
+	if v, ok := q.Peek(); !ok || 1 != v || 5 != q.Len() {
+		t.Fatalf("unexpected peek %d len %d", v, q.Len())
+	}
+	var got []int
+	for v, ok := q.Pop(); ok; v, ok = q.Pop() {
+		got = append(got, v)
+	}
+	if !sort.IntsAreSorted(got) || 5 != len(got) {
+		t.Fatalf("unexpected order: %v", got)
+	}
+}
+
+func Test_DelayQueue(t *testing.T) {
+	q := NewDelayQueue[string]()
+	_ = q.PutAfter("late", 80*time.Millisecond)
+	_ = q.PutAfter("early", 20*time.Millisecond)
+	if _, ok := q.Poll(); ok {
+		t.Fatal("expect nothing expired")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	for _, expect := range []string{"early", "late"} {
+		v, err := q.Take(ctx)
+		if nil != err || expect != v {
+			t.Fatalf("expect %s, got %s err %v", expect, v, err)
+		}
+	}
+	if time.Since(start) < 80*time.Millisecond {
+		t.Fatal("taken before deadline")
+	}
+
+	//关闭唤醒等待的 Take
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		q.Close()
+	}()
+	if _, err := q.Take(ctx); !errors.Is(err, ErrQueueClosed) {
+		t.Fatalf("expect closed, got %v", err)
+	}
+	if err := q.PutAfter("x", 0); !errors.Is(err, ErrQueueClosed) {
+		t.Fatalf("expect closed, got %v", err)
+	}
+}
+
+func Test_BlockingQueue(t *testing.T) {
+	q := NewBlockingQueue[int](2)
+	ctx := context.Background()
+	_ = q.Put(ctx, 1)
+	_ = q.Put(ctx, 2)
+	if q.Offer(3) {
+		t.Fatal("expect full")
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := q.Put(timeout, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect deadline, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var sum int
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			v, err := q.Take(ctx)
+			if nil != err {
+				return
+			}
+			sum += v
+		}
+	}()
+	for i := 3; i <= 100; i++ {
+		if err := q.Put(ctx, i); nil != err {
+			t.Fatal(err)
+		}
+	}
+	q.Close()
+	wg.Wait()
+	if 5050 != sum {
+		t.Fatalf("expect all taken after close, sum %d", sum)
+	}
+	if err := q.Put(ctx, 1); !errors.Is(err, ErrQueueClosed) {
+		t.Fatalf("expect closed, got %v", err)
+	}
+}
+
+func Benchmark_PriorityQueue(b *testing.B) {
+	q := NewPriorityQueue[int](intLess)
+	for i := 0; i < b.N; i++ {
+		q.Push((i * 7919) % 1000)
+		if q.Len() > 1000 {
+			q.Pop()
+		}
+	}
+}
+
+func Benchmark_DelayQueue(b *testing.B) {
+	q := NewDelayQueue[int]()
+	now := time.Now()
+	for i := 0; i < b.N; i++ {
+		_ = q.Put(i, now.Add(-time.Duration(i%1000)))
+		if q.Len() > 1000 {
+			q.Poll()
+		}
+	}
+}
+
+func Benchmark_BlockingQueue(b *testing.B) {
+	q := NewBlockingQueue[int](1024)
+	ctx := context.Background()
+	go func() {
+		for {
+			if _, err := q.Take(ctx); nil != err {
+				return
+			}
+		}
+	}()
+	for i := 0; i < b.N; i++ {
+		_ = q.Put(ctx, i)
+	}
+	q.Close()
+}
+
+func Fuzz_PriorityQueue(f *testing.F) {
+	f.Add([]byte{5, 1, 4, 1, 3})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		q := NewPriorityQueue[int](intLess)
+		for _, b := range data {
+			q.Push(int(b))
+		}
+		last := -1
+		for v, ok := q.Pop(); ok; v, ok = q.Pop() {
+			if v < last {
+				t.Fatalf("unordered pop %d after %d", v, last)
+			}
+			last = v
+		}
+	})
+}
+
+func Fuzz_DelayQueue(f *testing.F) {
+	f.Add([]byte{3, 0, 3, 1})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		q := NewDelayQueue[int]()
+		base := time.Now()
+		for i, b := range data {
+			_ = q.Put(i, base.Add(time.Duration(b)*time.Second))
+		}
+		//到期时间相同时按插入顺序
+		var lastAt byte
+		lastIdx := -1
+		for sec := 0; sec < 256; sec++ {
+			now := base.Add(time.Duration(sec) * time.Second)
+			q.mtx.Lock()
+			for v, ok := q.pollAt(now); ok; v, ok = q.pollAt(now) {
+				if data[v] > byte(sec) || data[v] < lastAt || (data[v] == lastAt && v < lastIdx) {
+					t.Fatalf("unexpected poll %d at %ds", v, sec)
+				}
+				lastAt, lastIdx = data[v], v
+			}
+			q.mtx.Unlock()
+		}
+		if 0 != q.Len() {
+			t.Fatalf("expect empty, len %d", q.Len())
+		}
+	})
+}
+
+func Fuzz_BlockingQueue(f *testing.F) {
+	f.Add([]byte{1, 2, 0, 3, 0, 0}, 2)
+	f.Fuzz(func(t *testing.T, ops []byte, capacity int) {
+		capacity = capacity%16 + 1
+		if capacity <= 0 {
+			capacity += 16
+		}
+		q := NewBlockingQueue[byte](capacity)
+		var model []byte
+		for _, op := range ops {
+			if 0 == op%2 {
+				v, ok := q.Poll()
+				if ok != (len(model) > 0) || (ok && v != model[0]) {
+					t.Fatalf("unexpected poll %d %v, model %v", v, ok, model)
+				}
+				if ok {
+					model = model[1:]
+				}
+				continue
+			}
+			ok := q.Offer(op)
+			if ok != (len(model) < capacity) {
+				t.Fatalf("unexpected offer %v, model %v", ok, model)
+			}
+			if ok {
+				model = append(model, op)
+			}
+		}
+		if len(model) != q.Len() {
+			t.Fatalf("expect len %d, got %d", len(model), q.Len())
+		}
+	})
+}
@@ -15,6 +15,8 @@ import (
 /* @Description: 固定长度的队列，如果队列满了则将z
  */
 
+// QueueFIFO
+// Deprecated: 使用类型安全的 Ring[T]
 type QueueFIFO struct {
 	Capacity int           `json:"c"`
 	H        int           `json:"h"`
@@ -56,7 +58,9 @@ func (q *QueueFIFO) Push(ele interface{}) {
 }
 
 func (q *QueueFIFO) UpdateFromTail(idx int, value interface{}) bool {
-	if idx >= q.Length {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	if idx < 0 || idx >= q.Length {
 		return false
 	}
 
@@ -67,7 +71,9 @@ func (q *QueueFIFO) UpdateFromTail(idx int, value interface{}) bool {
 }
 
 func (q *QueueFIFO) UpdateFromHead(idx int, value interface{}) bool {
-	if idx >= q.Length {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	if idx < 0 || idx >= q.Length {
 		return false
 	}
 
@@ -0,0 +1,285 @@
+package structutils
+
+/**
+ * @Author: lee
+ * @Description: 固定长度的泛型环形队列，满了覆盖最旧的数据
+ * @File: ring
+ * @Date: 2026-10-19 4:10 下午
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Ring 并发安全的环形队列，head 为最旧的数据，tail 为最新的数据
+// 零值可以直接使用，第一次 Push 时容量为1，需要更大容量时使用 NewRing 或 Resize
+type Ring[T any] struct {
+	buf    []T
+	head   int
+	length int
+	mtx    sync.RWMutex
+}
+
+// ringJSON 持久化格式，D 从旧到新
+type ringJSON[T any] struct {
+	Capacity int `json:"c"`
+	Data     []T `json:"d"`
+}
+
+// NewRing
+/* @Description: 创建环形队列
+ * @param capacity int 容量，<=0 时为1
+ * @return *Ring[T]
+ */
+func NewRing[T any](capacity int) *Ring[T] {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &Ring[T]{buf: make([]T, capacity)}
+}
+
+// Push
+/* @Description: 队列尾插入数据，满了时覆盖队列头
+ * @param value T
+ * @return T 被覆盖的数据
+ * @return bool 是否覆盖
+ */
+func (r *Ring[T]) Push(value T) (T, bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if 0 == len(r.buf) {
+		r.buf = make([]T, 1)
+	}
+	var evicted T
+	if r.length == len(r.buf) {
+		evicted = r.buf[r.head]
+		r.buf[r.head] = value
+		r.head = (r.head + 1) % len(r.buf)
+		return evicted, true
+	}
+	r.buf[(r.head+r.length)%len(r.buf)] = value
+	r.length++
+	return evicted, false
+}
+
+// PopHead
+/* @Description: 删除并返回最旧的数据
+ * @return T
+ * @return bool 队列为空时为 false
+ */
+func (r *Ring[T]) PopHead() (T, bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	var zero T
+	if 0 == r.length {
+		return zero, false
+	}
+	ret := r.buf[r.head]
+	r.buf[r.head] = zero
+	r.head = (r.head + 1) % len(r.buf)
+	r.length--
+	return ret, true
+}
+
+// Head 最旧的数据
+func (r *Ring[T]) Head() (T, bool) {
+	return r.FromHead(0)
+}
+
+// Tail 最新的数据
+func (r *Ring[T]) Tail() (T, bool) {
+	return r.FromTail(0)
+}
+
+// FromHead
+/* @Description: 从队列头开始的第 idx 个，0 为最旧的数据
+ * @param idx int
+ * @return T
+ * @return bool 越界时为 false
+ */
+func (r *Ring[T]) FromHead(idx int) (T, bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if idx < 0 || idx >= r.length {
+		var zero T
+		return zero, false
+	}
+	return r.buf[r.fromHead(idx)], true
+}
+
+// FromTail
+/* @Description: 从队列尾开始的第 idx 个，0 为最新的数据
+ * @param idx int
+ * @return T
+ * @return bool 越界时为 false
+ */
+func (r *Ring[T]) FromTail(idx int) (T, bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if idx < 0 || idx >= r.length {
+		var zero T
+		return zero, false
+	}
+	return r.buf[r.fromHead(r.length-1-idx)], true
+}
+
+// UpdateFromHead 修改从队列头开始的第 idx 个，越界时返回 false
+func (r *Ring[T]) UpdateFromHead(idx int, value T) bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if idx < 0 || idx >= r.length {
+		return false
+	}
+	r.buf[r.fromHead(idx)] = value
+	return true
+}
+
+// UpdateFromTail 修改从队列尾开始的第 idx 个，越界时返回 false
+func (r *Ring[T]) UpdateFromTail(idx int, value T) bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if idx < 0 || idx >= r.length {
+		return false
+	}
+	r.buf[r.fromHead(r.length-1-idx)] = value
+	return true
+}
+
+// Slice
+/* @Description: 从旧到新的快照，修改返回值不影响队列
+ * @return []T
+ */
+func (r *Ring[T]) Slice() []T {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.slice()
+}
+
+// Range
+/* @Description: 从队列尾开始遍历，遍历时持有读锁，fn 中不能修改队列
+ * @param fn func(int, T) bool 返回 false 时停止
+ */
+func (r *Ring[T]) Range(fn func(int, T) bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	for i := 0; i < r.length; i++ {
+		if !fn(i, r.buf[r.fromHead(r.length-1-i)]) {
+			break
+		}
+	}
+}
+
+// ReverseRange
+/* @Description: 从队列头开始遍历，遍历时持有读锁，fn 中不能修改队列
+ * @param fn func(int, T) bool 返回 false 时停止
+ */
+func (r *Ring[T]) ReverseRange(fn func(int, T) bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	for i := 0; i < r.length; i++ {
+		if !fn(i, r.buf[r.fromHead(i)]) {
+			break
+		}
+	}
+}
+
+// Resize
+/* @Description: 修改容量，变小时保留最新的数据
+ * @param capacity int <=0 时为1
+ */
+func (r *Ring[T]) Resize(capacity int) {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if capacity == len(r.buf) {
+		return
+	}
+	r.reset(capacity, r.slice())
+}
+
+func (r *Ring[T]) Clear() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	var zero T
+	for i := range r.buf {
+		r.buf[i] = zero
+	}
+	r.head = 0
+	r.length = 0
+}
+
+func (r *Ring[T]) Len() int {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.length
+}
+
+func (r *Ring[T]) Cap() int {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return len(r.buf)
+}
+
+func (r *Ring[T]) IsEmpty() bool {
+	return 0 == r.Len()
+}
+
+func (r *Ring[T]) IsFull() bool {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.length == len(r.buf)
+}
+
+// MarshalJSON 保存容量及从旧到新的数据
+func (r *Ring[T]) MarshalJSON() ([]byte, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return json.Marshal(ringJSON[T]{Capacity: len(r.buf), Data: r.slice()})
+}
+
+// UnmarshalJSON 数据多于容量时保留最新的
+func (r *Ring[T]) UnmarshalJSON(data []byte) error {
+	var v ringJSON[T]
+	if err := json.Unmarshal(data, &v); nil != err {
+		return fmt.Errorf("Ring|UnmarshalJSON err: %s", err.Error())
+	}
+	if v.Capacity <= 0 {
+		v.Capacity = len(v.Data)
+	}
+	if v.Capacity <= 0 {
+		v.Capacity = 1
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.reset(v.Capacity, v.Data)
+	return nil
+}
+
+func (r *Ring[T]) fromHead(idx int) int {
+	return (r.head + idx) % len(r.buf)
+}
+
+func (r *Ring[T]) slice() []T {
+	ret := make([]T, r.length)
+	for i := 0; i < r.length; i++ {
+		ret[i] = r.buf[r.fromHead(i)]
+	}
+	return ret
+}
+
+// reset 以 values 从旧到新重建，超过容量时保留最新的
+func (r *Ring[T]) reset(capacity int, values []T) {
+	if len(values) > capacity {
+		values = values[len(values)-capacity:]
+	}
+	r.buf = make([]T, capacity)
+	copy(r.buf, values)
+	r.head = 0
+	r.length = len(values)
+}
@@ -0,0 +1,140 @@
+package structutils
+
+/**
+ * @Author: lee
+ * @Description: 单生产者的无锁环形队列，只有一个协程写入，多个协程可同时读取
+ * @File: ring_sp
+ * @Date: 2026-10-19 4:40 下午
+ */
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"unsafe"
+)
+
+type spNode[T any] struct {
+	seq   uint64 //写入序号，读取时校验是否已被覆盖
+	value T
+}
+
+// SPRing 单生产者无锁环形队列，满了覆盖最旧的数据，如行情推送协程写入最近的 k 线，多个协程读取
+// 多个协程同时 Push 时数据不安全，需要使用 Ring，零值不可用，需要使用 NewSPRing 创建
+type SPRing[T any] struct {
+	slots   []unsafe.Pointer //*spNode[T]
+	written uint64           //已写入的总数
+}
+
+// NewSPRing
+/* @Description: 创建单生产者无锁环形队列
+ * @param capacity int 容量，<=0 时为1
+ * @return *SPRing[T]
+ */
+func NewSPRing[T any](capacity int) *SPRing[T] {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &SPRing[T]{slots: make([]unsafe.Pointer, capacity)}
+}
+
+// Push 队列尾插入数据，满了时覆盖队列头，只能在一个协程中调用
+func (r *SPRing[T]) Push(value T) {
+	seq := atomic.LoadUint64(&r.written)
+	node := &spNode[T]{seq: seq, value: value}
+	atomic.StorePointer(&r.slots[seq%uint64(len(r.slots))], unsafe.Pointer(node))
+	atomic.StoreUint64(&r.written, seq+1)
+}
+
+func (r *SPRing[T]) Len() int {
+	written := atomic.LoadUint64(&r.written)
+	if written > uint64(len(r.slots)) {
+		return len(r.slots)
+	}
+	return int(written)
+}
+
+func (r *SPRing[T]) Cap() int {
+	return len(r.slots)
+}
+
+// FromHead
+/* @Description: 从队列头开始的第 idx 个，0 为最旧的数据
+ * @param idx int
+ * @return T
+ * @return bool 越界或读取时已被覆盖为 false
+ */
+func (r *SPRing[T]) FromHead(idx int) (T, bool) {
+	written, length := r.bounds()
+	if idx < 0 || idx >= length {
+		var zero T
+		return zero, false
+	}
+	return r.load(written - uint64(length) + uint64(idx))
+}
+
+// FromTail
+/* @Description: 从队列尾开始的第 idx 个，0 为最新的数据
+ * @param idx int
+ * @return T
+ * @return bool 越界或读取时已被覆盖为 false
+ */
+func (r *SPRing[T]) FromTail(idx int) (T, bool) {
+	written, length := r.bounds()
+	if idx < 0 || idx >= length {
+		var zero T
+		return zero, false
+	}
+	return r.load(written - 1 - uint64(idx))
+}
+
+// Slice
+/* @Description: 从旧到新的快照，读取时被覆盖的旧数据不包含在内
+ * @return []T
+ */
+func (r *SPRing[T]) Slice() []T {
+	written, length := r.bounds()
+	ret := make([]T, 0, length)
+	for seq := written - uint64(length); seq < written; seq++ {
+		if v, ok := r.load(seq); ok {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+// Range
+/* @Description: 从队列尾开始遍历，遇到已被覆盖的数据时停止
+ * @param fn func(int, T) bool 返回 false 时停止
+ */
+func (r *SPRing[T]) Range(fn func(int, T) bool) {
+	written, length := r.bounds()
+	for i := 0; i < length; i++ {
+		v, ok := r.load(written - 1 - uint64(i))
+		if !ok || !fn(i, v) {
+			break
+		}
+	}
+}
+
+// MarshalJSON 格式同 Ring，可以反序列化为 Ring
+func (r *SPRing[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ringJSON[T]{Capacity: len(r.slots), Data: r.Slice()})
+}
+
+func (r *SPRing[T]) bounds() (uint64, int) {
+	written := atomic.LoadUint64(&r.written)
+	length := uint64(len(r.slots))
+	if written < length {
+		length = written
+	}
+	return written, int(length)
+}
+
+func (r *SPRing[T]) load(seq uint64) (T, bool) {
+	node := (*spNode[T])(atomic.LoadPointer(&r.slots[seq%uint64(len(r.slots))]))
+	if nil == node || seq != node.seq {
+		var zero T
+		return zero, false
+	}
+	return node.value, true
+}
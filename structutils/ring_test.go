@@ -0,0 +1,123 @@
+package structutils
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: ring_test
+ * @Date: 2026-10-19 5:00 下午
+ */
+
+func Test_Ring(t *testing.T) {
+	r := NewRing[int](3)
+	for i := 0; i < 5; i++ {
+		r.Push(i)
+	}
+	if !reflect.DeepEqual([]int{2, 3, 4}, r.Slice()) {
+		t.Fatalf("unexpected slice: %v", r.Slice())
+	}
+	if v, ok := r.FromTail(0); !ok || 4 != v {
+		t.Fatalf("unexpected tail: %d", v)
+	}
+	if v, ok := r.FromHead(0); !ok || 2 != v {
+		t.Fatalf("unexpected head: %d", v)
+	}
+	if _, ok := r.FromHead(3); ok {
+		t.Fatal("expect out of range")
+	}
+	if !r.UpdateFromTail(1, 30) || r.UpdateFromHead(-1, 0) {
+		t.Fatal("unexpected update result")
+	}
+
+	var ranged []int
+	r.Range(func(idx int, v int) bool {
+		ranged = append(ranged, v)
+		return true
+	})
+	if !reflect.DeepEqual([]int{4, 30, 2}, ranged) {
+		t.Fatalf("unexpected range: %v", ranged)
+	}
+
+	r.Resize(2)
+	if !reflect.DeepEqual([]int{30, 4}, r.Slice()) {
+		t.Fatalf("unexpected resize: %v", r.Slice())
+	}
+
+	data, err := json.Marshal(r)
+	if nil != err {
+		t.Fatal(err)
+	}
+	restored := NewRing[int](1)
+	if err = json.Unmarshal(data, restored); nil != err {
+		t.Fatal(err)
+	}
+	if 2 != restored.Cap() || !reflect.DeepEqual(r.Slice(), restored.Slice()) {
+		t.Fatalf("unexpected restored %s: %v", data, restored.Slice())
+	}
+}
+
+// Test_RingZero 零值 Ring 不 panic
+func Test_RingZero(t *testing.T) {
+	var r Ring[int]
+	if _, ok := r.PopHead(); ok {
+		t.Fatal("expect empty")
+	}
+	if _, evicted := r.Push(1); evicted || 1 != r.Cap() {
+		t.Fatalf("unexpected zero ring cap %d", r.Cap())
+	}
+	if v, evicted := r.Push(2); !evicted || 1 != v {
+		t.Fatalf("expect 1 evicted, got %d", v)
+	}
+	r.Resize(3)
+	r.Push(3)
+	if !reflect.DeepEqual([]int{2, 3}, r.Slice()) {
+		t.Fatalf("unexpected slice: %v", r.Slice())
+	}
+}
+
+func Test_SPRing(t *testing.T) {
+	r := NewSPRing[int](8)
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				s := r.Slice()
+				for j := 1; j < len(s); j++ {
+					if s[j] <= s[j-1] {
+						t.Errorf("unordered snapshot: %v", s)
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 10000; i++ {
+		r.Push(i)
+	}
+	close(stop)
+	wg.Wait()
+
+	if v, ok := r.FromTail(0); !ok || 9999 != v {
+		t.Fatalf("unexpected tail: %d", v)
+	}
+	data, _ := json.Marshal(r)
+	restored := NewRing[int](1)
+	if err := json.Unmarshal(data, restored); nil != err || 8 != restored.Len() {
+		t.Fatalf("unexpected restored %s, err: %v", data, err)
+	}
+}
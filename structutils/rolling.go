@@ -0,0 +1,334 @@
+package structutils
+
+/**
+ * @Author: lee
+ * @Description: 滚动窗口统计，插入及淘汰时增量更新和、均值、方差、最大最小值、EMA 及分位数，窗口可按数量或时间
+ * @File: rolling
+ * @Date: 2026-10-19 6:30 下午
+ */
+
+import (
+	"github.com/shopspring/decimal"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultEMAPeriod       = 20
+	defaultRollingCapacity = 64
+	defaultRollingMaxSize  = 1 << 16
+)
+
+type RollingConfig struct {
+	Size        int           `json:"size"     yaml:"size"                 mapstructure:"size"`        //按数量的窗口，<=0 时按 Window，数量最多为 MaxSize
+	MaxSize     int           `json:"max-size"     yaml:"max-size"         mapstructure:"max-size"`    //Size<=0 时缓冲的上限，超过时淘汰最旧的，默认65536
+	Window      time.Duration `json:"window"     yaml:"window"             mapstructure:"window"`      //按时间的窗口，相对最新数据的时间，<=0 不按时间
+	EMAAlpha    float64       `json:"ema-alpha"     yaml:"ema-alpha"       mapstructure:"ema-alpha"`   //EMA 平滑系数 (0,1]，默认 2/(Size+1)，未设置 Size 时 2/21
+	Percentiles bool          `json:"percentiles"     yaml:"percentiles"   mapstructure:"percentiles"` //维护有序数据以计算分位数，插入淘汰为 O(n)
+}
+
+type sample[T any] struct {
+	value T
+	at    time.Time
+	seq   uint64
+}
+
+// Rolling 并发安全，EMA 为所有数据的指数平均，不受窗口淘汰影响
+type Rolling[T any] struct {
+	num    Numeric[T]
+	cfg    RollingConfig
+	ring   *Ring[sample[T]]
+	seq    uint64
+	count  int
+	sum    T
+	mean   T //Welford 的均值，用于计算方差
+	m2     T
+	ema    T
+	emaSet bool
+	alpha  T
+	minQ   []sample[T] //单调递增，队头为最小值
+	maxQ   []sample[T] //单调递减，队头为最大值
+	sorted []T
+	mtx    sync.RWMutex
+}
+
+// NewRolling
+/* @Description: 创建滚动统计，如 NewRolling[decimal.Decimal](DecimalNumeric{}, RollingConfig{Size: 100})
+ * @param num Numeric[T] 数值运算
+ * @param cfg RollingConfig
+ * @return *Rolling[T]
+ */
+func NewRolling[T any](num Numeric[T], cfg RollingConfig) *Rolling[T] {
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = defaultRollingMaxSize
+	}
+	capacity := cfg.Size
+	if capacity <= 0 {
+		capacity = defaultRollingCapacity
+		if capacity > cfg.MaxSize {
+			capacity = cfg.MaxSize
+		}
+	}
+	if cfg.EMAAlpha <= 0 || cfg.EMAAlpha > 1 {
+		period := cfg.Size
+		if period <= 0 {
+			period = defaultEMAPeriod
+		}
+		cfg.EMAAlpha = 2 / float64(period+1)
+	}
+
+	return &Rolling[T]{
+		num:   num,
+		cfg:   cfg,
+		ring:  NewRing[sample[T]](capacity),
+		sum:   num.Zero(),
+		mean:  num.Zero(),
+		m2:    num.Zero(),
+		ema:   num.Zero(),
+		alpha: num.FromFloat(cfg.EMAAlpha),
+	}
+}
+
+// NewFloatRolling float64 的滚动统计
+func NewFloatRolling(cfg RollingConfig) *Rolling[float64] {
+	return NewRolling[float64](Float64Numeric{}, cfg)
+}
+
+// NewDecimalRolling decimal.Decimal 的滚动统计，和为精确值
+func NewDecimalRolling(cfg RollingConfig) *Rolling[decimal.Decimal] {
+	return NewRolling[decimal.Decimal](DecimalNumeric{}, cfg)
+}
+
+// Push 以当前时间插入
+func (r *Rolling[T]) Push(value T) {
+	r.PushAt(value, time.Now())
+}
+
+// PushAt
+/* @Description: 插入数据，淘汰超出数量及时间窗口的旧数据，时间窗口以 at 为准
+ * @param value T
+ * @param at time.Time 数据时间，如行情的成交时间
+ */
+func (r *Rolling[T]) PushAt(value T, at time.Time) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.cfg.Size <= 0 && r.ring.IsFull() && r.ring.Cap() < r.cfg.MaxSize {
+		capacity := r.ring.Cap() * 2
+		if capacity > r.cfg.MaxSize {
+			capacity = r.cfg.MaxSize
+		}
+		r.ring.Resize(capacity)
+	}
+	s := sample[T]{value: value, at: at, seq: r.seq}
+	r.seq++
+	if evicted, ok := r.ring.Push(s); ok {
+		r.remove(evicted)
+	}
+	r.add(s)
+	r.expire(at)
+}
+
+// Expire
+/* @Description: 淘汰时间窗口外的数据，长时间没有新数据时调用
+ * @param now time.Time
+ */
+func (r *Rolling[T]) Expire(now time.Time) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.expire(now)
+}
+
+func (r *Rolling[T]) Len() int {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.count
+}
+
+func (r *Rolling[T]) Sum() T {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.sum
+}
+
+// Mean 窗口为空时为0
+func (r *Rolling[T]) Mean() T {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if 0 == r.count {
+		return r.num.Zero()
+	}
+	return r.num.Div(r.sum, r.num.FromInt(r.count))
+}
+
+// Variance 总体方差，窗口为空时为0
+func (r *Rolling[T]) Variance() T {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.variance()
+}
+
+// StdDev 总体标准差
+func (r *Rolling[T]) StdDev() T {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.num.Sqrt(r.variance())
+}
+
+// Min 窗口为空时返回 false
+func (r *Rolling[T]) Min() (T, bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if 0 == len(r.minQ) {
+		return r.num.Zero(), false
+	}
+	return r.minQ[0].value, true
+}
+
+// Max 窗口为空时返回 false
+func (r *Rolling[T]) Max() (T, bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if 0 == len(r.maxQ) {
+		return r.num.Zero(), false
+	}
+	return r.maxQ[0].value, true
+}
+
+// EMA 没有数据时返回 false
+func (r *Rolling[T]) EMA() (T, bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.ema, r.emaSet
+}
+
+// Percentile
+/* @Description: 分位数，相邻两个数据线性插值
+ * @param p float64 [0, 100]，如 50 为中位数
+ * @return T
+ * @return bool 窗口为空或未开启 Percentiles 时为 false
+ */
+func (r *Rolling[T]) Percentile(p float64) (T, bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if !r.cfg.Percentiles || 0 == len(r.sorted) {
+		return r.num.Zero(), false
+	}
+	p = math.Max(0, math.Min(100, p))
+	rank := p / 100 * float64(len(r.sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	if lo == hi {
+		return r.sorted[lo], true
+	}
+	diff := r.num.Sub(r.sorted[hi], r.sorted[lo])
+	return r.num.Add(r.sorted[lo], r.num.Mul(diff, r.num.FromFloat(rank-float64(lo)))), true
+}
+
+// Values 窗口内从旧到新的数据
+func (r *Rolling[T]) Values() []T {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	samples := r.ring.Slice()
+	ret := make([]T, len(samples))
+	for i, s := range samples {
+		ret[i] = s.value
+	}
+	return ret
+}
+
+func (r *Rolling[T]) add(s sample[T]) {
+	x := s.value
+	r.count++
+	r.sum = r.num.Add(r.sum, x)
+	d := r.num.Sub(x, r.mean)
+	r.mean = r.num.Add(r.mean, r.num.Div(d, r.num.FromInt(r.count)))
+	r.m2 = r.num.Add(r.m2, r.num.Mul(d, r.num.Sub(x, r.mean)))
+
+	if r.emaSet {
+		r.ema = r.num.Add(r.ema, r.num.Mul(r.alpha, r.num.Sub(x, r.ema)))
+	} else {
+		r.ema = x
+		r.emaSet = true
+	}
+
+	for len(r.minQ) > 0 && r.num.Cmp(r.minQ[len(r.minQ)-1].value, x) >= 0 {
+		r.minQ = r.minQ[:len(r.minQ)-1]
+	}
+	r.minQ = append(r.minQ, s)
+	for len(r.maxQ) > 0 && r.num.Cmp(r.maxQ[len(r.maxQ)-1].value, x) <= 0 {
+		r.maxQ = r.maxQ[:len(r.maxQ)-1]
+	}
+	r.maxQ = append(r.maxQ, s)
+
+	if r.cfg.Percentiles {
+		idx := r.search(x)
+		var zero T
+		r.sorted = append(r.sorted, zero)
+		copy(r.sorted[idx+1:], r.sorted[idx:])
+		r.sorted[idx] = x
+	}
+}
+
+func (r *Rolling[T]) remove(s sample[T]) {
+	x := s.value
+	r.count--
+	r.sum = r.num.Sub(r.sum, x)
+	if 0 == r.count {
+		r.sum = r.num.Zero()
+		r.mean = r.num.Zero()
+		r.m2 = r.num.Zero()
+	} else {
+		d := r.num.Sub(x, r.mean)
+		r.mean = r.num.Sub(r.mean, r.num.Div(d, r.num.FromInt(r.count)))
+		r.m2 = r.num.Sub(r.m2, r.num.Mul(d, r.num.Sub(x, r.mean)))
+		//浮点误差
+		if r.num.Cmp(r.m2, r.num.Zero()) < 0 {
+			r.m2 = r.num.Zero()
+		}
+	}
+
+	if len(r.minQ) > 0 && r.minQ[0].seq == s.seq {
+		r.minQ = r.minQ[1:]
+	}
+	if len(r.maxQ) > 0 && r.maxQ[0].seq == s.seq {
+		r.maxQ = r.maxQ[1:]
+	}
+
+	if r.cfg.Percentiles {
+		idx := r.search(x)
+		if idx < len(r.sorted) {
+			r.sorted = append(r.sorted[:idx], r.sorted[idx+1:]...)
+		}
+	}
+}
+
+func (r *Rolling[T]) expire(now time.Time) {
+	if r.cfg.Window <= 0 {
+		return
+	}
+	for {
+		head, ok := r.ring.Head()
+		if !ok || now.Sub(head.at) < r.cfg.Window {
+			return
+		}
+		r.ring.PopHead()
+		r.remove(head)
+	}
+}
+
+func (r *Rolling[T]) variance() T {
+	if 0 == r.count {
+		return r.num.Zero()
+	}
+	return r.num.Div(r.m2, r.num.FromInt(r.count))
+}
+
+// search 有序数据中第一个 >= x 的位置
+func (r *Rolling[T]) search(x T) int {
+	return sort.Search(len(r.sorted), func(i int) bool {
+		return r.num.Cmp(r.sorted[i], x) >= 0
+	})
+}
@@ -0,0 +1,98 @@
+package structutils
+
+import (
+	"github.com/shopspring/decimal"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+/**
+ * @Author: lee
+ * @Description:
+ * @File: rolling_test
+ * @Date: 2026-10-19 7:20 下午
+ */
+
+func Test_RollingCount(t *testing.T) {
+	r := NewFloatRolling(RollingConfig{Size: 3, EMAAlpha: 0.5, Percentiles: true})
+	for _, v := range []float64{5, 1, 4, 2, 3} {
+		r.Push(v)
+	}
+	if !reflect.DeepEqual([]float64{4, 2, 3}, r.Values()) {
+		t.Fatalf("unexpected values: %v", r.Values())
+	}
+	if 9 != r.Sum() || 3 != r.Mean() {
+		t.Fatalf("unexpected sum %v mean %v", r.Sum(), r.Mean())
+	}
+	if math.Abs(r.Variance()-2.0/3) > 1e-9 || math.Abs(r.StdDev()-math.Sqrt(2.0/3)) > 1e-9 {
+		t.Fatalf("unexpected variance %v", r.Variance())
+	}
+	if v, _ := r.Min(); 2 != v {
+		t.Fatalf("unexpected min %v", v)
+	}
+	if v, _ := r.Max(); 4 != v {
+		t.Fatalf("unexpected max %v", v)
+	}
+	//5 -> 3 -> 3.5 -> 2.75 -> 2.875
+	if v, ok := r.EMA(); !ok || 2.875 != v {
+		t.Fatalf("unexpected ema %v", v)
+	}
+	if v, _ := r.Percentile(50); 3 != v {
+		t.Fatalf("unexpected median %v", v)
+	}
+	if v, _ := r.Percentile(75); 3.5 != v {
+		t.Fatalf("unexpected p75 %v", v)
+	}
+}
+
+func Test_RollingTime(t *testing.T) {
+	r := NewFloatRolling(RollingConfig{Window: 10 * time.Second, MaxSize: 100})
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		r.PushAt(float64(i), start.Add(time.Duration(i)*time.Second))
+	}
+	//19s 时保留 10..19
+	if 10 != r.Len() || 145 != r.Sum() {
+		t.Fatalf("unexpected len %d sum %v", r.Len(), r.Sum())
+	}
+	if v, _ := r.Min(); 10 != v {
+		t.Fatalf("unexpected min %v", v)
+	}
+
+	r.Expire(start.Add(time.Minute))
+	if 0 != r.Len() || 0 != r.Sum() {
+		t.Fatalf("expect empty, len %d", r.Len())
+	}
+	if _, ok := r.Max(); ok {
+		t.Fatal("expect no max")
+	}
+
+	//超过 MaxSize 时淘汰最旧的
+	for i := 0; i < 300; i++ {
+		r.PushAt(float64(i), start)
+	}
+	if 100 != r.Len() {
+		t.Fatalf("expect capped at 100, got %d", r.Len())
+	}
+	if v, _ := r.Min(); 200 != v {
+		t.Fatalf("unexpected min %v", v)
+	}
+}
+
+func Test_RollingDecimal(t *testing.T) {
+	r := NewDecimalRolling(RollingConfig{Size: 2, Percentiles: true})
+	for _, s := range []string{"0.1", "0.2", "0.3"} {
+		r.Push(decimal.RequireFromString(s))
+	}
+	if !r.Sum().Equal(decimal.RequireFromString("0.5")) || !r.Mean().Equal(decimal.RequireFromString("0.25")) {
+		t.Fatalf("unexpected sum %s mean %s", r.Sum(), r.Mean())
+	}
+	if !r.Variance().Equal(decimal.RequireFromString("0.0025")) {
+		t.Fatalf("unexpected variance %s", r.Variance())
+	}
+	if v, _ := r.Percentile(50); !v.Equal(decimal.RequireFromString("0.25")) {
+		t.Fatalf("unexpected median %s", v)
+	}
+}
@@ -10,9 +10,11 @@ package gutils
 import (
 	"fmt"
 	"github.com/0DeOrg/gutils/judge"
+	"github.com/0DeOrg/gutils/logutils"
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
-	"log"
+	"go.uber.org/zap"
+	"sync"
 )
 
 const CONFIG_PATH = "config.yaml"
@@ -20,36 +22,79 @@ const CONFIG_PATH = "config.yaml"
 //外部命令行解析的时候赋值
 var CfgPathFlag = ""
 
+var (
+	configChangeHooks []func()
+	configHookMtx     sync.RWMutex
+)
+
+// RegisterConfigChangeHook
+/* @Description: 注册配置热更新钩子，配置文件、Apollo、Nacos 更新并反序列化完成后同步调用
+ * @param fn func()
+ */
+func RegisterConfigChangeHook(fn func()) {
+	configHookMtx.Lock()
+	defer configHookMtx.Unlock()
+	configChangeHooks = append(configChangeHooks, fn)
+}
+
+// TriggerConfigChange
+/* @Description: 配置更新后调用，依次执行注册的钩子
+ */
+func TriggerConfigChange() {
+	configHookMtx.RLock()
+	hooks := make([]func(), len(configChangeHooks))
+	copy(hooks, configChangeHooks)
+	configHookMtx.RUnlock()
+
+	for _, fn := range hooks {
+		fn()
+	}
+}
+
+// NewViper
+/* @Description: 读取配置文件到 pObj 并监听文件变化，通常早于日志初始化调用，日志初始化前输出到标准错误
+ * @param path string 为空时使用 -c 参数或 config.yaml
+ * @param pObj interface{} 结构体指针
+ * @param callback ...func() 配置变化后异步执行
+ * @return *viper.Viper
+ * @return error
+ */
 func NewViper(path string, pObj interface{}, callback ...func()) (*viper.Viper, error) {
 	var config string
 	if len(path) == 0 {
 		//flag.StringVar(&config, "c", "", "choose config file.")
 		//flag.Parse()
 		if CfgPathFlag != "" {
-			fmt.Printf("您正在使用命令行的-c参数传递的值,config的路径为%v\n", config)
+			config = CfgPathFlag
+			logutils.NamedModule(logutils.ModuleViper).Info("您正在使用命令行的-c参数传递的值", zap.String("config", config))
 		} else {
 			config = CONFIG_PATH
 		}
 	} else {
 		config = path
-		fmt.Printf("您正在使用func NewViper()传递的值,config的路径为%v\n", config)
+		logutils.NamedModule(logutils.ModuleViper).Info("您正在使用func NewViper()传递的值", zap.String("config", config))
 	}
+
+	if !judge.IsStructPtr(pObj) {
+		return nil, fmt.Errorf("NewViper pObj must be struct pointer")
+	}
+
 	v := viper.New()
 	v.SetConfigFile(config)
 	err := v.ReadInConfig()
 	if err != nil {
-		log.Fatal("fatal error can't find config file: ", err.Error())
+		return nil, fmt.Errorf("NewViper|ReadInConfig err: %s", err.Error())
 	}
 	v.WatchConfig()
 
-	if !judge.IsStructPtr(pObj) {
-		log.Fatal("fatal error viper pObj must be struct pointer")
-	}
-
 	v.OnConfigChange(func(e fsnotify.Event) {
-		log.Println("config file changed:", e.Name)
+		//日志可能在之后初始化，每次获取
+		logger := logutils.NamedModule(logutils.ModuleViper)
+		logger.Info("config file changed", zap.String("file", e.Name))
 		if err := v.Unmarshal(pObj); err != nil {
-			log.Println(err.Error())
+			logger.Error("config file unmarshal fatal", zap.String("file", e.Name), zap.Error(err))
+		} else {
+			TriggerConfigChange()
 		}
 
 		for _, callFunc := range callback {
@@ -59,8 +104,7 @@ func NewViper(path string, pObj interface{}, callback ...func()) (*viper.Viper,
 	})
 
 	if err := v.Unmarshal(pObj); err != nil {
-		log.Println(err.Error())
-		return nil, err
+		return nil, fmt.Errorf("NewViper|Unmarshal err: %s", err.Error())
 	}
 	return v, nil
 }